@@ -0,0 +1,133 @@
+package btc
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+type AddressType string
+
+const (
+	DEF_addressType_P2PKH  AddressType = "p2pkh"
+	DEF_addressType_P2SH   AddressType = "p2sh"
+	DEF_addressType_P2WPKH AddressType = "p2wpkh"
+	DEF_addressType_P2WSH  AddressType = "p2wsh"
+	DEF_addressType_P2TR   AddressType = "p2tr"
+)
+
+var (
+	ErrAddressInvalid = errors.New("invalid address")
+	ErrAddressNetwork = errors.New("address is for other network") // mainnet address on testnet and vice versa
+)
+
+// knownParams are networks DetectAddressNetworks can report
+var knownParams = []*chaincfg.Params{
+	&chaincfg.MainNetParams,
+	&chaincfg.TestNet3Params,
+	&chaincfg.RegressionNetParams,
+	&chaincfg.SigNetParams,
+	&LTCMainNetParams,
+	&LTCTestNetParams,
+	&BCHMainNetParams,
+	&BCHTestNetParams,
+	&DOGEMainNetParams,
+	&DOGETestNetParams,
+}
+
+// ParsedAddress is offline classification of address
+type ParsedAddress struct {
+	Address        string // normalized ( lower case bech32, CashAddr on bitcoin cash )
+	Type           AddressType
+	Network        string // name of params
+	ScriptPubKey   string // hex of output script
+	WitnessVersion int    // -1 if not segwit
+}
+
+// ParseAddress classifies address of params without node
+// surrounding spaces are trimmed, address of other known network returns ErrAddressNetwork
+func ParseAddress(address string, params *chaincfg.Params) (parsed *ParsedAddress, err error) {
+	address = strings.TrimSpace(address)
+	addr, err := decodeAddress(address, params)
+	if err != nil {
+		networks := DetectAddressNetworks(address)
+		if len(networks) > 0 {
+			names := make([]string, 0, len(networks))
+			for _, network := range networks {
+				names = append(names, network.Name)
+			}
+			return nil, fmt.Errorf("%w | %s | %s, not %s", ErrAddressNetwork, address, strings.Join(names, ", "), params.Name)
+		}
+		return nil, fmt.Errorf("%w | %s | %v", ErrAddressInvalid, address, err)
+	}
+
+	parsed = &ParsedAddress{
+		Type:           addressType(addr),
+		Network:        params.Name,
+		WitnessVersion: -1,
+	}
+	if parsed.Type == "" {
+		return nil, fmt.Errorf("%w | unsupported address type | %s", ErrAddressInvalid, address)
+	}
+	if addr, ok := addr.(interface{ WitnessVersion() byte }); ok == true {
+		parsed.WitnessVersion = int(addr.WitnessVersion())
+	}
+
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, err
+	}
+	parsed.Address = EncodeAddress(addr, params)
+	parsed.ScriptPubKey = hex.EncodeToString(pkScript)
+	return parsed, nil
+}
+
+// NormalizeAddress returns address in canonical form of params
+func NormalizeAddress(address string, params *chaincfg.Params) (normalized string, err error) {
+	parsed, err := ParseAddress(address, params)
+	if err != nil {
+		return "", err
+	}
+	return parsed.Address, nil
+}
+
+// DetectAddressNetworks returns every known network the address is valid on, empty if none
+// base58 address can match several networks sharing prefix ( testnet3 / regtest / signet / LTC testnet, bitcoin / BCH legacy )
+func DetectAddressNetworks(address string) (networks []*chaincfg.Params) {
+	address = strings.TrimSpace(address)
+	for _, params := range knownParams {
+		addr, err := decodeAddress(address, params)
+		if err == nil && addressType(addr) != "" {
+			networks = append(networks, params)
+		}
+	}
+	return networks
+}
+
+// addressType returns type of standard address, empty for others ( public key, unknown witness version )
+func addressType(addr btcutil.Address) AddressType {
+	switch addr.(type) {
+	case *btcutil.AddressPubKeyHash:
+		return DEF_addressType_P2PKH
+	case *btcutil.AddressScriptHash:
+		return DEF_addressType_P2SH
+	case *btcutil.AddressWitnessPubKeyHash:
+		return DEF_addressType_P2WPKH
+	case *btcutil.AddressWitnessScriptHash:
+		return DEF_addressType_P2WSH
+	case *btcutil.AddressTaproot:
+		return DEF_addressType_P2TR
+	default:
+		return ""
+	}
+}
+
+// ParseAddress classifies address of client network without node
+func (t *Client) ParseAddress(address string) (parsed *ParsedAddress, err error) {
+	return ParseAddress(address, t.params)
+}
@@ -0,0 +1,81 @@
+package btc
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAddress(t *testing.T) {
+	mainnet := &chaincfg.MainNetParams
+	testnet := &chaincfg.TestNet3Params
+
+	for _, test := range []struct {
+		address      string
+		params       *chaincfg.Params
+		normalized   string
+		addrType     AddressType
+		scriptPubKey string
+		version      int
+	}{
+		{"1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", mainnet, "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", DEF_addressType_P2PKH, "76a91477bff20c60e522dfaa3350c39b030a5d004e839a88ac", -1},
+		{"3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", mainnet, "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", DEF_addressType_P2SH, "a914b472a266d0bd89c13706a4132ccfb16f7c3b9fcb87", -1},
+		// BIP173 vectors, upper case is normalized
+		{" BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4 ", mainnet, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", DEF_addressType_P2WPKH, "0014751e76e8199196d454941c45d1b3a323f1433bd6", 0},
+		{"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", testnet, "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", DEF_addressType_P2WSH, "00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262", 0},
+		// BIP86 vector
+		{"bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr", mainnet, "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr", DEF_addressType_P2TR, "5120a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c", 1},
+	} {
+		parsed, err := ParseAddress(test.address, test.params)
+		require.NoError(t, err, test.address)
+		require.Equal(t, test.normalized, parsed.Address)
+		require.Equal(t, test.addrType, parsed.Type)
+		require.Equal(t, test.params.Name, parsed.Network)
+		require.Equal(t, test.scriptPubKey, parsed.ScriptPubKey)
+		require.Equal(t, test.version, parsed.WitnessVersion)
+	}
+
+	// network mixups
+	_, err := ParseAddress("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", testnet)
+	require.ErrorIs(t, err, ErrAddressNetwork)
+	_, err = ParseAddress("tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", mainnet)
+	require.ErrorIs(t, err, ErrAddressNetwork)
+	_, err = ParseAddress("1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", testnet)
+	require.ErrorIs(t, err, ErrAddressNetwork)
+
+	// every network sharing prefix is reported
+	networkNames := func(address string) (names []string) {
+		for _, network := range DetectAddressNetworks(address) {
+			names = append(names, network.Name)
+		}
+		return names
+	}
+	require.Equal(t, []string{mainnet.Name, BCHMainNetParams.Name}, networkNames("1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2"))
+	require.Equal(t, []string{testnet.Name, chaincfg.RegressionNetParams.Name, chaincfg.SigNetParams.Name, LTCTestNetParams.Name, BCHTestNetParams.Name}, networkNames("mrX9vMRYLfVy1BnZbc5gZjuyaqH3ZW2ZHz"))
+	require.Equal(t, []string{testnet.Name, chaincfg.RegressionNetParams.Name, chaincfg.SigNetParams.Name, BCHTestNetParams.Name, DOGETestNetParams.Name}, networkNames("2MzQwSSnBHWHqSAqtTVQ6v47XtaisrJa1Vc"))
+	require.Equal(t, []string{chaincfg.RegressionNetParams.Name}, networkNames("bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080"))
+	require.Equal(t, []string{LTCMainNetParams.Name}, networkNames("LM2WMpR1Rp6j3Sa59cMXMs1SPzj9eXpGc1"))
+	_, err = ParseAddress("LM2WMpR1Rp6j3Sa59cMXMs1SPzj9eXpGc1", mainnet)
+	require.ErrorIs(t, err, ErrAddressNetwork)
+	require.ErrorContains(t, err, LTCMainNetParams.Name)
+
+	// invalid
+	for _, address := range []string{
+		"",
+		"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5",                         // checksum
+		"bc1QW508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",                         // mixed case
+		"1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN3",                                 // base58 checksum
+		"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", // public key
+	} {
+		_, err = ParseAddress(address, mainnet)
+		require.ErrorIs(t, err, ErrAddressInvalid, address)
+		require.Empty(t, DetectAddressNetworks(address), address)
+	}
+
+	// CashAddr on bitcoin cash
+	parsed, err := ParseAddress("1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu", &BCHMainNetParams)
+	require.NoError(t, err)
+	require.Equal(t, "bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a", parsed.Address)
+	require.Equal(t, DEF_addressType_P2PKH, parsed.Type)
+}
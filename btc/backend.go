@@ -0,0 +1,73 @@
+package btc
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// Backend is address indexed utxo / history service ( electrum server, esplora )
+// used by RawTx instead of scantxoutset for addresses out of node wallet
+type Backend interface {
+	ListUnspent(ctx context.Context, addr btcutil.Address) (unspents []*AddressUnspent, err error)
+	GetBalance(ctx context.Context, addr btcutil.Address) (balance *AddressBalance, err error)
+	GetHistory(ctx context.Context, addr btcutil.Address) (history []*AddressTx, err error)
+	GetTipHeight(ctx context.Context) (height int64, err error)
+}
+
+type AddressUnspent struct {
+	Txid   string
+	Vout   uint32
+	Amount btcutil.Amount
+	Height int64 // 0 if unconfirmed
+}
+
+type AddressBalance struct {
+	Confirmed   btcutil.Amount
+	Unconfirmed btcutil.Amount // can be negative if mempool tx spends confirmed output
+}
+
+type AddressTx struct {
+	Txid   string
+	Height int64 // 0 if unconfirmed
+}
+
+// SetBackend sets indexed backend for utxo of addresses out of node wallet ( nil uses scantxoutset )
+func (t *RawTx) SetBackend(backend Backend) {
+	t.backend = backend
+}
+
+func (t *RawTx) utxoGetBackend(ctx context.Context, addrs []btcutil.Address) (utxos []*utxo, err error) {
+	tip, err := t.backend.GetTipHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, err
+		}
+		unspents, err := t.backend.ListUnspent(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+		for _, unspent := range unspents {
+			var confirmations int64
+			if unspent.Height > 0 {
+				confirmations = tip - unspent.Height + 1
+			}
+			utxos = append(utxos, &utxo{
+				Txid:         unspent.Txid,
+				Vout:         unspent.Vout,
+				FromAddr:     EncodeAddress(addr, t.client.params),
+				FromAmount:   unspent.Amount.ToBTC(),
+				ScriptPubKey: hex.EncodeToString(pkScript),
+
+				Confirmations: confirmations,
+			})
+		}
+	}
+	return utxos, nil
+}
@@ -0,0 +1,111 @@
+package btc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/rpcclient"
+)
+
+const (
+	DEF_batchSize      = 100 // requests per http request
+	DEF_batchSizeBlock = 10  // verbose blocks with tx are large
+)
+
+// GetBlockHashes returns block hashes of heights from ~ to ( inclusive ) with batch requests
+func (t *Client) GetBlockHashes(ctx context.Context, from, to int64) (blockHashes []string, err error) {
+	if from < 0 || to < from {
+		return nil, fmt.Errorf("invalid block range | %d ~ %d", from, to)
+	}
+	hashes, err := batch(ctx, t, int(to-from+1), DEF_batchSize, func(rpc *rpcclient.Client, i int) func() (*chainhash.Hash, error) {
+		return rpc.GetBlockHashAsync(from + int64(i)).Receive
+	})
+	if err != nil {
+		return nil, err
+	}
+	blockHashes = make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		blockHashes = append(blockHashes, hash.String())
+	}
+	return blockHashes, nil
+}
+
+// GetBlockInfosWithTx returns verbose blocks of hashes in order with batch requests
+func (t *Client) GetBlockInfosWithTx(ctx context.Context, blockHashes []string) (blockInfos []*btcjson.GetBlockVerboseTxResult, err error) {
+	hashes, err := parseHashes(blockHashes)
+	if err != nil {
+		return nil, err
+	}
+	return batch(ctx, t, len(hashes), DEF_batchSizeBlock, func(rpc *rpcclient.Client, i int) func() (*btcjson.GetBlockVerboseTxResult, error) {
+		return rpc.GetBlockVerboseTxAsync(hashes[i]).Receive
+	})
+}
+
+// GetBlocksWithTx returns verbose blocks of heights from ~ to ( inclusive )
+// hashes and blocks are fetched in separate batches, check PreviousHash if reorg matters
+func (t *Client) GetBlocksWithTx(ctx context.Context, from, to int64) (blockInfos []*btcjson.GetBlockVerboseTxResult, err error) {
+	blockHashes, err := t.GetBlockHashes(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return t.GetBlockInfosWithTx(ctx, blockHashes)
+}
+
+// GetRawTxInfos returns verbose txs of txids in order with batch requests ( txindex is needed for confirmed txs out of wallet )
+func (t *Client) GetRawTxInfos(ctx context.Context, txids []string) (rawTxInfos []*btcjson.TxRawResult, err error) {
+	hashes, err := parseHashes(txids)
+	if err != nil {
+		return nil, err
+	}
+	return batch(ctx, t, len(hashes), DEF_batchSize, func(rpc *rpcclient.Client, i int) func() (*btcjson.TxRawResult, error) {
+		return rpc.GetRawTransactionVerboseAsync(hashes[i]).Receive
+	})
+}
+
+// batch queues count requests on batch clients and sends them by size per http request
+// results are in order of requests, first failed request fails whole batch
+func batch[T any](ctx context.Context, t *Client, count, size int, queue func(rpc *rpcclient.Client, i int) func() (T, error)) (results []T, err error) {
+	results = make([]T, 0, count)
+	for from := 0; from < count; from += size {
+		to := min(from+size, count)
+		rpc, err := rpcclient.NewBatch(t.config)
+		if err != nil {
+			return nil, err
+		}
+
+		receives := make([]func() (T, error), 0, to-from)
+		for i := from; i < to; i++ {
+			receives = append(receives, queue(rpc, i))
+		}
+		err = waitErr(ctx, t.timeout, rpc.Send)
+		if err != nil {
+			rpc.Shutdown()
+			return nil, err
+		}
+		// responses are delivered by Send, receive does not block
+		for i, receive := range receives {
+			res, err := receive()
+			if err != nil {
+				rpc.Shutdown()
+				return nil, fmt.Errorf("batch request %d failed | %w", from+i, classifyError(err))
+			}
+			results = append(results, res)
+		}
+		rpc.Shutdown()
+	}
+	return results, nil
+}
+
+func parseHashes(hashes []string) (parsed []*chainhash.Hash, err error) {
+	parsed = make([]*chainhash.Hash, 0, len(hashes))
+	for _, hash := range hashes {
+		h, err := chainhash.NewHashFromStr(hash)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, h)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,53 @@
+package btc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientBatch(t *testing.T) {
+	const blockCount = DEF_batchSizeBlock + 2 // 2 batches of blocks
+	hash := func(height int) string { return fmt.Sprintf("%064x", height+1) }
+
+	results := map[string]string{}
+	for height := 0; height < blockCount; height++ {
+		results[fmt.Sprintf("getblockhash [%d]", height)] = fmt.Sprintf("%q", hash(height))
+		results[fmt.Sprintf("getblock [%q,2]", hash(height))] = fmt.Sprintf(`{"hash":%q,"height":%d,"tx":[{"txid":"tx-%d"}]}`, hash(height), height, height)
+	}
+	results[fmt.Sprintf("getrawtransaction [%q,1]", hash(0))] = fmt.Sprintf(`{"txid":%q,"confirmations":3}`, hash(0))
+	results[fmt.Sprintf("getrawtransaction [%q,1]", hash(1))] = "!No such mempool or blockchain transaction"
+	client, requests := newTestRPCServer(t, results)
+	ctx := context.Background()
+
+	blockHashes, err := client.GetBlockHashes(ctx, 0, blockCount-1)
+	require.NoError(t, err)
+	require.Len(t, blockHashes, blockCount)
+	require.Equal(t, hash(blockCount-1), blockHashes[blockCount-1])
+
+	blocks, err := client.GetBlocksWithTx(ctx, 0, blockCount-1)
+	require.NoError(t, err)
+	require.Len(t, blocks, blockCount)
+	for height, block := range blocks {
+		require.Equal(t, int64(height), block.Height)
+		require.Equal(t, fmt.Sprintf("tx-%d", height), block.Tx[0].Txid)
+	}
+	require.Len(t, *requests, blockCount*3)
+
+	txs, err := client.GetRawTxInfos(ctx, []string{hash(0)})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), txs[0].Confirmations)
+	_, err = client.GetRawTxInfos(ctx, []string{hash(0), hash(1)})
+	var rpcErr *RPCError
+	require.ErrorAs(t, err, &rpcErr)
+	require.Equal(t, btcjson.ErrRPCInvalidAddressOrKey, rpcErr.Code)
+
+	// invalid input
+	_, err = client.GetBlockHashes(ctx, 5, 4)
+	require.Error(t, err)
+	_, err = client.GetRawTxInfos(ctx, []string{"zz"})
+	require.Error(t, err)
+}
@@ -0,0 +1,143 @@
+package btc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+const (
+	DEF_cashAddrCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+	DEF_cashAddrType_P2PKH = 0
+	DEF_cashAddrType_P2SH  = 1
+)
+
+// EncodeAddress encodes address in format of chain ( CashAddr for bitcoin cash, else btcutil encoding )
+func EncodeAddress(addr btcutil.Address, params *chaincfg.Params) string {
+	if cashAddrPrefix(params) != "" {
+		if address, err := EncodeCashAddr(addr, params); err == nil {
+			return address
+		}
+	}
+	return addr.EncodeAddress()
+}
+
+// DecodeAddress decodes address of chain ( CashAddr or legacy for bitcoin cash ), String of result is legacy form node accepts
+func DecodeAddress(address string, params *chaincfg.Params) (addr btcutil.Address, err error) {
+	return decodeAddress(address, params)
+}
+
+// EncodeCashAddr encodes P2PKH / P2SH address to CashAddr with prefix ( bitcoincash:q... )
+func EncodeCashAddr(addr btcutil.Address, params *chaincfg.Params) (address string, err error) {
+	prefix := cashAddrPrefix(params)
+	if prefix == "" {
+		return "", fmt.Errorf("cashaddr is not used in network | %s", params.Name)
+	}
+
+	var addrType byte
+	switch addr.(type) {
+	case *btcutil.AddressPubKeyHash:
+		addrType = DEF_cashAddrType_P2PKH
+	case *btcutil.AddressScriptHash:
+		addrType = DEF_cashAddrType_P2SH
+	default:
+		return "", fmt.Errorf("unsupported cashaddr type | %T", addr)
+	}
+
+	// version byte : type << 3 | size ( 0 : 160 bits )
+	payload, err := bech32.ConvertBits(append([]byte{addrType << 3}, addr.ScriptAddress()...), 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	checksum := cashAddrPolyMod(append(cashAddrPrefixValues(prefix), append(payload, make([]byte, 8)...)...))
+
+	var sb strings.Builder
+	sb.WriteString(prefix + ":")
+	for _, value := range payload {
+		sb.WriteByte(DEF_cashAddrCharset[value])
+	}
+	for i := 0; i < 8; i++ {
+		sb.WriteByte(DEF_cashAddrCharset[(checksum>>uint(5*(7-i)))&0x1f])
+	}
+	return sb.String(), nil
+}
+
+// DecodeCashAddr decodes CashAddr ( prefix is optional ) to P2PKH / P2SH address of params
+func DecodeCashAddr(address string, params *chaincfg.Params) (addr btcutil.Address, err error) {
+	prefix := cashAddrPrefix(params)
+	if prefix == "" {
+		return nil, fmt.Errorf("cashaddr is not used in network | %s", params.Name)
+	}
+	if strings.ToLower(address) != address && strings.ToUpper(address) != address {
+		return nil, fmt.Errorf("invalid cashaddr | mixed case | %s", address)
+	}
+
+	encoded := strings.ToLower(address)
+	if idx := strings.LastIndexByte(encoded, ':'); idx != -1 {
+		if encoded[:idx] != prefix {
+			return nil, fmt.Errorf("cashaddr is not for network | %s | %s", address, params.Name)
+		}
+		encoded = encoded[idx+1:]
+	}
+	if len(encoded) <= 8 {
+		return nil, fmt.Errorf("invalid cashaddr | too short | %s", address)
+	}
+
+	values := make([]byte, 0, len(encoded))
+	for _, c := range encoded {
+		idx := strings.IndexRune(DEF_cashAddrCharset, c)
+		if idx == -1 {
+			return nil, fmt.Errorf("invalid cashaddr | invalid character | %s", address)
+		}
+		values = append(values, byte(idx))
+	}
+	if cashAddrPolyMod(append(cashAddrPrefixValues(prefix), values...)) != 0 {
+		return nil, fmt.Errorf("invalid cashaddr | checksum mismatch | %s", address)
+	}
+
+	payload, err := bech32.ConvertBits(values[:len(values)-8], 5, 8, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cashaddr | %s | %w", address, err)
+	}
+	if len(payload) != 21 || payload[0]&0x07 != 0 {
+		return nil, fmt.Errorf("invalid cashaddr | unsupported hash size | %s", address)
+	}
+
+	switch payload[0] >> 3 {
+	case DEF_cashAddrType_P2PKH:
+		return btcutil.NewAddressPubKeyHash(payload[1:], params)
+	case DEF_cashAddrType_P2SH:
+		return btcutil.NewAddressScriptHashFromHash(payload[1:], params)
+	default:
+		return nil, fmt.Errorf("invalid cashaddr | unsupported type : %d | %s", payload[0]>>3, address)
+	}
+}
+
+// cashAddrPrefixValues returns lower 5 bits of prefix characters and separator
+func cashAddrPrefixValues(prefix string) []byte {
+	values := make([]byte, 0, len(prefix)+1)
+	for i := 0; i < len(prefix); i++ {
+		values = append(values, prefix[i]&0x1f)
+	}
+	return append(values, 0)
+}
+
+// cashAddrPolyMod is BCH code checksum of CashAddr ( 40 bits )
+func cashAddrPolyMod(values []byte) uint64 {
+	generators := [5]uint64{0x98f2bc8e61, 0x79b76d99e2, 0xf33e5fb3c4, 0xae2eabe2a8, 0x1e4f43e470}
+	c := uint64(1)
+	for _, value := range values {
+		c0 := c >> 35
+		c = ((c & 0x07ffffffff) << 5) ^ uint64(value)
+		for i, generator := range generators {
+			if (c0>>uint(i))&1 == 1 {
+				c ^= generator
+			}
+		}
+	}
+	return c ^ 1
+}
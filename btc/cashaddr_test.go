@@ -0,0 +1,94 @@
+package btc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCashAddr(t *testing.T) {
+	// CashAddr spec test vector
+	for _, test := range []struct {
+		legacy   string
+		cashAddr string
+	}{
+		{"1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu", "bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a"},
+		{"3CWFddi6m4ndiGyKqzYvsFYagqDLPVMTzC", "bitcoincash:ppm2qsznhks23z7629mms6s4cwef74vcwvn0h829pq"},
+	} {
+		addr, err := btcutil.DecodeAddress(test.legacy, &BCHMainNetParams)
+		require.NoError(t, err)
+		cashAddr, err := EncodeCashAddr(addr, &BCHMainNetParams)
+		require.NoError(t, err)
+		require.Equal(t, test.cashAddr, cashAddr)
+		require.Equal(t, test.cashAddr, EncodeAddress(addr, &BCHMainNetParams))
+		require.Equal(t, test.legacy, EncodeAddress(addr, &chaincfg.MainNetParams))
+
+		// with or without prefix, upper case
+		for _, address := range []string{test.cashAddr, strings.TrimPrefix(test.cashAddr, "bitcoincash:"), strings.ToUpper(test.cashAddr)} {
+			decoded, err := DecodeCashAddr(address, &BCHMainNetParams)
+			require.NoError(t, err)
+			require.Equal(t, addr.String(), decoded.String())
+			require.IsType(t, addr, decoded)
+		}
+
+		// decodeAddress accepts both formats
+		decoded, err := decodeAddress(test.cashAddr, &BCHMainNetParams)
+		require.NoError(t, err)
+		require.Equal(t, test.legacy, decoded.EncodeAddress())
+		decoded, err = decodeAddress(test.legacy, &BCHMainNetParams)
+		require.NoError(t, err)
+		require.Equal(t, test.legacy, decoded.EncodeAddress())
+	}
+
+	// invalid
+	_, err := DecodeCashAddr("bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6b", &BCHMainNetParams) // checksum
+	require.Error(t, err)
+	_, err = DecodeCashAddr("bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvY22gdx6a", &BCHMainNetParams) // mixed case
+	require.Error(t, err)
+	_, err = DecodeCashAddr("bchtest:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a", &BCHMainNetParams) // network
+	require.Error(t, err)
+	_, err = DecodeCashAddr("bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a", &chaincfg.MainNetParams)
+	require.Error(t, err)
+
+	// testnet round trip
+	addr, err := btcutil.NewAddressPubKeyHash(make([]byte, 20), &BCHTestNetParams)
+	require.NoError(t, err)
+	cashAddr, err := EncodeCashAddr(addr, &BCHTestNetParams)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(cashAddr, "bchtest:q"))
+	decoded, err := DecodeCashAddr(cashAddr, &BCHTestNetParams)
+	require.NoError(t, err)
+	require.Equal(t, addr.String(), decoded.String())
+}
+
+func TestCashAddrClient(t *testing.T) {
+	cashAddr := "bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a"
+	legacy := "1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu"
+
+	client, requests := newTestRPCServer(t, map[string]string{
+		"getnewaddress": `"` + cashAddr + `"`,
+		"dumpprivkey":   `"KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn"`,
+		"sendtoaddress": `"` + DEF_txid_dummy + `"`,
+	})
+	client.params = &BCHMainNetParams
+
+	address, err := client.GetNewAddressWithLabel(context.Background(), "customer-1")
+	require.NoError(t, err)
+	require.Equal(t, cashAddr, address)
+
+	_, err = client.DumpPrivKey(context.Background(), cashAddr)
+	require.NoError(t, err)
+	_, err = client.SendCoin(context.Background(), cashAddr, 1000)
+	require.NoError(t, err)
+
+	// node is called with legacy address
+	require.Equal(t, []string{
+		`/ getnewaddress ["customer-1"]`,
+		`/ dumpprivkey ["` + legacy + `"]`,
+		`/ sendtoaddress ["` + legacy + `",0.00001]`,
+	}, *requests)
+}
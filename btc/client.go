@@ -1,34 +1,143 @@
 package btc
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/rpcclient"
 )
 
+func NewClient(params *chaincfg.Params, host string, opts ...Option) (*Client, error) {
+	client := &Client{}
+	err := client.Open(params, host, "", "", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
 type Client struct {
-	params *chaincfg.Params
-	rpc    *rpcclient.Client
+	params  *chaincfg.Params
+	rpc     *rpcclient.Client
+	timeout time.Duration
+
+	config *rpcclient.ConnConfig // kept to open wallet endpoint
+	host   string                // host without wallet path
+	wallet string                // empty is default wallet
+
+	transport   *http.Transport   // custom transport ( WithTransport )
+	clientCerts []tls.Certificate // mutual tls ( WithClientCert )
+	forwarder   *forwarder        // local proxy of transport, shared with wallet clients
 }
 
-func (t *Client) Open(params *chaincfg.Params, host, id, pw string) (err error) {
+func (t *Client) Open(params *chaincfg.Params, host, id, pw string, opts ...Option) (err error) {
 	t.params = params
-	t.rpc, err = rpcclient.New(&rpcclient.ConnConfig{
+	config := &rpcclient.ConnConfig{
 		Host:         host,
 		User:         id,
 		Pass:         pw,
 		HTTPPostMode: true, // Bitcoin core only supports HTTP POST mode
 		DisableTLS:   true, // Bitcoin core does not provide TLS by default
-	}, nil)
+	}
+	for _, opt := range opts {
+		if err = opt(t, config); err != nil {
+			return err
+		}
+	}
+	err = t.openForwarder(config)
+	if err != nil {
+		return err
+	}
+	t.rpc, err = rpcclient.New(config, nil)
 	if err != nil {
+		if t.forwarder != nil {
+			t.forwarder.Close()
+		}
 		return err
 	}
+	t.config = config
+	t.host = host
 	return nil
 }
 
+// Wallet returns client routed to wallet endpoint ( /wallet/<name> ) of multi wallet node
+// params, timeout and options are shared, returned client must be closed separately ( before this client if custom transport is set )
+func (t *Client) Wallet(name string) (wallet *Client, err error) {
+	if name == "" {
+		return nil, fmt.Errorf("wallet name is empty")
+	}
+	config := *t.config
+	config.Host = t.host + "/wallet/" + url.PathEscape(name)
+	rpc, err := rpcclient.New(&config, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		params:  t.params,
+		rpc:     rpc,
+		timeout: t.timeout,
+		config:  &config,
+		host:    t.host,
+		wallet:  name,
+	}, nil
+}
+
+// WalletName returns name of routed wallet ( empty is default wallet )
+func (t *Client) WalletName() string {
+	return t.wallet
+}
+
+func (t *Client) Params() *chaincfg.Params {
+	return t.params
+}
+
 func (t *Client) Close() {
 	if t.rpc == nil {
 		return
 	}
 	t.rpc.Shutdown()
 	t.rpc = nil
+	if t.forwarder != nil {
+		t.forwarder.Close()
+		t.forwarder = nil
+	}
+}
+
+// wait waits rpcclient future until ctx is done or timeout ( 0 is no timeout )
+// rpcclient has no ctx support, so the request itself is not aborted - only the caller is released
+func wait[T any](ctx context.Context, timeout time.Duration, receive func() (T, error)) (res T, err error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	type result struct {
+		res T
+		err error
+	}
+	chanRes := make(chan result, 1)
+	go func() {
+		res, err := receive()
+		chanRes <- result{res: res, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return res, ctx.Err()
+	case result := <-chanRes:
+		return result.res, classifyError(result.err)
+	}
+}
+
+func waitErr(ctx context.Context, timeout time.Duration, receive func() error) (err error) {
+	_, err = wait(ctx, timeout, func() (struct{}, error) {
+		return struct{}{}, receive()
+	})
+	return err
 }
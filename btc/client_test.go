@@ -0,0 +1,215 @@
+package btc
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	block := make(chan struct{})
+	defer close(block)
+	_, err := wait(ctx, 0, func() (int64, error) {
+		<-block
+		return 0, nil
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	res, err := wait(context.Background(), 0, func() (int64, error) { return 1, nil })
+	require.NoError(t, err)
+	require.Equal(t, int64(1), res)
+}
+
+func TestWaitTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	err := waitErr(context.Background(), 10*time.Millisecond, func() error {
+		<-block
+		return nil
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// newTestRPCServer returns client of fake node answering results by "method params" or method ( null if not set )
+// result starting with "!" is returned as rpc error of the message, batch requests are answered in order
+// requests are recorded as "path method params"
+func newTestRPCServer(t *testing.T, results map[string]string) (client *Client, requests *[]string) {
+	requests = &[]string{}
+	answer := func(path string, req *btcjson.Request) string {
+		params, err := json.Marshal(req.Params)
+		require.NoError(t, err)
+		*requests = append(*requests, fmt.Sprintf("%s %s %s", path, req.Method, params))
+
+		result, ok := results[fmt.Sprintf("%s %s", req.Method, params)]
+		if ok == false {
+			result, ok = results[req.Method]
+		}
+		if ok == false {
+			result = `null`
+		}
+		if strings.HasPrefix(result, "!") == true {
+			return fmt.Sprintf(`{"result":null,"error":{"code":-5,"message":%q},"id":%v}`, result[1:], req.ID)
+		}
+		return fmt.Sprintf(`{"result":%s,"error":null,"id":%v}`, result, req.ID)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		if bytes.HasPrefix(body, []byte("[")) == false {
+			req := &btcjson.Request{}
+			require.NoError(t, json.Unmarshal(body, req))
+			fmt.Fprint(w, answer(r.URL.EscapedPath(), req))
+			return
+		}
+
+		var reqs []*btcjson.Request
+		require.NoError(t, json.Unmarshal(body, &reqs))
+		responses := make([]string, 0, len(reqs))
+		for _, req := range reqs {
+			responses = append(responses, answer(r.URL.EscapedPath(), req))
+		}
+		fmt.Fprint(w, "["+strings.Join(responses, ",")+"]")
+	}))
+	t.Cleanup(server.Close)
+
+	client = &Client{}
+	require.NoError(t, client.Open(&chaincfg.RegressionNetParams, strings.TrimPrefix(server.URL, "http://"), "user", "pass"))
+	t.Cleanup(client.Close)
+	return client, requests
+}
+
+func TestClientWallet(t *testing.T) {
+	client, requests := newTestRPCServer(t, map[string]string{"listwallets": `["", "hot"]`})
+
+	names, err := client.ListWallets(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"", "hot"}, names)
+
+	wallet, err := client.Wallet("hot wallet")
+	require.NoError(t, err)
+	defer wallet.Close()
+	require.Equal(t, "hot wallet", wallet.WalletName())
+	require.NoError(t, wallet.LockWallet(context.Background()))
+	require.NoError(t, wallet.UnloadWallet(context.Background(), ""))
+
+	require.Equal(t, []string{
+		"/ listwallets []",
+		"/wallet/hot%20wallet walletlock []",
+		"/wallet/hot%20wallet unloadwallet []",
+	}, *requests)
+
+	_, err = client.Wallet("")
+	require.Error(t, err)
+}
+
+func TestClientCert(t *testing.T) {
+	// self signed client certificate
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	cert, err := x509.ParseCertificate(certDER)
+	require.NoError(t, err)
+
+	// node behind tls proxy requiring client certificate
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &btcjson.Request{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(req))
+		fmt.Fprintf(w, `{"result":150,"error":null,"id":%v}`, req.ID)
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: x509.NewCertPool()}
+	server.TLS.ClientCAs.AddCert(cert)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	host := strings.TrimPrefix(server.URL, "https://")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	for _, test := range []struct {
+		name string
+		opts []Option
+		ok   bool
+	}{
+		{"ca only", []Option{WithTLS(caPEM)}, false},
+		{"client cert", []Option{WithTLS(caPEM), WithClientCert(certPEM, keyPEM)}, true},
+		{"client cert before ca", []Option{WithClientCert(certPEM, keyPEM), WithTLS(caPEM)}, true},
+		// tls setting of custom transport is kept
+		{"transport", []Option{WithTransport(&http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}), WithClientCert(certPEM, keyPEM)}, true},
+		{"transport without client cert", []Option{WithTransport(&http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}), WithTLS(nil)}, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			client, err := NewClient(&chaincfg.RegressionNetParams, host, append(test.opts, WithBasicAuth("user", "pass"), WithTimeout(time.Second))...)
+			require.NoError(t, err)
+			defer client.Close()
+			height, err := client.GetBlockCount(context.Background())
+			if test.ok == false {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, int64(150), height)
+		})
+	}
+
+	// forwarder refuses hosts other than node
+	client, err := NewClient(&chaincfg.RegressionNetParams, host, WithTLS(caPEM), WithClientCert(certPEM, keyPEM), WithBasicAuth("user", "pass"))
+	require.NoError(t, err)
+	defer client.Close()
+	proxyURL, err := url.Parse(client.config.Proxy)
+	require.NoError(t, err)
+	httpClient := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+	for _, target := range []string{"http://example.com/", "http://127.0.0.1:1/"} {
+		res, err := httpClient.Post(target, "application/json", strings.NewReader(`{}`))
+		require.NoError(t, err)
+		res.Body.Close()
+		require.Equal(t, http.StatusForbidden, res.StatusCode, target)
+	}
+	res, err := httpClient.Post("http://"+host+"/", "application/json", strings.NewReader(`{"jsonrpc":"1.0","id":1,"method":"getblockcount","params":[]}`))
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	// client certificate without tls
+	_, err = NewClient(&chaincfg.RegressionNetParams, host, WithClientCert(certPEM, keyPEM), func(client *Client, config *rpcclient.ConnConfig) error {
+		config.DisableTLS = true
+		return nil
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,293 @@
+package btc
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+const (
+	DEF_dustLimit          btcutil.Amount = 546 // P2PKH dust threshold of bitcoin core ( 3 sat/vB )
+	DEF_bnbMaxTries                       = 100000
+	DEF_knapsackIterations                = 1000
+)
+
+var (
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrNoExactMatch      = errors.New("no exact match")
+)
+
+// Coin is utxo candidate of coin selection
+type Coin struct {
+	Txid          string
+	Vout          uint32
+	Amount        btcutil.Amount
+	Confirmations int64
+
+	// amount - fee to spend this coin as input
+	EffectiveValue btcutil.Amount
+}
+
+// CoinSelector picks coins whose total effective value covers target ( outputs + fee without inputs )
+// costOfChange is the fee of adding change output plus dust limit - selection within
+// [ target, target + costOfChange ] needs no change output
+type CoinSelector interface {
+	Select(coins []*Coin, target, costOfChange btcutil.Amount) (selected []*Coin, err error)
+}
+
+//--------------------------------------------------------------------------------//
+// largest first
+
+// LargestFirstSelector spends biggest coins first ( least inputs )
+type LargestFirstSelector struct{}
+
+func (t *LargestFirstSelector) Select(coins []*Coin, target, costOfChange btcutil.Amount) (selected []*Coin, err error) {
+	sorted := sortCoins(coins, func(a, b *Coin) bool {
+		return a.EffectiveValue > b.EffectiveValue
+	})
+	return accumulate(sorted, target)
+}
+
+//--------------------------------------------------------------------------------//
+// oldest first
+
+// OldestFirstSelector spends coins with most confirmations first
+type OldestFirstSelector struct{}
+
+func (t *OldestFirstSelector) Select(coins []*Coin, target, costOfChange btcutil.Amount) (selected []*Coin, err error) {
+	sorted := sortCoins(coins, func(a, b *Coin) bool {
+		if a.Confirmations != b.Confirmations {
+			return a.Confirmations > b.Confirmations
+		}
+		return a.EffectiveValue > b.EffectiveValue
+	})
+	return accumulate(sorted, target)
+}
+
+//--------------------------------------------------------------------------------//
+// branch and bound
+
+// BranchAndBoundSelector searches coins matching target within cost of change ( no change output )
+// falls back to Fallback if no exact match is found ( nil returns ErrNoExactMatch )
+type BranchAndBoundSelector struct {
+	MaxTries int
+	Fallback CoinSelector
+}
+
+func (t *BranchAndBoundSelector) Select(coins []*Coin, target, costOfChange btcutil.Amount) (selected []*Coin, err error) {
+	selected, err = t.selectExact(coins, target, costOfChange)
+	if errors.Is(err, ErrNoExactMatch) == true && t.Fallback != nil {
+		return t.Fallback.Select(coins, target, costOfChange)
+	}
+	return selected, err
+}
+
+func (t *BranchAndBoundSelector) selectExact(coins []*Coin, target, costOfChange btcutil.Amount) (selected []*Coin, err error) {
+	maxTries := t.MaxTries
+	if maxTries <= 0 {
+		maxTries = DEF_bnbMaxTries
+	}
+	sorted := sortCoins(coins, func(a, b *Coin) bool {
+		return a.EffectiveValue > b.EffectiveValue
+	})
+
+	var available btcutil.Amount
+	for _, coin := range sorted {
+		available += coin.EffectiveValue
+	}
+	if available < target {
+		return nil, ErrInsufficientFunds
+	}
+
+	// depth first search - include / exclude each coin in descending order
+	var (
+		selection    []int // index of included coins
+		currentValue btcutil.Amount
+		best         []int
+		bestWaste    btcutil.Amount = -1
+	)
+	for tries, idx := 0, 0; tries < maxTries; tries, idx = tries+1, idx+1 {
+		backtrack := false
+		if currentValue+available < target || currentValue > target+costOfChange {
+			// cannot reach target or exceeded range
+			backtrack = true
+		} else if currentValue >= target {
+			waste := currentValue - target
+			if bestWaste < 0 || waste < bestWaste {
+				best, bestWaste = append([]int(nil), selection...), waste
+				if waste == 0 {
+					break
+				}
+			}
+			backtrack = true
+		}
+
+		if backtrack == true {
+			if len(selection) == 0 {
+				break // searched every branch
+			}
+			// give back skipped coins, then exclude last included coin
+			last := selection[len(selection)-1]
+			for idx--; idx > last; idx-- {
+				available += sorted[idx].EffectiveValue
+			}
+			currentValue -= sorted[last].EffectiveValue
+			selection = selection[:len(selection)-1]
+		} else {
+			available -= sorted[idx].EffectiveValue
+			// skip coin equal to previous excluded one ( same branch already searched )
+			if idx > 0 && sorted[idx].EffectiveValue == sorted[idx-1].EffectiveValue &&
+				(len(selection) == 0 || selection[len(selection)-1] != idx-1) {
+				continue
+			}
+			selection = append(selection, idx)
+			currentValue += sorted[idx].EffectiveValue
+		}
+	}
+
+	if best == nil {
+		return nil, ErrNoExactMatch
+	}
+	for _, idx := range best {
+		selected = append(selected, sorted[idx])
+	}
+	return selected, nil
+}
+
+//--------------------------------------------------------------------------------//
+// knapsack
+
+// KnapsackSelector approximates the smallest subset over target by random search ( bitcoin core legacy selection )
+type KnapsackSelector struct {
+	Iterations int
+	Rand       *rand.Rand // nil uses time seeded source
+}
+
+func (t *KnapsackSelector) Select(coins []*Coin, target, costOfChange btcutil.Amount) (selected []*Coin, err error) {
+	iterations := t.Iterations
+	if iterations <= 0 {
+		iterations = DEF_knapsackIterations
+	}
+	random := t.Rand
+	if random == nil {
+		random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	// split into coins under target and smallest coin over target
+	var (
+		lower      []*Coin
+		lowerTotal btcutil.Amount
+		larger     *Coin
+	)
+	for _, coin := range coins {
+		if coin.EffectiveValue <= 0 {
+			continue
+		}
+		switch {
+		case coin.EffectiveValue == target:
+			return []*Coin{coin}, nil
+		case coin.EffectiveValue < target+costOfChange:
+			lower = append(lower, coin)
+			lowerTotal += coin.EffectiveValue
+		case larger == nil || coin.EffectiveValue < larger.EffectiveValue:
+			larger = coin
+		}
+	}
+
+	if lowerTotal == target {
+		return lower, nil
+	}
+	if lowerTotal < target {
+		if larger == nil {
+			return nil, ErrInsufficientFunds
+		}
+		return []*Coin{larger}, nil
+	}
+
+	lower = sortCoins(lower, func(a, b *Coin) bool {
+		return a.EffectiveValue > b.EffectiveValue
+	})
+	best, bestValue := approximateBestSubset(random, lower, lowerTotal, target, iterations)
+	if bestValue != target && lowerTotal >= target+costOfChange {
+		// retry with room for change output
+		best, bestValue = approximateBestSubset(random, lower, lowerTotal, target+costOfChange, iterations)
+	}
+
+	// single larger coin is better than subset which needs change anyway or wastes more
+	if larger != nil && ((bestValue != target && bestValue < target+costOfChange) || larger.EffectiveValue <= bestValue) {
+		return []*Coin{larger}, nil
+	}
+	for i, include := range best {
+		if include == true {
+			selected = append(selected, lower[i])
+		}
+	}
+	return selected, nil
+}
+
+func approximateBestSubset(random *rand.Rand, coins []*Coin, total, target btcutil.Amount, iterations int) (best []bool, bestValue btcutil.Amount) {
+	best = make([]bool, len(coins))
+	for i := range best {
+		best[i] = true
+	}
+	bestValue = total
+
+	included := make([]bool, len(coins))
+	for rep := 0; rep < iterations && bestValue != target; rep++ {
+		for i := range included {
+			included[i] = false
+		}
+		var value btcutil.Amount
+		reached := false
+		for pass := 0; pass < 2 && reached == false; pass++ {
+			for i, coin := range coins {
+				// first pass picks randomly, second pass fills up remaining
+				if (pass == 0 && random.Intn(2) == 1) || (pass == 1 && included[i] == false) {
+					value += coin.EffectiveValue
+					included[i] = true
+					if value >= target {
+						reached = true
+						if value < bestValue {
+							bestValue = value
+							copy(best, included)
+						}
+						value -= coin.EffectiveValue
+						included[i] = false
+					}
+				}
+			}
+		}
+	}
+	return best, bestValue
+}
+
+//--------------------------------------------------------------------------------//
+// util
+
+func sortCoins(coins []*Coin, less func(a, b *Coin) bool) (sorted []*Coin) {
+	sorted = make([]*Coin, 0, len(coins))
+	for _, coin := range coins {
+		if coin.EffectiveValue > 0 { // skip uneconomic coin
+			sorted = append(sorted, coin)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return less(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+func accumulate(sorted []*Coin, target btcutil.Amount) (selected []*Coin, err error) {
+	var value btcutil.Amount
+	for _, coin := range sorted {
+		selected = append(selected, coin)
+		value += coin.EffectiveValue
+		if value >= target {
+			return selected, nil
+		}
+	}
+	return nil, ErrInsufficientFunds
+}
@@ -0,0 +1,102 @@
+package btc
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCoins(values ...btcutil.Amount) []*Coin {
+	coins := make([]*Coin, 0, len(values))
+	for i, value := range values {
+		coins = append(coins, &Coin{
+			Txid:           DEF_txid_dummy,
+			Vout:           uint32(i),
+			Amount:         value,
+			Confirmations:  int64(i + 1),
+			EffectiveValue: value,
+		})
+	}
+	return coins
+}
+
+func sumCoins(coins []*Coin) (sum btcutil.Amount) {
+	for _, coin := range coins {
+		sum += coin.EffectiveValue
+	}
+	return sum
+}
+
+func TestCoinSelect(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		selector CoinSelector
+		values   []btcutil.Amount
+		target   btcutil.Amount
+		expect   []uint32 // vout of selected coins
+	}{
+		{"largest first", &LargestFirstSelector{}, []btcutil.Amount{1000, 5000, 3000}, 6000, []uint32{1, 2}},
+		{"oldest first", &OldestFirstSelector{}, []btcutil.Amount{1000, 5000, 3000}, 3500, []uint32{2, 1}},
+		{"bnb exact", &BranchAndBoundSelector{}, []btcutil.Amount{1000, 2000, 3000, 4000, 8000}, 7000, []uint32{3, 2}},
+		{"knapsack exact coin", &KnapsackSelector{}, []btcutil.Amount{1000, 2000, 7000}, 7000, []uint32{2}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			selected, err := test.selector.Select(newTestCoins(test.values...), test.target, 0)
+			require.NoError(t, err)
+			vouts := make([]uint32, 0, len(selected))
+			for _, coin := range selected {
+				vouts = append(vouts, coin.Vout)
+			}
+			require.Equal(t, test.expect, vouts)
+		})
+	}
+}
+
+func TestCoinSelectBranchAndBound(t *testing.T) {
+	coins := newTestCoins(1000, 2000, 3000, 4000, 8000)
+
+	// within cost of change
+	selected, err := (&BranchAndBoundSelector{}).Select(coins, 6500, 600)
+	require.NoError(t, err)
+	require.Equal(t, btcutil.Amount(7000), sumCoins(selected))
+
+	// no exact match, no fallback
+	_, err = (&BranchAndBoundSelector{}).Select(coins, 17500, 100)
+	require.ErrorIs(t, err, ErrNoExactMatch)
+
+	// no exact match, fallback
+	selected, err = (&BranchAndBoundSelector{Fallback: &LargestFirstSelector{}}).Select(coins, 17500, 100)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, sumCoins(selected), btcutil.Amount(17500))
+}
+
+func TestCoinSelectKnapsack(t *testing.T) {
+	coins := newTestCoins(1000, 2000, 3000, 50000)
+	selector := &KnapsackSelector{Rand: rand.New(rand.NewSource(1))}
+
+	// subset of small coins is preferred over larger coin
+	selected, err := selector.Select(coins, 5000, 0)
+	require.NoError(t, err)
+	require.Equal(t, btcutil.Amount(5000), sumCoins(selected))
+
+	// small coins are not enough
+	selected, err = selector.Select(coins, 7000, 0)
+	require.NoError(t, err)
+	require.Equal(t, btcutil.Amount(50000), sumCoins(selected))
+}
+
+func TestCoinSelectInsufficient(t *testing.T) {
+	coins := newTestCoins(1000, 2000, 3000)
+	coins = append(coins, &Coin{Amount: 100, EffectiveValue: -50}) // uneconomic coin is ignored
+	for _, selector := range []CoinSelector{
+		&LargestFirstSelector{},
+		&OldestFirstSelector{},
+		&BranchAndBoundSelector{Fallback: &KnapsackSelector{}},
+		&KnapsackSelector{},
+	} {
+		_, err := selector.Select(coins, 6001, 0)
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+	}
+}
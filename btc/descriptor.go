@@ -0,0 +1,69 @@
+package btc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// output script descriptor checksum ( BIP380 )
+
+const (
+	DEF_descInputCharset    = "0123456789()[],'/*abcdefgh@:$%{}IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	DEF_descChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+var descGenerator = [5]uint64{0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd}
+
+// DescriptorChecksum returns 8 character checksum of descriptor ( without #checksum )
+func DescriptorChecksum(desc string) (checksum string, err error) {
+	var (
+		chk    uint64 = 1
+		cls    uint64
+		clsCnt int
+	)
+	polymod := func(value uint64) {
+		top := chk >> 35
+		chk = (chk&0x7ffffffff)<<5 ^ value
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= descGenerator[i]
+			}
+		}
+	}
+
+	for _, ch := range desc {
+		pos := strings.IndexRune(DEF_descInputCharset, ch)
+		if pos == -1 {
+			return "", fmt.Errorf("invalid character in descriptor | %q", ch)
+		}
+		polymod(uint64(pos) & 31)
+		cls = cls*3 + uint64(pos)>>5
+		clsCnt++
+		if clsCnt == 3 {
+			polymod(cls)
+			cls, clsCnt = 0, 0
+		}
+	}
+	if clsCnt > 0 {
+		polymod(cls)
+	}
+	for i := 0; i < 8; i++ {
+		polymod(0)
+	}
+	chk ^= 1
+
+	buf := make([]byte, 8)
+	for i := 0; i < 8; i++ {
+		buf[i] = DEF_descChecksumCharset[(chk>>(5*(7-i)))&31]
+	}
+	return string(buf), nil
+}
+
+// AddDescriptorChecksum returns desc#checksum
+func AddDescriptorChecksum(desc string) (descWithChecksum string, err error) {
+	checksum, err := DescriptorChecksum(desc)
+	if err != nil {
+		return "", err
+	}
+	return desc + "#" + checksum, nil
+}
@@ -0,0 +1,289 @@
+package btc
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+const (
+	DEF_electrumClientName      = "blockchain_rpc"
+	DEF_electrumProtocolVersion = "1.4"
+	DEF_electrumTimeout         = 30 * time.Second
+)
+
+var (
+	ErrElectrumBroken = errors.New("electrum connection is broken") // connection failed and redial failed too
+)
+
+// ElectrumBackend queries electrum server ( electrs, ElectrumX, fulcrum ) by scripthash
+// requests are sent one by one on single connection, connection is closed on read / write error and redialed by next request
+type ElectrumBackend struct {
+	addr      string
+	tlsConfig *tls.Config
+
+	mtx    sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+	id     uint64
+	broken error // cause of broken connection
+	closed bool
+}
+
+// NewElectrumBackend connects to electrum server ( host:port ), tlsConfig is nil for tcp without TLS
+func NewElectrumBackend(ctx context.Context, addr string, tlsConfig *tls.Config) (backend *ElectrumBackend, err error) {
+	backend = &ElectrumBackend{
+		addr:      addr,
+		tlsConfig: tlsConfig,
+	}
+	backend.mtx.Lock()
+	defer backend.mtx.Unlock()
+	err = backend.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return backend, nil
+}
+
+// Close closes connection, waits for pending request
+func (t *ElectrumBackend) Close() error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.closed = true
+	if t.broken != nil {
+		return nil // already closed
+	}
+	t.broken = net.ErrClosed
+	return t.conn.Close()
+}
+
+func (t *ElectrumBackend) ListUnspent(ctx context.Context, addr btcutil.Address) (unspents []*AddressUnspent, err error) {
+	scriptHash, err := electrumScriptHash(addr)
+	if err != nil {
+		return nil, err
+	}
+	var res []struct {
+		TxHash string `json:"tx_hash"`
+		TxPos  uint32 `json:"tx_pos"`
+		Height int64  `json:"height"`
+		Value  int64  `json:"value"`
+	}
+	err = t.call(ctx, "blockchain.scripthash.listunspent", []interface{}{scriptHash}, &res)
+	if err != nil {
+		return nil, err
+	}
+	unspents = make([]*AddressUnspent, 0, len(res))
+	for _, r := range res {
+		unspents = append(unspents, &AddressUnspent{
+			Txid:   r.TxHash,
+			Vout:   r.TxPos,
+			Amount: btcutil.Amount(r.Value),
+			Height: electrumHeight(r.Height),
+		})
+	}
+	return unspents, nil
+}
+
+func (t *ElectrumBackend) GetBalance(ctx context.Context, addr btcutil.Address) (balance *AddressBalance, err error) {
+	scriptHash, err := electrumScriptHash(addr)
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		Confirmed   int64 `json:"confirmed"`
+		Unconfirmed int64 `json:"unconfirmed"`
+	}
+	err = t.call(ctx, "blockchain.scripthash.get_balance", []interface{}{scriptHash}, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &AddressBalance{
+		Confirmed:   btcutil.Amount(res.Confirmed),
+		Unconfirmed: btcutil.Amount(res.Unconfirmed),
+	}, nil
+}
+
+func (t *ElectrumBackend) GetHistory(ctx context.Context, addr btcutil.Address) (history []*AddressTx, err error) {
+	scriptHash, err := electrumScriptHash(addr)
+	if err != nil {
+		return nil, err
+	}
+	var res []struct {
+		TxHash string `json:"tx_hash"`
+		Height int64  `json:"height"`
+	}
+	err = t.call(ctx, "blockchain.scripthash.get_history", []interface{}{scriptHash}, &res)
+	if err != nil {
+		return nil, err
+	}
+	history = make([]*AddressTx, 0, len(res))
+	for _, r := range res {
+		history = append(history, &AddressTx{
+			Txid:   r.TxHash,
+			Height: electrumHeight(r.Height),
+		})
+	}
+	return history, nil
+}
+
+func (t *ElectrumBackend) GetTipHeight(ctx context.Context) (height int64, err error) {
+	var res struct {
+		Height int64 `json:"height"`
+	}
+	err = t.call(ctx, "blockchain.headers.subscribe", []interface{}{}, &res)
+	if err != nil {
+		return 0, err
+	}
+	return res.Height, nil
+}
+
+// call sends request and waits for response of the same id ( notifications are skipped )
+// ctx cancel interrupts pending read / write, connection is broken then and redialed by next call
+func (t *ElectrumBackend) call(ctx context.Context, method string, params []interface{}, result interface{}) (err error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if t.closed == true {
+		return fmt.Errorf("%w | %s | %v", ErrElectrumBroken, method, net.ErrClosed)
+	}
+	if t.broken != nil {
+		err = t.dial(ctx)
+		if err != nil {
+			return fmt.Errorf("%w | %s | %v", ErrElectrumBroken, method, err)
+		}
+	}
+	return t.request(ctx, method, params, result)
+}
+
+// dial connects and negotiates protocol version ( mtx must be held )
+func (t *ElectrumBackend) dial(ctx context.Context) (err error) {
+	dialer := &net.Dialer{Timeout: DEF_electrumTimeout}
+	var conn net.Conn
+	if t.tlsConfig != nil {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: t.tlsConfig}).DialContext(ctx, "tcp", t.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", t.addr)
+	}
+	if err != nil {
+		return fmt.Errorf("electrum connect failed | %s | %w", t.addr, err)
+	}
+	t.conn = conn
+	t.reader = bufio.NewReader(conn)
+	t.broken = nil
+
+	// protocol version must be negotiated first
+	var version []string
+	err = t.request(ctx, "server.version", []interface{}{DEF_electrumClientName, DEF_electrumProtocolVersion}, &version)
+	if err != nil {
+		if t.broken == nil {
+			t.setBroken(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// request sends request on current connection ( mtx must be held )
+func (t *ElectrumBackend) request(ctx context.Context, method string, params []interface{}, result interface{}) (err error) {
+	defer func() {
+		if err != nil && ctx.Err() != nil {
+			err = fmt.Errorf("%w | %v", ctx.Err(), err)
+		}
+	}()
+
+	deadline, ok := ctx.Deadline()
+	if ok == false {
+		deadline = time.Now().Add(DEF_electrumTimeout)
+	}
+	err = t.conn.SetDeadline(deadline)
+	if err != nil {
+		return t.setBroken(err)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		t.conn.SetDeadline(time.Now()) // unblock read / write
+	})
+	defer stop()
+
+	t.id++
+	req, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": t.id, "method": method, "params": params})
+	if err != nil {
+		return err
+	}
+	_, err = t.conn.Write(append(req, '\n'))
+	if err != nil {
+		return t.setBroken(fmt.Errorf("electrum request failed | %s | %w", method, err))
+	}
+
+	for {
+		line, err := t.reader.ReadBytes('\n')
+		if err != nil {
+			return t.setBroken(fmt.Errorf("electrum response failed | %s | %w", method, err))
+		}
+		var res struct {
+			ID     *uint64         `json:"id"`
+			Result json.RawMessage `json:"result"`
+			Error  *struct {
+				Code    int    `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		err = json.Unmarshal(line, &res)
+		if err != nil {
+			return t.setBroken(fmt.Errorf("invalid electrum response | %s | %w", method, err))
+		}
+		if res.ID == nil {
+			continue // notification
+		}
+		if *res.ID != t.id {
+			// response of timed out request never arrives ( connection is broken then ), stream is out of sync
+			return t.setBroken(fmt.Errorf("unexpected electrum response | %s | id : %d", method, *res.ID))
+		}
+		if res.Error != nil {
+			return fmt.Errorf("electrum error | %s | code : %d | %s", method, res.Error.Code, res.Error.Message)
+		}
+		err = json.Unmarshal(res.Result, result)
+		if err != nil {
+			return fmt.Errorf("invalid electrum result | %s | %w", method, err) // whole line is read, stream is in sync
+		}
+		return nil
+	}
+}
+
+// setBroken closes connection, next call redials ( mtx must be held )
+func (t *ElectrumBackend) setBroken(cause error) error {
+	t.broken = cause
+	t.conn.Close()
+	return cause
+}
+
+// electrumScriptHash is reversed sha256 of output script in hex
+func electrumScriptHash(addr btcutil.Address) (scriptHash string, err error) {
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(pkScript)
+	for i, j := 0, len(hash)-1; i < j; i, j = i+1, j-1 {
+		hash[i], hash[j] = hash[j], hash[i]
+	}
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// electrumHeight converts height of mempool tx ( 0 or -1 if parent is unconfirmed ) to 0
+func electrumHeight(height int64) int64 {
+	if height < 0 {
+		return 0
+	}
+	return height
+}
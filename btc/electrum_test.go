@@ -0,0 +1,149 @@
+package btc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestElectrumServer answers results by method, notification is sent before every response
+// empty result is never answered
+func newTestElectrumServer(t *testing.T, results map[string]string) (addr string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					var req struct {
+						ID     uint64        `json:"id"`
+						Method string        `json:"method"`
+						Params []interface{} `json:"params"`
+					}
+					if json.Unmarshal(scanner.Bytes(), &req) != nil {
+						return
+					}
+					fmt.Fprintf(conn, `{"jsonrpc":"2.0","method":"blockchain.headers.subscribe","params":[{"height":1}]}`+"\n")
+					result, ok := results[req.Method]
+					if ok == true && result == "" {
+						continue
+					}
+					if ok == false {
+						fmt.Fprintf(conn, `{"jsonrpc":"2.0","id":%d,"error":{"code":-32601,"message":"unknown method"}}`+"\n", req.ID)
+						continue
+					}
+					fmt.Fprintf(conn, `{"jsonrpc":"2.0","id":%d,"result":%s}`+"\n", req.ID, result)
+				}
+			}(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestElectrumScriptHash(t *testing.T) {
+	// electrum protocol docs example
+	addr, err := btcutil.DecodeAddress("1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	scriptHash, err := electrumScriptHash(addr)
+	require.NoError(t, err)
+	require.Equal(t, "8b01df4e368ea28f8dc0423bcf7a4923e3a12d307c875e47a0cfbf90b5c39161", scriptHash)
+}
+
+func TestElectrumBackend(t *testing.T) {
+	ctx := context.Background()
+	addr := newTestElectrumServer(t, map[string]string{
+		"server.version":                    `["ElectrumX 1.16.0", "1.4"]`,
+		"blockchain.headers.subscribe":      `{"height":120,"hex":"00"}`,
+		"blockchain.scripthash.listunspent": `[{"tx_hash":"` + DEF_txid_dummy + `","tx_pos":1,"height":100,"value":50000},{"tx_hash":"` + DEF_txid_dummy + `","tx_pos":2,"height":0,"value":7000}]`,
+		"blockchain.scripthash.get_balance": `{"confirmed":50000,"unconfirmed":7000}`,
+		"blockchain.scripthash.get_history": `[{"tx_hash":"aa","height":100},{"tx_hash":"bb","height":-1,"fee":200}]`,
+		"blockchain.slow":                   ``,
+	})
+	backend, err := NewElectrumBackend(ctx, addr, nil)
+	require.NoError(t, err)
+	defer backend.Close()
+
+	btcAddr, err := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), &chaincfg.RegressionNetParams)
+	require.NoError(t, err)
+
+	height, err := backend.GetTipHeight(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(120), height)
+
+	unspents, err := backend.ListUnspent(ctx, btcAddr)
+	require.NoError(t, err)
+	require.Equal(t, []*AddressUnspent{
+		{Txid: DEF_txid_dummy, Vout: 1, Amount: 50000, Height: 100},
+		{Txid: DEF_txid_dummy, Vout: 2, Amount: 7000, Height: 0},
+	}, unspents)
+
+	balance, err := backend.GetBalance(ctx, btcAddr)
+	require.NoError(t, err)
+	require.Equal(t, &AddressBalance{Confirmed: 50000, Unconfirmed: 7000}, balance)
+
+	history, err := backend.GetHistory(ctx, btcAddr)
+	require.NoError(t, err)
+	require.Equal(t, []*AddressTx{{Txid: "aa", Height: 100}, {Txid: "bb", Height: 0}}, history)
+
+	// error response
+	err = backend.call(ctx, "blockchain.unknown", []interface{}{}, nil)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrElectrumBroken)
+
+	// undecodable result keeps connection
+	conn := backend.conn
+	var invalid struct{ Height string }
+	err = backend.call(ctx, "blockchain.headers.subscribe", []interface{}{}, &invalid)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrElectrumBroken)
+	height, err = backend.GetTipHeight(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(120), height)
+	require.Equal(t, conn, backend.conn)
+
+	// cancel interrupts pending read, connection is not reused but redialed
+	ctxCancel, cancel := context.WithCancel(ctx)
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	err = backend.call(ctxCancel, "blockchain.slow", []interface{}{}, nil)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Less(t, time.Since(start), DEF_electrumTimeout)
+	height, err = backend.GetTipHeight(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(120), height)
+	require.NotEqual(t, conn, backend.conn)
+
+	// closed backend is not redialed
+	require.NoError(t, backend.Close())
+	_, err = backend.GetTipHeight(ctx)
+	require.ErrorIs(t, err, ErrElectrumBroken)
+}
+
+func TestElectrumRedialFail(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	backend := &ElectrumBackend{addr: addr, broken: io.EOF}
+	_, err = backend.GetTipHeight(context.Background())
+	require.ErrorIs(t, err, ErrElectrumBroken)
+	require.Nil(t, backend.conn)
+}
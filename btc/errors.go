@@ -0,0 +1,119 @@
+package btc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcjson"
+)
+
+// sentinel errors of bitcoin core rpc, use with errors.Is
+// ErrInsufficientFunds ( coin selection ) is also returned for wallet error -6
+var (
+	// retry later
+	ErrNodeWarmingUp      = errors.New("node is warming up")
+	ErrNodeInitialSync    = errors.New("node is in initial block download")
+	ErrFeeEstimateFailed  = errors.New("fee estimation failed")
+	ErrTxMempoolChainFull = errors.New("too long mempool chain")
+
+	// bump fee
+	ErrTxFeeTooLow = errors.New("fee too low") // min relay fee, mempool min fee, rbf insufficient fee
+
+	// already done
+	ErrTxAlreadyInChain   = errors.New("tx already in chain")
+	ErrTxAlreadyInMempool = errors.New("tx already in mempool")
+
+	// alert
+	ErrWalletLocked      = errors.New("wallet is locked")
+	ErrWalletPassphrase  = errors.New("wallet passphrase is incorrect")
+	ErrWalletNotFound    = errors.New("wallet not found")
+	ErrTxMempoolConflict = errors.New("tx conflicts with mempool")
+	ErrTxInputsMissing   = errors.New("tx inputs are missing or spent")
+	ErrTxNonFinal        = errors.New("tx is non final")
+	ErrTxMaxFeeExceeded  = errors.New("tx fee exceeds max fee rate")
+	ErrTxRejected        = errors.New("tx rejected") // other reject reasons
+)
+
+// RPCError is error returned by node with classified sentinel
+type RPCError struct {
+	Code    btcjson.RPCErrorCode
+	Message string
+	kind    error // nil if not classified
+}
+
+func (t *RPCError) Error() string {
+	return fmt.Sprintf("rpc error | code : %d | %s", t.Code, t.Message)
+}
+
+func (t *RPCError) Unwrap() error {
+	return t.kind
+}
+
+// reject reason of -25 / -26 ( message of AcceptToMemoryPool ) -> sentinel
+var rejectReasons = []struct {
+	reason string
+	kind   error
+}{
+	{"txn-mempool-conflict", ErrTxMempoolConflict},
+	{"txn-already-in-mempool", ErrTxAlreadyInMempool},
+	{"txn-already-known", ErrTxAlreadyInMempool},
+	{"min relay fee not met", ErrTxFeeTooLow},
+	{"mempool min fee not met", ErrTxFeeTooLow},
+	{"insufficient fee", ErrTxFeeTooLow},
+	{"max-fee-exceeded", ErrTxMaxFeeExceeded},
+	{"fee exceeds maximum", ErrTxMaxFeeExceeded},
+	{"non-bip68-final", ErrTxNonFinal},
+	{"non-final", ErrTxNonFinal},
+	{"too-long-mempool-chain", ErrTxMempoolChainFull},
+	{"missingorspent", ErrTxInputsMissing},
+	{"missing inputs", ErrTxInputsMissing},
+}
+
+// classifyError converts btcjson.RPCError to RPCError wrapped with its sentinel ( "sentinel | rpc error | code : -26 | message" )
+// code and message of node are kept, other errors are returned as is
+func classifyError(err error) error {
+	var rpcErr *btcjson.RPCError
+	if err == nil || errors.As(err, &rpcErr) == false {
+		return err
+	}
+	classified := &RPCError{
+		Code:    rpcErr.Code,
+		Message: rpcErr.Message,
+		kind:    classifyCode(rpcErr.Code, rpcErr.Message),
+	}
+	if classified.kind == nil {
+		return classified
+	}
+	return fmt.Errorf("%w | %w", classified.kind, classified)
+}
+
+func classifyCode(code btcjson.RPCErrorCode, message string) error {
+	switch code {
+	case btcjson.ErrRPCInWarmup:
+		return ErrNodeWarmingUp
+	case btcjson.ErrRPCClientInInitialDownload:
+		return ErrNodeInitialSync
+	case btcjson.ErrRPCWalletInsufficientFunds:
+		return ErrInsufficientFunds
+	case btcjson.ErrRPCWalletUnlockNeeded:
+		return ErrWalletLocked
+	case btcjson.ErrRPCWalletPassphraseIncorrect:
+		return ErrWalletPassphrase
+	case btcjson.ErrRPCWalletNotFound:
+		return ErrWalletNotFound
+	case btcjson.ErrRPCVerifyAlreadyInChain:
+		return ErrTxAlreadyInChain
+	case btcjson.ErrRPCVerify, btcjson.ErrRPCVerifyRejected:
+		message = strings.ToLower(message)
+		for _, reject := range rejectReasons {
+			if strings.Contains(message, reject.reason) == true {
+				return reject.kind
+			}
+		}
+		if code == btcjson.ErrRPCVerifyRejected {
+			return ErrTxRejected
+		}
+	}
+	return nil
+}
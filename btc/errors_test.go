@@ -0,0 +1,61 @@
+package btc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyError(t *testing.T) {
+	for _, test := range []struct {
+		code    btcjson.RPCErrorCode
+		message string
+		kind    error
+	}{
+		// messages of bitcoin core
+		{-6, "Insufficient funds", ErrInsufficientFunds},
+		{-13, "Error: Please enter the wallet passphrase with walletpassphrase first.", ErrWalletLocked},
+		{-18, "Requested wallet does not exist or is not loaded", ErrWalletNotFound},
+		{-27, "Transaction already in block chain", ErrTxAlreadyInChain},
+		{-26, "txn-mempool-conflict", ErrTxMempoolConflict},
+		{-26, "min relay fee not met, 100 < 141", ErrTxFeeTooLow},
+		{-26, "mempool min fee not met, 141 < 282", ErrTxFeeTooLow},
+		{-26, "insufficient fee, rejecting replacement 0f2c..., not enough additional fees to relay; 0.00 < 0.00000141", ErrTxFeeTooLow},
+		{-26, "non-final", ErrTxNonFinal},
+		{-26, "non-BIP68-final", ErrTxNonFinal},
+		{-25, "bad-txns-inputs-missingorspent", ErrTxInputsMissing},
+		{-26, "max-fee-exceeded", ErrTxMaxFeeExceeded},
+		{-26, "dust", ErrTxRejected},
+		{-28, "Loading block index...", ErrNodeWarmingUp},
+	} {
+		err := classifyError(fmt.Errorf("send failed | %w", btcjson.NewRPCError(test.code, test.message)))
+		require.ErrorIs(t, err, test.kind, test.message)
+
+		var rpcErr *RPCError
+		require.True(t, errors.As(err, &rpcErr))
+		require.Equal(t, test.code, rpcErr.Code)
+
+		// message of node is kept with sentinel
+		require.Contains(t, err.Error(), test.kind.Error())
+		require.Contains(t, err.Error(), test.message)
+	}
+
+	// unknown code is kept as RPCError without sentinel
+	err := classifyError(btcjson.NewRPCError(-8, "Invalid parameter"))
+	require.Nil(t, errors.Unwrap(err))
+	require.Contains(t, err.Error(), "Invalid parameter")
+
+	// non rpc error
+	require.Equal(t, context.Canceled, classifyError(context.Canceled))
+	require.Nil(t, classifyError(nil))
+
+	// rpc call result is classified
+	_, err = wait(context.Background(), 0, func() (string, error) {
+		return "", btcjson.NewRPCError(-26, "txn-mempool-conflict")
+	})
+	require.ErrorIs(t, err, ErrTxMempoolConflict)
+}
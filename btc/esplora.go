@@ -0,0 +1,168 @@
+package btc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+const (
+	DEF_esploraTimeout       = 30 * time.Second
+	DEF_esploraChainPageSize = 25 // confirmed txs per page of /address/:address/txs/chain
+)
+
+// EsploraBackend queries esplora rest api ( blockstream.info/api, mempool.space/api, self hosted electrs --http-addr )
+type EsploraBackend struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewEsploraBackend creates backend of base url ( ex : https://blockstream.info/testnet/api ), httpClient is optional
+func NewEsploraBackend(baseURL string, httpClient *http.Client) *EsploraBackend {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DEF_esploraTimeout}
+	}
+	return &EsploraBackend{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  httpClient,
+	}
+}
+
+type esploraStatus struct {
+	Confirmed   bool  `json:"confirmed"`
+	BlockHeight int64 `json:"block_height"`
+}
+
+func (t esploraStatus) height() int64 {
+	if t.Confirmed == false {
+		return 0
+	}
+	return t.BlockHeight
+}
+
+type esploraStats struct {
+	FundedTxoSum int64 `json:"funded_txo_sum"`
+	SpentTxoSum  int64 `json:"spent_txo_sum"`
+}
+
+func (t *EsploraBackend) ListUnspent(ctx context.Context, addr btcutil.Address) (unspents []*AddressUnspent, err error) {
+	var res []struct {
+		Txid   string        `json:"txid"`
+		Vout   uint32        `json:"vout"`
+		Value  int64         `json:"value"`
+		Status esploraStatus `json:"status"`
+	}
+	err = t.get(ctx, "/address/"+addr.EncodeAddress()+"/utxo", &res)
+	if err != nil {
+		return nil, err
+	}
+	unspents = make([]*AddressUnspent, 0, len(res))
+	for _, r := range res {
+		unspents = append(unspents, &AddressUnspent{
+			Txid:   r.Txid,
+			Vout:   r.Vout,
+			Amount: btcutil.Amount(r.Value),
+			Height: r.Status.height(),
+		})
+	}
+	return unspents, nil
+}
+
+func (t *EsploraBackend) GetBalance(ctx context.Context, addr btcutil.Address) (balance *AddressBalance, err error) {
+	var res struct {
+		ChainStats   esploraStats `json:"chain_stats"`
+		MempoolStats esploraStats `json:"mempool_stats"`
+	}
+	err = t.get(ctx, "/address/"+addr.EncodeAddress(), &res)
+	if err != nil {
+		return nil, err
+	}
+	return &AddressBalance{
+		Confirmed:   btcutil.Amount(res.ChainStats.FundedTxoSum - res.ChainStats.SpentTxoSum),
+		Unconfirmed: btcutil.Amount(res.MempoolStats.FundedTxoSum - res.MempoolStats.SpentTxoSum),
+	}, nil
+}
+
+// GetHistory returns mempool txs first, then confirmed txs from newest
+func (t *EsploraBackend) GetHistory(ctx context.Context, addr btcutil.Address) (history []*AddressTx, err error) {
+	type esploraTx struct {
+		Txid   string        `json:"txid"`
+		Status esploraStatus `json:"status"`
+	}
+
+	// first page has mempool txs and first page of confirmed txs
+	path := "/address/" + addr.EncodeAddress() + "/txs"
+	for {
+		var res []esploraTx
+		err = t.get(ctx, path, &res)
+		if err != nil {
+			return nil, err
+		}
+
+		var confirmed int
+		var lastTxid string
+		for _, r := range res {
+			history = append(history, &AddressTx{Txid: r.Txid, Height: r.Status.height()})
+			if r.Status.Confirmed == true {
+				confirmed++
+				lastTxid = r.Txid
+			}
+		}
+		if confirmed < DEF_esploraChainPageSize {
+			return history, nil
+		}
+		path = "/address/" + addr.EncodeAddress() + "/txs/chain/" + lastTxid
+	}
+}
+
+func (t *EsploraBackend) GetTipHeight(ctx context.Context) (height int64, err error) {
+	body, err := t.request(ctx, "/blocks/tip/height")
+	if err != nil {
+		return 0, err
+	}
+	height, err = strconv.ParseInt(strings.TrimSpace(string(body)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid tip height | %s", body)
+	}
+	return height, nil
+}
+
+func (t *EsploraBackend) get(ctx context.Context, path string, result interface{}) (err error) {
+	body, err := t.request(ctx, path)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return fmt.Errorf("invalid esplora response | %s | %w", path, err)
+	}
+	return nil
+}
+
+func (t *EsploraBackend) request(ctx context.Context, path string) (body []byte, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("esplora request failed | %s | %w", path, err)
+	}
+	defer res.Body.Close()
+
+	body, err = io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("esplora error | %s | status : %d | %s", path, res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
@@ -0,0 +1,98 @@
+package btc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestEsploraServer answers body by path, unknown path is 404
+func newTestEsploraServer(t *testing.T, bodies map[string]string) *EsploraBackend {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := bodies[strings.TrimPrefix(r.URL.Path, "/api")]
+		if ok == false {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return NewEsploraBackend(server.URL+"/api/", nil)
+}
+
+func TestEsploraBackend(t *testing.T) {
+	ctx := context.Background()
+	btcAddr, err := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), &chaincfg.RegressionNetParams)
+	require.NoError(t, err)
+	address := btcAddr.EncodeAddress()
+
+	// 25 confirmed txs on first page -> next page
+	page := make([]string, 0, DEF_esploraChainPageSize+1)
+	page = append(page, `{"txid":"mempool","status":{"confirmed":false}}`)
+	for i := 0; i < DEF_esploraChainPageSize; i++ {
+		page = append(page, fmt.Sprintf(`{"txid":"tx%d","status":{"confirmed":true,"block_height":%d}}`, i, 200-i))
+	}
+	backend := newTestEsploraServer(t, map[string]string{
+		"/blocks/tip/height":                      "210\n",
+		"/address/" + address:                     `{"chain_stats":{"funded_txo_sum":90000,"spent_txo_sum":30000},"mempool_stats":{"funded_txo_sum":0,"spent_txo_sum":10000}}`,
+		"/address/" + address + "/utxo":           `[{"txid":"` + DEF_txid_dummy + `","vout":0,"value":60000,"status":{"confirmed":true,"block_height":200}}]`,
+		"/address/" + address + "/txs":            "[" + strings.Join(page, ",") + "]",
+		"/address/" + address + "/txs/chain/tx24": `[{"txid":"tx25","status":{"confirmed":true,"block_height":170}}]`,
+	})
+
+	height, err := backend.GetTipHeight(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(210), height)
+
+	unspents, err := backend.ListUnspent(ctx, btcAddr)
+	require.NoError(t, err)
+	require.Equal(t, []*AddressUnspent{{Txid: DEF_txid_dummy, Vout: 0, Amount: 60000, Height: 200}}, unspents)
+
+	balance, err := backend.GetBalance(ctx, btcAddr)
+	require.NoError(t, err)
+	require.Equal(t, &AddressBalance{Confirmed: 60000, Unconfirmed: -10000}, balance)
+
+	history, err := backend.GetHistory(ctx, btcAddr)
+	require.NoError(t, err)
+	require.Len(t, history, DEF_esploraChainPageSize+2)
+	require.Equal(t, &AddressTx{Txid: "mempool", Height: 0}, history[0])
+	require.Equal(t, &AddressTx{Txid: "tx25", Height: 170}, history[len(history)-1])
+
+	// not found
+	other, err := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	_, err = backend.ListUnspent(ctx, other)
+	require.Error(t, err)
+}
+
+func TestRawTxBackend(t *testing.T) {
+	client, _ := newTestRPCServer(t, map[string]string{"getaddressinfo": `{"ismine":false}`})
+	btcAddr, err := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), &chaincfg.RegressionNetParams)
+	require.NoError(t, err)
+	address := btcAddr.EncodeAddress()
+	backend := newTestEsploraServer(t, map[string]string{
+		"/blocks/tip/height":            "210",
+		"/address/" + address + "/utxo": `[{"txid":"` + DEF_txid_dummy + `","vout":0,"value":60000,"status":{"confirmed":true,"block_height":200}},{"txid":"` + DEF_txid_dummy + `","vout":1,"value":1000,"status":{"confirmed":false}}]`,
+	})
+
+	rawTx := &RawTx{}
+	require.NoError(t, rawTx.Init(client, address, 0))
+	require.NoError(t, rawTx.AddFrom(newTestKey(t, 1, true).String(), address))
+	rawTx.SetBackend(backend)
+
+	utxos, err := rawTx.utxoGet(context.Background())
+	require.NoError(t, err)
+	require.Len(t, utxos, 2)
+	require.Equal(t, address, utxos[0].FromAddr)
+	require.Equal(t, 0.0006, utxos[0].FromAmount)
+	require.Equal(t, int64(11), utxos[0].Confirmations)
+	require.Equal(t, "00140000000000000000000000000000000000000000", utxos[0].ScriptPubKey)
+	require.Equal(t, int64(0), utxos[1].Confirmations)
+}
@@ -0,0 +1,243 @@
+package btc
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	DEF_sequenceRBF                 = wire.MaxTxInSequenceNum - 2 // BIP125 opt-in
+	DEF_incrementalRelayFee FeeRate = 1                           // sat/vB, default of bitcoin core
+)
+
+// IsRBF returns true if any input signals BIP125 replaceability
+func IsRBF(msgTx *wire.MsgTx) bool {
+	for _, txIn := range msgTx.TxIn {
+		if txIn.Sequence < wire.MaxTxInSequenceNum-1 {
+			return true
+		}
+	}
+	return false
+}
+
+//--------------------------------------------------------------------------------//
+// replace by fee
+
+// BumpFee rebuilds tx of txid spending the same inputs at new fee rate and signs it with from private keys
+// fee increase is taken from change output to balance address ( dropped if under dust limit )
+// original tx must signal replaceability, returned tx is not broadcasted
+func (t *RawTx) BumpFee(ctx context.Context, txid string, feeRate FeeRate) (msgTxSigned *wire.MsgTx, err error) {
+	msgTxOrig, err := t.client.GetRawTx(ctx, txid)
+	if err != nil {
+		return nil, err
+	}
+	utxos, err := t.prevOutGet(ctx, msgTxOrig)
+	if err != nil {
+		return nil, err
+	}
+	t.utxoSetMultiSig(utxos)
+	t.utxoSetTimeLock(utxos) // scripts are needed to estimate size before sign
+	changeScript, err := txscript.PayToAddrScript(t.balanceAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	msgTx, err := bumpFee(msgTxOrig, utxos, changeScript, feeRate, t.dustLimit)
+	if err != nil {
+		return nil, err
+	}
+	return t.sign(ctx, msgTx, utxos)
+}
+
+// prevOutGet returns outputs spent by inputs of msgTx ( confirmed utxo set first, then parent tx in mempool )
+func (t *RawTx) prevOutGet(ctx context.Context, msgTx *wire.MsgTx) (utxos []*utxo, err error) {
+	utxos = make([]*utxo, 0, len(msgTx.TxIn))
+	for _, txIn := range msgTx.TxIn {
+		outPoint := txIn.PreviousOutPoint
+		var prevOut *wire.TxOut
+
+		txOut, err := t.client.GetTxOut(ctx, outPoint.Hash.String(), outPoint.Index, false)
+		if err != nil {
+			return nil, err
+		}
+		if txOut != nil {
+			amount, err := btcutil.NewAmount(txOut.Value)
+			if err != nil {
+				return nil, err
+			}
+			pkScript, err := hex.DecodeString(txOut.ScriptPubKey.Hex)
+			if err != nil {
+				return nil, err
+			}
+			prevOut = wire.NewTxOut(int64(amount), pkScript)
+		} else {
+			msgTxParent, err := t.client.GetRawTx(ctx, outPoint.Hash.String())
+			if err != nil {
+				return nil, err
+			}
+			if int(outPoint.Index) >= len(msgTxParent.TxOut) {
+				return nil, fmt.Errorf("invalid prev out | %v", outPoint)
+			}
+			prevOut = msgTxParent.TxOut[outPoint.Index]
+		}
+
+		utxos = append(utxos, &utxo{
+			Txid:         outPoint.Hash.String(),
+			Vout:         outPoint.Index,
+			FromAmount:   btcutil.Amount(prevOut.Value).ToBTC(),
+			ScriptPubKey: hex.EncodeToString(prevOut.PkScript),
+		})
+	}
+	return utxos, nil
+}
+
+func bumpFee(msgTxOrig *wire.MsgTx, utxos []*utxo, changeScript []byte, feeRate FeeRate, dustLimit btcutil.Amount) (msgTx *wire.MsgTx, err error) {
+	if IsRBF(msgTxOrig) == false {
+		return nil, fmt.Errorf("tx does not signal replaceability | %s", msgTxOrig.TxHash())
+	}
+	if len(utxos) != len(msgTxOrig.TxIn) {
+		return nil, fmt.Errorf("input count mismatch | tx : %d | utxo : %d", len(msgTxOrig.TxIn), len(utxos))
+	}
+
+	// same inputs and outputs, fee is taken from change
+	msgTx = wire.NewMsgTx(msgTxOrig.Version)
+	msgTx.LockTime = msgTxOrig.LockTime
+	estimator := &TxSizeEstimator{}
+	var feeOrig btcutil.Amount
+	for i, txIn := range msgTxOrig.TxIn {
+		outPoint := txIn.PreviousOutPoint
+		txInNew := wire.NewTxIn(&outPoint, nil, nil)
+		txInNew.Sequence = min(txIn.Sequence, DEF_sequenceRBF) // keep relative lock ( CSV )
+		msgTx.AddTxIn(txInNew)
+
+		pkScript, redeemScript, witnessScript, err := utxos[i].scripts()
+		if err != nil {
+			return nil, err
+		}
+		err = estimator.AddInput(pkScript, redeemScript, witnessScript)
+		if err != nil {
+			return nil, err
+		}
+		amount, err := btcutil.NewAmount(utxos[i].FromAmount)
+		if err != nil {
+			return nil, err
+		}
+		feeOrig += amount
+	}
+	changeIdx := -1
+	for i, txOut := range msgTxOrig.TxOut {
+		if changeIdx == -1 && bytes.Equal(txOut.PkScript, changeScript) == true {
+			changeIdx = i
+		}
+		msgTx.AddTxOut(wire.NewTxOut(txOut.Value, txOut.PkScript))
+		estimator.AddOutput(txOut.PkScript)
+		feeOrig -= btcutil.Amount(txOut.Value)
+	}
+	if changeIdx == -1 {
+		return nil, fmt.Errorf("no change output to reduce")
+	}
+
+	// BIP125 - replacement pays more than original plus its own relay fee
+	fee := estimator.Fee(feeRate)
+	if feeMin := feeOrig + DEF_incrementalRelayFee.Fee(estimator.VSize()); fee < feeMin {
+		fee = feeMin
+	}
+	change := btcutil.Amount(msgTx.TxOut[changeIdx].Value) - (fee - feeOrig)
+	switch {
+	case change < 0:
+		return nil, fmt.Errorf("not enough change to bump fee | change : %v | fee : %v", msgTx.TxOut[changeIdx].Value, fee)
+	case change < dustLimit:
+		// change under dust limit is left as fee
+		msgTx.TxOut = append(msgTx.TxOut[:changeIdx], msgTx.TxOut[changeIdx+1:]...)
+		if len(msgTx.TxOut) == 0 {
+			return nil, fmt.Errorf("not enough change to bump fee | change : %v | fee : %v", change, fee)
+		}
+	default:
+		msgTx.TxOut[changeIdx].Value = int64(change)
+	}
+	return msgTx, nil
+}
+
+//--------------------------------------------------------------------------------//
+// child pays for parent
+
+// CPFP spends change output of stuck parent tx to balance address and signs it with from private keys
+// child fee is set to make fee rate of parent + child package reach fee rate ( parent must be in mempool )
+// returned tx is not broadcasted
+func (t *RawTx) CPFP(ctx context.Context, parentTxid string, feeRate FeeRate) (msgTxSigned *wire.MsgTx, err error) {
+	mempoolEntry, err := t.client.GetMempoolEntry(ctx, parentTxid)
+	if err != nil {
+		return nil, err
+	}
+	parentFee, err := btcutil.NewAmount(mempoolEntry.Fees.Base)
+	if err != nil {
+		return nil, err
+	}
+	msgTxParent, err := t.client.GetRawTx(ctx, parentTxid)
+	if err != nil {
+		return nil, err
+	}
+	changeScript, err := txscript.PayToAddrScript(t.balanceAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	msgTx, spent, err := cpfp(msgTxParent, int64(mempoolEntry.VSize), parentFee, changeScript, feeRate, t.dustLimit)
+	if err != nil {
+		return nil, err
+	}
+	if t.rbf == true {
+		msgTx.TxIn[0].Sequence = DEF_sequenceRBF
+	}
+	return t.sign(ctx, msgTx, []*utxo{spent})
+}
+
+func cpfp(msgTxParent *wire.MsgTx, parentVSize int64, parentFee btcutil.Amount, changeScript []byte, feeRate FeeRate, dustLimit btcutil.Amount) (msgTx *wire.MsgTx, spent *utxo, err error) {
+	changeIdx := -1
+	for i, txOut := range msgTxParent.TxOut {
+		if bytes.Equal(txOut.PkScript, changeScript) == true {
+			changeIdx = i
+			break
+		}
+	}
+	if changeIdx == -1 {
+		return nil, nil, fmt.Errorf("no change output in parent tx | %s", msgTxParent.TxHash())
+	}
+	changeValue := btcutil.Amount(msgTxParent.TxOut[changeIdx].Value)
+
+	estimator := &TxSizeEstimator{}
+	err = estimator.AddInput(changeScript, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	estimator.AddOutput(changeScript)
+
+	// child pays what parent lacks, at least its own fee
+	childFee := feeRate.Fee(parentVSize+estimator.VSize()) - parentFee
+	if feeMin := estimator.Fee(feeRate); childFee < feeMin {
+		childFee = feeMin
+	}
+	value := changeValue - childFee
+	if value < dustLimit {
+		return nil, nil, fmt.Errorf("not enough change to pay for parent | change : %v | fee : %v", changeValue, childFee)
+	}
+
+	parentHash := msgTxParent.TxHash()
+	msgTx = wire.NewMsgTx(wire.TxVersion)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&parentHash, uint32(changeIdx)), nil, nil))
+	msgTx.AddTxOut(wire.NewTxOut(int64(value), changeScript))
+
+	spent = &utxo{
+		Txid:         parentHash.String(),
+		Vout:         uint32(changeIdx),
+		FromAmount:   changeValue.ToBTC(),
+		ScriptPubKey: hex.EncodeToString(changeScript),
+	}
+	return msgTx, spent, nil
+}
@@ -0,0 +1,135 @@
+package btc
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestP2WPKHScript(t *testing.T, seed byte) []byte {
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(newTestKey(t, seed, true).SerializePubKey()), &chaincfg.RegressionNetParams)
+	require.NoError(t, err)
+	pkScript, err := txscript.PayToAddrScript(addr)
+	require.NoError(t, err)
+	return pkScript
+}
+
+// 1 P2WPKH input ( 100000 ) -> to ( 60000 ) + change ( 39000 ), fee 1000
+func newTestStuckTx(t *testing.T, toScript, changeScript []byte, sequence uint32) (msgTx *wire.MsgTx, utxos []*utxo) {
+	hash, err := chainhash.NewHashFromStr(DEF_txid_dummy)
+	require.NoError(t, err)
+	msgTx = wire.NewMsgTx(wire.TxVersion)
+	txIn := wire.NewTxIn(wire.NewOutPoint(hash, 0), nil, nil)
+	txIn.Sequence = sequence
+	msgTx.AddTxIn(txIn)
+	msgTx.AddTxOut(wire.NewTxOut(60000, toScript))
+	msgTx.AddTxOut(wire.NewTxOut(39000, changeScript))
+	return msgTx, []*utxo{{Txid: DEF_txid_dummy, FromAmount: 0.001, ScriptPubKey: hex.EncodeToString(changeScript)}}
+}
+
+func TestBumpFee(t *testing.T) {
+	toScript, changeScript := newTestP2WPKHScript(t, 1), newTestP2WPKHScript(t, 2)
+
+	// not replaceable
+	msgTxOrig, utxos := newTestStuckTx(t, toScript, changeScript, wire.MaxTxInSequenceNum)
+	require.False(t, IsRBF(msgTxOrig))
+	_, err := bumpFee(msgTxOrig, utxos, changeScript, 20, DEF_dustLimit)
+	require.Error(t, err)
+
+	msgTxOrig, utxos = newTestStuckTx(t, toScript, changeScript, DEF_sequenceRBF)
+	require.True(t, IsRBF(msgTxOrig))
+	msgTx, err := bumpFee(msgTxOrig, utxos, changeScript, 20, DEF_dustLimit)
+	require.NoError(t, err)
+
+	// same input, to amount kept, fee taken from change
+	require.Len(t, msgTx.TxIn, 1)
+	require.Equal(t, msgTxOrig.TxIn[0].PreviousOutPoint, msgTx.TxIn[0].PreviousOutPoint)
+	require.Equal(t, int64(60000), msgTx.TxOut[0].Value)
+	estimator := &TxSizeEstimator{}
+	require.NoError(t, estimator.AddInput(changeScript, nil, nil))
+	estimator.AddOutput(toScript)
+	estimator.AddOutput(changeScript)
+	require.Equal(t, int64(40000)-int64(estimator.Fee(20)), msgTx.TxOut[1].Value)
+
+	// low fee rate still pays original fee + incremental relay fee
+	msgTx, err = bumpFee(msgTxOrig, utxos, changeScript, 1, DEF_dustLimit)
+	require.NoError(t, err)
+	require.Equal(t, int64(39000)-int64(estimator.Fee(1)), msgTx.TxOut[1].Value)
+
+	// change under dust limit is dropped
+	msgTx, err = bumpFee(msgTxOrig, utxos, changeScript, 280, DEF_dustLimit)
+	require.NoError(t, err)
+	require.Len(t, msgTx.TxOut, 1)
+
+	// no change output
+	_, err = bumpFee(msgTxOrig, utxos, newTestP2WPKHScript(t, 3), 20, DEF_dustLimit)
+	require.Error(t, err)
+}
+
+func TestRawTxBumpFeeTimeLock(t *testing.T) {
+	wif := newTestKey(t, 4, true)
+	timeLock, err := NewTimeLock(&chaincfg.RegressionNetParams, DEF_multiSig_P2SH, DEF_timeLock_CLTV, 100, hex.EncodeToString(wif.SerializePubKey()))
+	require.NoError(t, err)
+	lockScript, err := timeLock.PkScript()
+	require.NoError(t, err)
+	toScript, changeScript := newTestP2WPKHScript(t, 1), newTestP2WPKHScript(t, 2)
+
+	// stuck tx spending P2SH CLTV output
+	msgTxOrig, _ := newTestStuckTx(t, toScript, changeScript, DEF_sequenceRBF)
+	require.NoError(t, timeLock.Apply(msgTxOrig, 0))
+	var buf bytes.Buffer
+	require.NoError(t, msgTxOrig.Serialize(&buf))
+
+	client, _ := newTestRPCServer(t, map[string]string{
+		"getrawtransaction": `"` + hex.EncodeToString(buf.Bytes()) + `"`,
+		"gettxout":          `{"bestblock":"` + DEF_txid_dummy + `","confirmations":10,"value":0.001,"scriptPubKey":{"hex":"` + hex.EncodeToString(lockScript) + `"},"coinbase":false}`,
+	})
+	changeAddr, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(newTestKey(t, 2, true).SerializePubKey()), client.params)
+	require.NoError(t, err)
+	rawTx := &RawTx{}
+	require.NoError(t, rawTx.Init(client, changeAddr.EncodeAddress(), 0))
+	require.NoError(t, rawTx.AddFromTimeLock(timeLock, wif.String()))
+
+	msgTxSigned, err := rawTx.BumpFee(context.Background(), DEF_txid_dummy, 20)
+	require.NoError(t, err)
+	require.Equal(t, msgTxOrig.LockTime, msgTxSigned.LockTime)
+	require.Less(t, msgTxSigned.TxOut[1].Value, int64(39000))
+
+	// signed input spends time lock, fee covers its size
+	prevOut := wire.NewTxOut(100000, lockScript)
+	fetcher := txscript.NewCannedPrevOutputFetcher(prevOut.PkScript, prevOut.Value)
+	engine, err := txscript.NewEngine(prevOut.PkScript, msgTxSigned, 0, txscript.StandardVerifyFlags, nil, txscript.NewTxSigHashes(msgTxSigned, fetcher), prevOut.Value, fetcher)
+	require.NoError(t, err)
+	require.NoError(t, engine.Execute())
+	_, vsize := getRawTxSize(msgTxSigned)
+	require.GreaterOrEqual(t, 100000-60000-msgTxSigned.TxOut[1].Value, int64(FeeRate(20).Fee(int64(vsize))))
+}
+
+func TestCPFP(t *testing.T) {
+	toScript, changeScript := newTestP2WPKHScript(t, 1), newTestP2WPKHScript(t, 2)
+	msgTxParent, _ := newTestStuckTx(t, toScript, changeScript, wire.MaxTxInSequenceNum)
+
+	// parent 141 vB paying 1000 sat, package at 20 sat/vB
+	msgTx, spent, err := cpfp(msgTxParent, 141, 1000, changeScript, 20, DEF_dustLimit)
+	require.NoError(t, err)
+	require.Equal(t, msgTxParent.TxHash(), msgTx.TxIn[0].PreviousOutPoint.Hash)
+	require.Equal(t, uint32(1), spent.Vout)
+
+	estimator := &TxSizeEstimator{}
+	require.NoError(t, estimator.AddInput(changeScript, nil, nil))
+	estimator.AddOutput(changeScript)
+	childFee := FeeRate(20).Fee(141+estimator.VSize()) - 1000
+	require.Equal(t, int64(39000)-int64(childFee), msgTx.TxOut[0].Value)
+
+	// not enough change
+	_, _, err = cpfp(msgTxParent, 141, 1000, changeScript, 500, DEF_dustLimit)
+	require.Error(t, err)
+}
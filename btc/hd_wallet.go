@@ -0,0 +1,245 @@
+package btc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// Purpose is BIP43 purpose of derivation path, it decides address type
+type Purpose uint32
+
+const (
+	DEF_purpose_BIP44 Purpose = 44 // P2PKH
+	DEF_purpose_BIP49 Purpose = 49 // P2SH-P2WPKH
+	DEF_purpose_BIP84 Purpose = 84 // P2WPKH
+	DEF_purpose_BIP86 Purpose = 86 // P2TR
+)
+
+// HDWallet derives deposit addresses of one account ( m / purpose' / coin_type' / account' / change / index )
+// private keys never leave this process, node can watch addresses by importdescriptors
+type HDWallet struct {
+	params  *chaincfg.Params
+	purpose Purpose
+	account *hdkeychain.ExtendedKey
+
+	// key origin of descriptor, known only if created from master key
+	hasOrigin    bool
+	fingerprint  uint32
+	accountIndex uint32
+}
+
+// NewHDWallet creates wallet from extended key ( xprv / tprv of master, or xpub / xprv of account level )
+// account is used only for master key
+func NewHDWallet(params *chaincfg.Params, purpose Purpose, extKey string, account uint32) (wallet *HDWallet, err error) {
+	key, err := hdkeychain.NewKeyFromString(extKey)
+	if err != nil {
+		return nil, err
+	}
+	if key.IsForNet(params) == false {
+		return nil, fmt.Errorf("extended key is not for network | %s", params.Name)
+	}
+	return newHDWallet(params, purpose, key, account)
+}
+
+// NewHDWalletFromSeed creates wallet from BIP39 seed
+func NewHDWalletFromSeed(params *chaincfg.Params, purpose Purpose, seed []byte, account uint32) (wallet *HDWallet, err error) {
+	master, err := hdkeychain.NewMaster(seed, params)
+	if err != nil {
+		return nil, err
+	}
+	return newHDWallet(params, purpose, master, account)
+}
+
+func newHDWallet(params *chaincfg.Params, purpose Purpose, key *hdkeychain.ExtendedKey, account uint32) (wallet *HDWallet, err error) {
+	switch purpose {
+	case DEF_purpose_BIP44, DEF_purpose_BIP49, DEF_purpose_BIP84, DEF_purpose_BIP86:
+	default:
+		return nil, fmt.Errorf("unsupported purpose | %d", purpose)
+	}
+	wallet = &HDWallet{params: params, purpose: purpose}
+
+	switch key.Depth() {
+	case 0:
+		// master -> account
+		if key.IsPrivate() == false {
+			return nil, fmt.Errorf("hardened derivation needs private master key")
+		}
+		pubKey, err := key.ECPubKey()
+		if err != nil {
+			return nil, err
+		}
+		path := []uint32{uint32(purpose), params.HDCoinType, account}
+		for _, idx := range path {
+			key, err = key.Derive(hdkeychain.HardenedKeyStart + idx)
+			if err != nil {
+				return nil, err
+			}
+		}
+		wallet.hasOrigin = true
+		wallet.fingerprint = binary.BigEndian.Uint32(btcutil.Hash160(pubKey.SerializeCompressed())[:4])
+		wallet.accountIndex = account
+	case 3:
+		// account level key
+	default:
+		return nil, fmt.Errorf("extended key must be master or account level | depth : %d", key.Depth())
+	}
+	wallet.account = key
+	return wallet, nil
+}
+
+// AccountKey returns extended public key of account ( xpub / tpub )
+func (t *HDWallet) AccountKey() (xpub string, err error) {
+	pub, err := t.account.Neuter()
+	if err != nil {
+		return "", err
+	}
+	return pub.String(), nil
+}
+
+// MasterFingerprint returns fingerprint of master key ( 0 if unknown )
+func (t *HDWallet) MasterFingerprint() uint32 {
+	return t.fingerprint
+}
+
+// Address returns address of account / change / index
+func (t *HDWallet) Address(change bool, index uint32) (address string, err error) {
+	key, err := t.derive(change, index)
+	if err != nil {
+		return "", err
+	}
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return "", err
+	}
+	pubKeyHash := btcutil.Hash160(pubKey.SerializeCompressed())
+
+	var addr btcutil.Address
+	switch t.purpose {
+	case DEF_purpose_BIP44:
+		addr, err = btcutil.NewAddressPubKeyHash(pubKeyHash, t.params)
+	case DEF_purpose_BIP49:
+		addr, err = btcutil.NewAddressScriptHash(p2wpkhScript(pubKeyHash), t.params)
+	case DEF_purpose_BIP84:
+		addr, err = btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, t.params)
+	case DEF_purpose_BIP86:
+		addr, err = btcutil.NewAddressTaproot(taprootOutputKey(pubKey), t.params)
+	}
+	if err != nil {
+		return "", err
+	}
+	return EncodeAddress(addr, t.params), nil
+}
+
+// KeyOrigin returns BIP32 origin of key of account / change / index, written to PSBT for hardware wallets
+// wallet must be created from master key
+func (t *HDWallet) KeyOrigin(change bool, index uint32) (origin *KeyOrigin, err error) {
+	if t.hasOrigin == false {
+		return nil, fmt.Errorf("key origin is unknown, wallet is not created from master key")
+	}
+	key, err := t.derive(change, index)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return nil, err
+	}
+	return &KeyOrigin{
+		PubKey:      pubKey.SerializeCompressed(),
+		Fingerprint: bits.ReverseBytes32(t.fingerprint), // PSBT keeps fingerprint bytes in little endian
+		Path: []uint32{
+			hdkeychain.HardenedKeyStart + uint32(t.purpose),
+			hdkeychain.HardenedKeyStart + t.params.HDCoinType,
+			hdkeychain.HardenedKeyStart + t.accountIndex,
+			changeIndex(change),
+			index,
+		},
+		Taproot: t.purpose == DEF_purpose_BIP86,
+	}, nil
+}
+
+// PrivKey returns wif of account / change / index ( account key must be private )
+func (t *HDWallet) PrivKey(change bool, index uint32) (wif string, err error) {
+	if t.account.IsPrivate() == false {
+		return "", fmt.Errorf("wallet has no private key")
+	}
+	key, err := t.derive(change, index)
+	if err != nil {
+		return "", err
+	}
+	privKey, err := key.ECPrivKey()
+	if err != nil {
+		return "", err
+	}
+	btcWif, err := btcutil.NewWIF(privKey, t.params, true)
+	if err != nil {
+		return "", err
+	}
+	return btcWif.String(), nil
+}
+
+func (t *HDWallet) derive(change bool, index uint32) (key *hdkeychain.ExtendedKey, err error) {
+	if index >= hdkeychain.HardenedKeyStart {
+		return nil, fmt.Errorf("index is out of range | %d", index)
+	}
+	key, err = t.account.Derive(changeIndex(change))
+	if err != nil {
+		return nil, err
+	}
+	return key.Derive(index)
+}
+
+func changeIndex(change bool) uint32 {
+	if change == true {
+		return 1
+	}
+	return 0
+}
+
+//--------------------------------------------------------------------------------//
+// descriptor
+
+// Descriptor returns ranged descriptor of receive / change addresses with checksum ( public keys only )
+// ex : wpkh([d34db33f/84'/0'/0']xpub.../0/*)#checksum
+func (t *HDWallet) Descriptor(change bool) (desc string, err error) {
+	xpub, err := t.AccountKey()
+	if err != nil {
+		return "", err
+	}
+	key := fmt.Sprintf("%s/%d/*", xpub, changeIndex(change))
+	if t.hasOrigin == true {
+		key = fmt.Sprintf("[%08x/%d'/%d'/%d']", t.fingerprint, t.purpose, t.params.HDCoinType, t.accountIndex) + key
+	}
+
+	switch t.purpose {
+	case DEF_purpose_BIP44:
+		desc = "pkh(" + key + ")"
+	case DEF_purpose_BIP49:
+		desc = "sh(wpkh(" + key + "))"
+	case DEF_purpose_BIP84:
+		desc = "wpkh(" + key + ")"
+	case DEF_purpose_BIP86:
+		desc = "tr(" + key + ")"
+	}
+	return AddDescriptorChecksum(desc)
+}
+
+// ImportDescriptorRequest returns request to watch addresses of index [ 0, end ] on node
+// timestamp is unix time of first use or "now"
+func (t *HDWallet) ImportDescriptorRequest(change bool, end int, timestamp interface{}) (request *ImportDescriptorRequest, err error) {
+	desc, err := t.Descriptor(change)
+	if err != nil {
+		return nil, err
+	}
+	return &ImportDescriptorRequest{
+		Desc:      desc,
+		Range:     []int{0, end},
+		Timestamp: timestamp,
+		Internal:  change,
+	}, nil
+}
@@ -0,0 +1,84 @@
+package btc
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/require"
+)
+
+// seed of mnemonic "abandon abandon ... about" ( BIP39 test vector, empty passphrase )
+const DEF_seed_test = "5eb00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc19a5ac40b389cd370d086206dec8aa6c43daea6690f20ad3d8d48b2d2ce9e38e4"
+
+func TestHDWalletAddress(t *testing.T) {
+	seed, err := hex.DecodeString(DEF_seed_test)
+	require.NoError(t, err)
+
+	// first receive address of BIP44 / 49 / 84 / 86 test vectors
+	for _, test := range []struct {
+		purpose Purpose
+		address string
+	}{
+		{DEF_purpose_BIP44, "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA"},
+		{DEF_purpose_BIP49, "37VucYSaXLCAsxYyAPfbSi9eh4iEcbShgf"},
+		{DEF_purpose_BIP84, "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"},
+		{DEF_purpose_BIP86, "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr"},
+	} {
+		wallet, err := NewHDWalletFromSeed(&chaincfg.MainNetParams, test.purpose, seed, 0)
+		require.NoError(t, err)
+		address, err := wallet.Address(false, 0)
+		require.NoError(t, err)
+		require.Equal(t, test.address, address)
+
+		// watch only wallet of account xpub derives same address
+		xpub, err := wallet.AccountKey()
+		require.NoError(t, err)
+		watchOnly, err := NewHDWallet(&chaincfg.MainNetParams, test.purpose, xpub, 0)
+		require.NoError(t, err)
+		address, err = watchOnly.Address(false, 0)
+		require.NoError(t, err)
+		require.Equal(t, test.address, address)
+		_, err = watchOnly.PrivKey(false, 0)
+		require.Error(t, err)
+	}
+}
+
+func TestHDWalletDescriptor(t *testing.T) {
+	seed, err := hex.DecodeString(DEF_seed_test)
+	require.NoError(t, err)
+	wallet, err := NewHDWalletFromSeed(&chaincfg.MainNetParams, DEF_purpose_BIP84, seed, 0)
+	require.NoError(t, err)
+	require.Equal(t, uint32(0x73c5da0a), wallet.MasterFingerprint())
+
+	desc, err := wallet.Descriptor(true)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(desc, "wpkh([73c5da0a/84'/0'/0']xpub"))
+	require.Contains(t, desc, "/1/*)#")
+
+	// private key matches address
+	wif, err := wallet.PrivKey(false, 0)
+	require.NoError(t, err)
+	btcWif, err := btcutil.DecodeWIF(wif)
+	require.NoError(t, err)
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(btcWif.SerializePubKey()), &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	require.Equal(t, "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", addr.EncodeAddress())
+
+	// import request is marshaled as array of objects
+	request, err := wallet.ImportDescriptorRequest(false, 999, "now")
+	require.NoError(t, err)
+	marshaled, err := btcjson.MarshalCmd(btcjson.RpcVersion1, 1, NewImportDescriptorsCmd([]*ImportDescriptorRequest{request}))
+	require.NoError(t, err)
+	require.Contains(t, string(marshaled), `"params":[[{"desc":"wpkh([73c5da0a/84'/0'/0']xpub`)
+	require.Contains(t, string(marshaled), `"range":[0,999],"timestamp":"now"}]]`)
+
+	// invalid
+	_, err = NewHDWalletFromSeed(&chaincfg.MainNetParams, 0, seed, 0)
+	require.Error(t, err)
+	_, err = NewHDWallet(&chaincfg.TestNet3Params, DEF_purpose_BIP84, "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8", 0)
+	require.Error(t, err)
+}
@@ -0,0 +1,275 @@
+package btc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	DEF_messageMagic   = "Bitcoin Signed Message:\n" // litecoin, dogecoin use their own ( messageMagic )
+	DEF_bip322Tag      = "BIP0322-signed-message"
+	DEF_sizeCompactSig = 65
+
+	// BIP137 header = base + recovery id ( 0 ~ 3 )
+	DEF_bip137Header_P2PKH_Uncompressed = 27
+	DEF_bip137Header_P2PKH              = 31
+	DEF_bip137Header_P2SH_P2WPKH        = 35
+	DEF_bip137Header_P2WPKH             = 39
+)
+
+// SignMessage signs message with private key of address
+// P2PKH, P2SH-P2WPKH are signed by BIP137 ( compact ECDSA ), P2WPKH, P2TR by BIP322 simple signature
+func SignMessage(privKey, address, message string, params *chaincfg.Params) (signature string, err error) {
+	addr, err := decodeAddress(address, params)
+	if err != nil {
+		return "", err
+	}
+	switch addr.(type) {
+	case *btcutil.AddressPubKeyHash, *btcutil.AddressScriptHash:
+		return SignMessageBIP137(privKey, address, message, params)
+	default:
+		return SignMessageBIP322(privKey, address, message, params)
+	}
+}
+
+// VerifyMessage verifies BIP137 or BIP322 simple signature of address
+// ok is false if signature does not match, err is returned for malformed input
+func VerifyMessage(address, signature, message string, params *chaincfg.Params) (ok bool, err error) {
+	addr, err := decodeAddress(address, params)
+	if err != nil {
+		return false, err
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false, fmt.Errorf("invalid signature encoding | %w", err)
+	}
+	if len(sig) == DEF_sizeCompactSig && sig[0] >= DEF_bip137Header_P2PKH_Uncompressed && sig[0] < DEF_bip137Header_P2WPKH+4 {
+		return verifyMessageBIP137(addr, sig, message, params)
+	}
+	return verifyMessageBIP322(addr, sig, message)
+}
+
+//--------------------------------------------------------------------------------//
+// BIP137
+
+// SignMessageBIP137 signs message as signmessage of bitcoin core, header of signature tells address type
+func SignMessageBIP137(privKey, address, message string, params *chaincfg.Params) (signature string, err error) {
+	addr, err := decodeAddress(address, params)
+	if err != nil {
+		return "", err
+	}
+	wif, err := decodeWIFOfAddress(privKey, addr, params)
+	if err != nil {
+		return "", err
+	}
+
+	var header byte
+	switch addr.(type) {
+	case *btcutil.AddressPubKeyHash:
+		header = DEF_bip137Header_P2PKH_Uncompressed
+		if wif.CompressPubKey == true {
+			header = DEF_bip137Header_P2PKH
+		}
+	case *btcutil.AddressScriptHash:
+		header = DEF_bip137Header_P2SH_P2WPKH
+	case *btcutil.AddressWitnessPubKeyHash:
+		header = DEF_bip137Header_P2WPKH
+	default:
+		return "", fmt.Errorf("unsupported address type for BIP137 | %s", address)
+	}
+
+	sig, err := ecdsa.SignCompact(wif.PrivKey, messageHash(message, params), wif.CompressPubKey)
+	if err != nil {
+		return "", err
+	}
+	recoveryID := (sig[0] - DEF_bip137Header_P2PKH_Uncompressed) & 0x03
+	sig[0] = header + recoveryID
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// verifyMessageBIP137 recovers public key and compares hash of address
+// header type is not forced to match address type ( segwit signatures of old wallets use P2PKH header )
+func verifyMessageBIP137(addr btcutil.Address, sig []byte, message string, params *chaincfg.Params) (ok bool, err error) {
+	header := sig[0] - DEF_bip137Header_P2PKH_Uncompressed
+	compressed := header >= DEF_bip137Header_P2PKH-DEF_bip137Header_P2PKH_Uncompressed
+
+	compact := make([]byte, DEF_sizeCompactSig)
+	copy(compact, sig)
+	compact[0] = DEF_bip137Header_P2PKH_Uncompressed + header&0x03
+	if compressed == true {
+		compact[0] += 4
+	}
+	pubKey, _, err := ecdsa.RecoverCompact(compact, messageHash(message, params))
+	if err != nil {
+		return false, nil
+	}
+
+	var pubKeyHash []byte
+	if compressed == true {
+		pubKeyHash = btcutil.Hash160(pubKey.SerializeCompressed())
+	} else {
+		pubKeyHash = btcutil.Hash160(pubKey.SerializeUncompressed())
+	}
+	switch addr.(type) {
+	case *btcutil.AddressPubKeyHash:
+		return bytes.Equal(addr.ScriptAddress(), pubKeyHash), nil
+	case *btcutil.AddressWitnessPubKeyHash:
+		return compressed == true && bytes.Equal(addr.ScriptAddress(), pubKeyHash), nil
+	case *btcutil.AddressScriptHash:
+		return compressed == true && bytes.Equal(addr.ScriptAddress(), btcutil.Hash160(p2wpkhScript(pubKeyHash))), nil
+	default:
+		return false, fmt.Errorf("unsupported address type for BIP137 | %s", addr)
+	}
+}
+
+// messageHash is double sha256 of magic of network and message with var int length prefix
+func messageHash(message string, params *chaincfg.Params) []byte {
+	var buf bytes.Buffer
+	wire.WriteVarString(&buf, 0, messageMagic(params))
+	wire.WriteVarString(&buf, 0, message)
+	return chainhash.DoubleHashB(buf.Bytes())
+}
+
+//--------------------------------------------------------------------------------//
+// BIP322
+
+// SignMessageBIP322 signs message with BIP322 simple signature ( witness of virtual to_sign tx )
+// P2WPKH and P2TR ( key path ) are supported
+func SignMessageBIP322(privKey, address, message string, params *chaincfg.Params) (signature string, err error) {
+	addr, err := decodeAddress(address, params)
+	if err != nil {
+		return "", err
+	}
+	wif, err := decodeWIFOfAddress(privKey, addr, params)
+	if err != nil {
+		return "", err
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return "", err
+	}
+
+	toSign, fetcher := bip322ToSign(pkScript, message)
+	sigHashes := txscript.NewTxSigHashes(toSign, fetcher)
+	switch addr.(type) {
+	case *btcutil.AddressWitnessPubKeyHash:
+		toSign.TxIn[0].Witness, err = txscript.WitnessSignature(toSign, sigHashes, 0, 0, pkScript, txscript.SigHashAll, wif.PrivKey, true)
+	case *btcutil.AddressTaproot:
+		toSign.TxIn[0].Witness, err = txscript.TaprootWitnessSignature(toSign, sigHashes, 0, 0, pkScript, txscript.SigHashDefault, wif.PrivKey)
+	default:
+		return "", fmt.Errorf("unsupported address type for BIP322 | %s", address)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	err = writeWitness(&buf, toSign.TxIn[0].Witness)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// verifyMessageBIP322 executes script of address with witness of signature
+func verifyMessageBIP322(addr btcutil.Address, sig []byte, message string) (ok bool, err error) {
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return false, err
+	}
+	witness, err := readWitness(bytes.NewReader(sig))
+	if err != nil {
+		return false, fmt.Errorf("invalid BIP322 signature | %w", err)
+	}
+
+	toSign, fetcher := bip322ToSign(pkScript, message)
+	toSign.TxIn[0].Witness = witness
+	engine, err := txscript.NewEngine(pkScript, toSign, 0, txscript.StandardVerifyFlags, nil, txscript.NewTxSigHashes(toSign, fetcher), 0, fetcher)
+	if err != nil {
+		return false, nil
+	}
+	return engine.Execute() == nil, nil
+}
+
+// bip322ToSign returns unsigned to_sign tx spending to_spend tx which commits to message
+func bip322ToSign(pkScript []byte, message string) (toSign *wire.MsgTx, fetcher *txscript.CannedPrevOutputFetcher) {
+	hash := chainhash.TaggedHash([]byte(DEF_bip322Tag), []byte(message))
+	scriptSig, _ := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(hash[:]).Script()
+
+	toSpend := wire.NewMsgTx(0)
+	txIn := wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, wire.MaxPrevOutIndex), scriptSig, nil)
+	txIn.Sequence = 0
+	toSpend.AddTxIn(txIn)
+	toSpend.AddTxOut(wire.NewTxOut(0, pkScript))
+
+	toSpendHash := toSpend.TxHash()
+	toSign = wire.NewMsgTx(0)
+	txIn = wire.NewTxIn(wire.NewOutPoint(&toSpendHash, 0), nil, nil)
+	txIn.Sequence = 0
+	toSign.AddTxIn(txIn)
+	toSign.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_RETURN}))
+	return toSign, txscript.NewCannedPrevOutputFetcher(pkScript, 0)
+}
+
+func writeWitness(buf *bytes.Buffer, witness wire.TxWitness) (err error) {
+	err = wire.WriteVarInt(buf, 0, uint64(len(witness)))
+	if err != nil {
+		return err
+	}
+	for _, item := range witness {
+		err = wire.WriteVarBytes(buf, 0, item)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readWitness(r *bytes.Reader) (witness wire.TxWitness, err error) {
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count > uint64(r.Len()) {
+		return nil, fmt.Errorf("invalid witness item count | %d", count)
+	}
+	witness = make(wire.TxWitness, 0, count)
+	for i := uint64(0); i < count; i++ {
+		item, err := wire.ReadVarBytes(r, 0, uint32(r.Len()), "witness item")
+		if err != nil {
+			return nil, err
+		}
+		witness = append(witness, item)
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("trailing bytes after witness | %d", r.Len())
+	}
+	return witness, nil
+}
+
+// decodeWIFOfAddress decodes private key and checks it owns address
+func decodeWIFOfAddress(privKey string, addr btcutil.Address, params *chaincfg.Params) (wif *btcutil.WIF, err error) {
+	keys := newKeyStore()
+	err = keys.add(privKey, params)
+	if err != nil {
+		return nil, err
+	}
+	switch addr := addr.(type) {
+	case *btcutil.AddressPubKeyHash, *btcutil.AddressWitnessPubKeyHash:
+		return keys.get(addr.ScriptAddress(), false)
+	case *btcutil.AddressScriptHash:
+		return keys.get(addr.ScriptAddress(), true)
+	case *btcutil.AddressTaproot:
+		return keys.getTaproot(addr.ScriptAddress())
+	default:
+		return nil, fmt.Errorf("unsupported address type | %s", addr)
+	}
+}
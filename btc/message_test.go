@@ -0,0 +1,161 @@
+package btc
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageBIP322(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	// BIP322 test vector
+	privKey := "L3VFeEujGtevx9w18HD1fhRbCH67Az2dpCymeRE1SoPK6XQtaN2k"
+	addressP2WPKH := "bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l"
+	addressP2TR := "bc1ppv609nr0vr25u07u95waq5lucwfm6tde4nydujnu8npg4q75mr5sxq8lt3"
+
+	hash := chainhash.TaggedHash([]byte(DEF_bip322Tag), []byte(""))
+	require.Equal(t, "c90c269c4f8fcbe6880f72a721ddfbf1914268a794cbb21cfafee13770ae19f1", hex.EncodeToString(hash[:]))
+
+	for _, test := range []struct {
+		address   string
+		message   string
+		signature string
+	}{
+		{addressP2WPKH, "", "AkcwRAIgM2gBAQqvZX15ZiysmKmQpDrG83avLIT492QBzLnQIxYCIBaTpOaD20qRlEylyxFSeEA2ba9YOixpX8z46TSDtS40ASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI="},
+		{addressP2WPKH, "Hello World", "AkcwRAIgZRfIY3p7/DoVTty6YZbWS71bc5Vct9p9Fia83eRmw2QCICK/ENGfwLtptFluMGs2KsqoNSk89pO7F29zJLUx9a/sASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI="},
+		{addressP2TR, "Hello World", "AUHd69PrJQEv+oKTfZ8l+WROBHuy9HKrbFCJu7U1iK2iiEy1vMU5EfMtjc+VSHM7aU0SDbak5IUZRVno2P5mjSafAQ=="},
+	} {
+		ok, err := VerifyMessage(test.address, test.signature, test.message, params)
+		require.NoError(t, err)
+		require.True(t, ok, test.address)
+		ok, err = VerifyMessage(test.address, test.signature, test.message+"!", params)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		signature, err := SignMessage(privKey, test.address, test.message, params)
+		require.NoError(t, err)
+		ok, err = VerifyMessage(test.address, signature, test.message, params)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	// wrong key
+	_, err := SignMessage(newTestKey(t, 1, true).String(), addressP2WPKH, "", params)
+	require.Error(t, err)
+	// malformed
+	_, err = VerifyMessage(addressP2WPKH, "!!", "", params)
+	require.Error(t, err)
+	_, err = VerifyMessage(addressP2WPKH, "AkcwRAIg", "", params)
+	require.Error(t, err)
+}
+
+func TestMessageBIP137(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	for _, compress := range []bool{true, false} {
+		wif := newTestKey(t, 1, compress)
+		addr, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(wif.SerializePubKey()), params)
+		require.NoError(t, err)
+
+		signature, err := SignMessage(wif.String(), addr.EncodeAddress(), "proof of ownership", params)
+		require.NoError(t, err)
+		ok, err := VerifyMessage(addr.EncodeAddress(), signature, "proof of ownership", params)
+		require.NoError(t, err)
+		require.True(t, ok)
+		ok, err = VerifyMessage(addr.EncodeAddress(), signature, "other message", params)
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+
+	// segwit headers
+	wif := newTestKey(t, 2, true)
+	pubKeyHash := btcutil.Hash160(wif.SerializePubKey())
+	addrP2WPKH, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, params)
+	require.NoError(t, err)
+	addrP2SH, err := btcutil.NewAddressScriptHash(p2wpkhScript(pubKeyHash), params)
+	require.NoError(t, err)
+	addrP2PKH, err := btcutil.NewAddressPubKeyHash(pubKeyHash, params)
+	require.NoError(t, err)
+
+	signature, err := SignMessage(wif.String(), addrP2SH.EncodeAddress(), "hello", params)
+	require.NoError(t, err)
+	ok, err := VerifyMessage(addrP2SH.EncodeAddress(), signature, "hello", params)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	signature, err = SignMessageBIP137(wif.String(), addrP2WPKH.EncodeAddress(), "hello", params)
+	require.NoError(t, err)
+	ok, err = VerifyMessage(addrP2WPKH.EncodeAddress(), signature, "hello", params)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// P2PKH header is accepted for segwit address of the same key
+	signature, err = SignMessageBIP137(wif.String(), addrP2PKH.EncodeAddress(), "hello", params)
+	require.NoError(t, err)
+	ok, err = VerifyMessage(addrP2WPKH.EncodeAddress(), signature, "hello", params)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestMessageMagic(t *testing.T) {
+	for _, test := range []struct {
+		params *chaincfg.Params
+		magic  string
+	}{
+		{&chaincfg.MainNetParams, "Bitcoin Signed Message:\n"},
+		{&BCHMainNetParams, "Bitcoin Signed Message:\n"},
+		{&LTCMainNetParams, "Litecoin Signed Message:\n"},
+		{&LTCTestNetParams, "Litecoin Signed Message:\n"},
+		{&DOGEMainNetParams, "Dogecoin Signed Message:\n"},
+		{&DOGETestNetParams, "Dogecoin Signed Message:\n"},
+	} {
+		require.Equal(t, test.magic, messageMagic(test.params), test.params.Name)
+	}
+
+	// signature of litecoin is not valid on bitcoin and vice versa
+	wif, err := btcutil.NewWIF(newTestKey(t, 1, true).PrivKey, &LTCMainNetParams, true)
+	require.NoError(t, err)
+	pubKeyHash := btcutil.Hash160(wif.SerializePubKey())
+	addrLTC, err := btcutil.NewAddressPubKeyHash(pubKeyHash, &LTCMainNetParams)
+	require.NoError(t, err)
+	addrBTC, err := btcutil.NewAddressPubKeyHash(pubKeyHash, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+
+	signature, err := SignMessage(wif.String(), addrLTC.EncodeAddress(), "hello", &LTCMainNetParams)
+	require.NoError(t, err)
+	ok, err := VerifyMessage(addrLTC.EncodeAddress(), signature, "hello", &LTCMainNetParams)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = VerifyMessage(addrBTC.EncodeAddress(), signature, "hello", &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestClientMessage(t *testing.T) {
+	client, requests := newTestRPCServer(t, map[string]string{
+		"signmessage":            `"c2lnbmF0dXJl"`,
+		"signmessagewithprivkey": `"c2lnbmF0dXJl"`,
+		"verifymessage":          `true`,
+	})
+	ctx := context.Background()
+	address := "mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r"
+
+	signature, err := client.SignMessage(ctx, address, "hello")
+	require.NoError(t, err)
+	require.Equal(t, "c2lnbmF0dXJl", signature)
+	_, err = client.SignMessageWithPrivKey(ctx, "cPrivKey", "hello")
+	require.NoError(t, err)
+	ok, err := client.VerifyMessage(ctx, address, signature, "hello")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.Equal(t, []string{
+		`/ signmessage ["` + address + `","hello"]`,
+		`/ signmessagewithprivkey ["cPrivKey","hello"]`,
+		`/ verifymessage ["` + address + `","c2lnbmF0dXJl","hello"]`,
+	}, *requests)
+}
@@ -1,40 +1,109 @@
 package btc
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/wire"
 )
 
 //---------------------------------------------------------------------------//
 // wallet
 
-func (t *Client) UnlockPassphrase(passphrase string, timeoutSec int64) (err error) {
+func (t *Client) UnlockPassphrase(ctx context.Context, passphrase string, timeoutSec int64) (err error) {
 	if timeoutSec <= 0 {
 		timeoutSec = 60 // default sec
 	}
-	return t.rpc.WalletPassphrase(passphrase, timeoutSec)
+	return waitErr(ctx, t.timeout, func() error { return t.rpc.WalletPassphrase(passphrase, timeoutSec) })
 }
 
-func (t *Client) ImportPrivKey(privKey string) (err error) {
+// LockWallet removes decryption key from memory ( walletlock )
+func (t *Client) LockWallet(ctx context.Context) (err error) {
+	return waitErr(ctx, t.timeout, t.rpc.WalletLockAsync().Receive)
+}
+
+// EncryptWallet encrypts wallet with passphrase, wallet is locked after encryption
+func (t *Client) EncryptWallet(ctx context.Context, passphrase string) (err error) {
+	var message string
+	return t.sendCmd(ctx, btcjson.NewEncryptWalletCmd(passphrase), &message)
+}
+
+// CreateWalletOptions are optional flags of createwallet ( nil is default )
+// descriptor wallet is created by default since bitcoin core 23
+type CreateWalletOptions struct {
+	DisablePrivateKeys bool // watch only
+	Blank              bool // no keys or hd seed
+	Passphrase         string
+	AvoidReuse         bool
+}
+
+func (t *Client) CreateWallet(ctx context.Context, name string, opts *CreateWalletOptions) (result *btcjson.CreateWalletResult, err error) {
+	var createOpts []rpcclient.CreateWalletOpt
+	if opts != nil {
+		if opts.DisablePrivateKeys == true {
+			createOpts = append(createOpts, rpcclient.WithCreateWalletDisablePrivateKeys())
+		}
+		if opts.Blank == true {
+			createOpts = append(createOpts, rpcclient.WithCreateWalletBlank())
+		}
+		if opts.Passphrase != "" {
+			createOpts = append(createOpts, rpcclient.WithCreateWalletPassphrase(opts.Passphrase))
+		}
+		if opts.AvoidReuse == true {
+			createOpts = append(createOpts, rpcclient.WithCreateWalletAvoidReuse())
+		}
+	}
+	return wait(ctx, t.timeout, t.rpc.CreateWalletAsync(name, createOpts...).Receive)
+}
+
+func (t *Client) LoadWallet(ctx context.Context, name string) (result *btcjson.LoadWalletResult, err error) {
+	return wait(ctx, t.timeout, t.rpc.LoadWalletAsync(name).Receive)
+}
+
+// UnloadWallet unloads wallet of name, empty name unloads wallet of this client ( Wallet )
+func (t *Client) UnloadWallet(ctx context.Context, name string) (err error) {
+	var walletName *string
+	if name != "" {
+		walletName = &name
+	}
+	return waitErr(ctx, t.timeout, t.rpc.UnloadWalletAsync(walletName).Receive)
+}
+
+// ListWallets returns names of loaded wallets
+func (t *Client) ListWallets(ctx context.Context) (names []string, err error) {
+	err = t.sendCmd(ctx, &ListWalletsCmd{}, &names)
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// BackupWallet copies wallet file to destination ( path on node host )
+func (t *Client) BackupWallet(ctx context.Context, destination string) (err error) {
+	return waitErr(ctx, t.timeout, t.rpc.BackupWalletAsync(destination).Receive)
+}
+
+func (t *Client) ImportPrivKey(ctx context.Context, privKey string) (err error) {
 	wif, err := btcutil.DecodeWIF(privKey)
 	if err != nil {
 		return err
 	}
-	return t.rpc.ImportPrivKey(wif)
+	return waitErr(ctx, t.timeout, t.rpc.ImportPrivKeyAsync(wif).Receive)
 }
 
-func (t *Client) GetBalanceTotal() (balance btcutil.Amount, err error) {
-	return t.GetBalance("*")
+func (t *Client) GetBalanceTotal(ctx context.Context) (balance btcutil.Amount, err error) {
+	return t.GetBalance(ctx, "*")
 }
 
-func (t *Client) GetBalance(address string) (balance btcutil.Amount, err error) {
-	return t.rpc.GetBalance(address)
+func (t *Client) GetBalance(ctx context.Context, address string) (balance btcutil.Amount, err error) {
+	return wait(ctx, t.timeout, t.rpc.GetBalanceAsync(address).Receive)
 }
 
-func (t *Client) GetListUnspent(minBlock, maxBlock int, addresses ...btcutil.Address) (unspents []btcjson.ListUnspentResult, err error) {
+func (t *Client) GetListUnspent(ctx context.Context, minBlock, maxBlock int, addresses ...btcutil.Address) (unspents []btcjson.ListUnspentResult, err error) {
 	if minBlock <= 0 {
 		minBlock = 1
 	}
@@ -43,130 +112,221 @@ func (t *Client) GetListUnspent(minBlock, maxBlock int, addresses ...btcutil.Add
 	}
 
 	if len(addresses) == 0 { // get all unspent
-		return t.rpc.ListUnspentMinMax(minBlock, maxBlock)
+		return wait(ctx, t.timeout, t.rpc.ListUnspentMinMaxAsync(minBlock, maxBlock).Receive)
 	} else {
-		return t.rpc.ListUnspentMinMaxAddresses(minBlock, maxBlock, addresses)
+		return wait(ctx, t.timeout, t.rpc.ListUnspentMinMaxAddressesAsync(minBlock, maxBlock, addresses).Receive)
 	}
 }
 
 //---------------------------------------------------------------------------//
 // address
 
-func (t *Client) GetNewAddress() (privkey, address string, err error) {
-	btcAddr, err := t.rpc.GetNewAddress("")
+// GetNewAddress returns new address of node wallet with its private key ( legacy wallet only, use HDWallet for descriptor wallet )
+func (t *Client) GetNewAddress(ctx context.Context) (privkey, address string, err error) {
+	address, err = t.GetNewAddressWithLabel(ctx, "")
 	if err != nil {
 		return "", "", err
 	}
-	btcPrivKey, err := t.rpc.DumpPrivKey(btcAddr)
+	privkey, err = t.DumpPrivKey(ctx, address)
 	if err != nil {
 		return "", "", err
 	}
+	return privkey, address, nil
+}
 
-	return btcPrivKey.String(), btcAddr.EncodeAddress(), nil
+// GetNewAddressWithLabel returns new address labeled on node ( ex : customer id ) to attribute deposits
+// private key stays in node wallet, works with descriptor wallet
+func (t *Client) GetNewAddressWithLabel(ctx context.Context, label string) (address string, err error) {
+	// rpcclient decodes result by btcutil, CashAddr of bitcoin cash node is decoded here
+	err = t.sendCmd(ctx, btcjson.NewGetNewAddressCmd(&label, nil), &address)
+	if err != nil {
+		return "", err
+	}
+	btcAddr, err := decodeAddress(address, t.params)
+	if err != nil {
+		return "", err
+	}
+	return EncodeAddress(btcAddr, t.params), nil
 }
 
-func (t *Client) GetAddressInfo(address string) (addrInfo *btcjson.GetAddressInfoResult, err error) {
-	return t.rpc.GetAddressInfo(address)
+func (t *Client) GetAddressInfo(ctx context.Context, address string) (addrInfo *btcjson.GetAddressInfoResult, err error) {
+	return wait(ctx, t.timeout, t.rpc.GetAddressInfoAsync(address).Receive)
 }
 
-func (t *Client) ValidateAddress(address string) (validateAddr *btcjson.ValidateAddressWalletResult, err error) {
-	btcAddr, err := btcutil.DecodeAddress(address, t.params)
+func (t *Client) ValidateAddress(ctx context.Context, address string) (validateAddr *btcjson.ValidateAddressWalletResult, err error) {
+	btcAddr, err := decodeAddress(address, t.params)
 	if err != nil {
 		return nil, err
 	}
-	return t.rpc.ValidateAddress(btcAddr)
+	return wait(ctx, t.timeout, t.rpc.ValidateAddressAsync(btcAddr).Receive)
 }
 
-func (t *Client) DumpPrivKey(address string) (privKey string, err error) {
-	btcAddr, err := btcutil.DecodeAddress(address, t.params)
+func (t *Client) DumpPrivKey(ctx context.Context, address string) (privKey string, err error) {
+	btcAddr, err := decodeAddress(address, t.params)
 	if err != nil {
 		return "", err
 	}
-	wif, err := t.rpc.DumpPrivKey(btcAddr)
+	wif, err := wait(ctx, t.timeout, t.rpc.DumpPrivKeyAsync(btcAddr).Receive)
 	if err != nil {
 		return "", err
 	}
 	return wif.String(), nil
 }
 
+//---------------------------------------------------------------------------//
+// message
+
+// SignMessage signs message with key of address in wallet ( BIP137, legacy address only on bitcoin core )
+func (t *Client) SignMessage(ctx context.Context, address, message string) (signature string, err error) {
+	btcAddr, err := decodeAddress(address, t.params)
+	if err != nil {
+		return "", err
+	}
+	return wait(ctx, t.timeout, t.rpc.SignMessageAsync(btcAddr, message).Receive)
+}
+
+// SignMessageWithPrivKey signs message on node without wallet ( BIP137, P2PKH header )
+func (t *Client) SignMessageWithPrivKey(ctx context.Context, privKey, message string) (signature string, err error) {
+	err = t.sendCmd(ctx, btcjson.NewSignMessageWithPrivKey(privKey, message), &signature)
+	return signature, err
+}
+
+// VerifyMessage verifies BIP137 signature on node, use VerifyMessage of package for BIP322
+func (t *Client) VerifyMessage(ctx context.Context, address, signature, message string) (ok bool, err error) {
+	btcAddr, err := decodeAddress(address, t.params)
+	if err != nil {
+		return false, err
+	}
+	return wait(ctx, t.timeout, t.rpc.VerifyMessageAsync(btcAddr, signature, message).Receive)
+}
+
 //---------------------------------------------------------------------------//
 // tx
 
-func (t *Client) GetTxInfo(txid string) (txInfo *btcjson.GetTransactionResult, err error) {
+func (t *Client) GetTxInfo(ctx context.Context, txid string) (txInfo *btcjson.GetTransactionResult, err error) {
 	hash, err := chainhash.NewHashFromStr(txid)
 	if err != nil {
 		return nil, err
 	}
-	txInfo, err = t.rpc.GetTransaction(hash)
+	txInfo, err = wait(ctx, t.timeout, t.rpc.GetTransactionAsync(hash).Receive)
 	if err != nil {
 		return nil, err
 	}
 	return txInfo, nil
 }
 
-func (t *Client) GetRawTxInfo(txid string) (rawTxInfo *btcjson.TxRawResult, err error) {
+func (t *Client) GetRawTxInfo(ctx context.Context, txid string) (rawTxInfo *btcjson.TxRawResult, err error) {
 	hash, err := chainhash.NewHashFromStr(txid)
 	if err != nil {
 		return nil, err
 	}
-	rawTxInfo, err = t.rpc.GetRawTransactionVerbose(hash)
+	rawTxInfo, err = wait(ctx, t.timeout, t.rpc.GetRawTransactionVerboseAsync(hash).Receive)
 	if err != nil {
 		return nil, err
 	}
 	return rawTxInfo, nil
 }
 
+func (t *Client) GetRawTx(ctx context.Context, txid string) (msgTx *wire.MsgTx, err error) {
+	hash, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := wait(ctx, t.timeout, t.rpc.GetRawTransactionAsync(hash).Receive)
+	if err != nil {
+		return nil, err
+	}
+	return tx.MsgTx(), nil
+}
+
+// GetTxOut returns unspent output ( nil if spent or not found )
+func (t *Client) GetTxOut(ctx context.Context, txid string, vout uint32, includeMempool bool) (txOut *btcjson.GetTxOutResult, err error) {
+	hash, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		return nil, err
+	}
+	return wait(ctx, t.timeout, t.rpc.GetTxOutAsync(hash, vout, includeMempool).Receive)
+}
+
+func (t *Client) GetMempoolEntry(ctx context.Context, txid string) (mempoolEntry *btcjson.GetMempoolEntryResult, err error) {
+	return wait(ctx, t.timeout, t.rpc.GetMempoolEntryAsync(txid).Receive)
+}
+
+func (t *Client) GetRawMempool(ctx context.Context) (txids []string, err error) {
+	hashes, err := wait(ctx, t.timeout, t.rpc.GetRawMempoolAsync().Receive)
+	if err != nil {
+		return nil, err
+	}
+	txids = make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		txids = append(txids, hash.String())
+	}
+	return txids, nil
+}
+
 //---------------------------------------------------------------------------//
 // block
 
-func (t *Client) GetBestBlock() (blockHash string, err error) {
-	btcBlockHash, err := t.rpc.GetBestBlockHash()
+func (t *Client) GetBestBlock(ctx context.Context) (blockHash string, err error) {
+	btcBlockHash, err := wait(ctx, t.timeout, t.rpc.GetBestBlockHashAsync().Receive)
 	if err != nil {
 		return "", err
 	}
 	return btcBlockHash.String(), nil
 }
 
-func (t *Client) GetBlockHash(blockNumber int64) (blockHash string, err error) {
-	btcBlockHash, err := t.rpc.GetBlockHash(blockNumber)
+func (t *Client) GetBlockCount(ctx context.Context) (blockNumber int64, err error) {
+	return wait(ctx, t.timeout, t.rpc.GetBlockCountAsync().Receive)
+}
+
+func (t *Client) GetBlockHash(ctx context.Context, blockNumber int64) (blockHash string, err error) {
+	btcBlockHash, err := wait(ctx, t.timeout, t.rpc.GetBlockHashAsync(blockNumber).Receive)
 	if err != nil {
 		return "", err
 	}
 	return btcBlockHash.String(), nil
 }
 
-func (t *Client) GetBlockInfo(blockHash string) (blockInfo *btcjson.GetBlockVerboseResult, err error) {
+func (t *Client) GetBlockInfo(ctx context.Context, blockHash string) (blockInfo *btcjson.GetBlockVerboseResult, err error) {
+	btcBlockHash, err := chainhash.NewHashFromStr(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	return wait(ctx, t.timeout, t.rpc.GetBlockVerboseAsync(btcBlockHash).Receive)
+}
+
+// GetBlock returns decoded raw block
+func (t *Client) GetBlock(ctx context.Context, blockHash string) (msgBlock *wire.MsgBlock, err error) {
 	btcBlockHash, err := chainhash.NewHashFromStr(blockHash)
 	if err != nil {
 		return nil, err
 	}
-	return t.rpc.GetBlockVerbose(btcBlockHash)
+	return wait(ctx, t.timeout, t.rpc.GetBlockAsync(btcBlockHash).Receive)
 }
 
-func (t *Client) GetBlockInfoWithTx(blockHash string) (blockInfo *btcjson.GetBlockVerboseTxResult, err error) {
+func (t *Client) GetBlockInfoWithTx(ctx context.Context, blockHash string) (blockInfo *btcjson.GetBlockVerboseTxResult, err error) {
 	btcBlockHash, err := chainhash.NewHashFromStr(blockHash)
 	if err != nil {
 		return nil, err
 	}
-	return t.rpc.GetBlockVerboseTx(btcBlockHash)
+	return wait(ctx, t.timeout, t.rpc.GetBlockVerboseTxAsync(btcBlockHash).Receive)
 }
 
 //---------------------------------------------------------------------------//
 // fee
 
-func (t *Client) GetSmartFee(confTargetBlock int64, feeEstimateMode *btcjson.EstimateSmartFeeMode) (smartFee btcutil.Amount, err error) {
+func (t *Client) GetSmartFee(ctx context.Context, confTargetBlock int64, feeEstimateMode *btcjson.EstimateSmartFeeMode) (smartFee btcutil.Amount, err error) {
 	if confTargetBlock <= 0 {
 		confTargetBlock = 10
 	}
 	if feeEstimateMode == nil {
 		feeEstimateMode = &btcjson.EstimateModeUnset
 	}
-	result, err := t.rpc.EstimateSmartFee(confTargetBlock, feeEstimateMode)
+	result, err := wait(ctx, t.timeout, t.rpc.EstimateSmartFeeAsync(confTargetBlock, feeEstimateMode).Receive)
 	if err != nil {
 		return 0, err
-	} else if len(result.Errors) != 0 {
-		err = fmt.Errorf("%v", result.Errors)
-		return 0, err
+	} else if len(result.Errors) != 0 || result.FeeRate == nil {
+		return 0, fmt.Errorf("%w | %v", ErrFeeEstimateFailed, result.Errors)
 	}
 	smartFee, err = btcutil.NewAmount(*result.FeeRate)
 	if err != nil {
@@ -175,36 +335,45 @@ func (t *Client) GetSmartFee(confTargetBlock int64, feeEstimateMode *btcjson.Est
 	return smartFee, nil
 }
 
-func (t *Client) SetFee(fee btcutil.Amount) (err error) {
-	return t.rpc.SetTxFee(fee)
+// GetSmartFeeRate returns estimated fee rate in sat/vB
+func (t *Client) GetSmartFeeRate(ctx context.Context, confTargetBlock int64, feeEstimateMode *btcjson.EstimateSmartFeeMode) (feeRate FeeRate, err error) {
+	smartFee, err := t.GetSmartFee(ctx, confTargetBlock, feeEstimateMode)
+	if err != nil {
+		return 0, err
+	}
+	return NewFeeRateFromBTCPerKB(smartFee), nil
+}
+
+func (t *Client) SetFee(ctx context.Context, fee btcutil.Amount) (err error) {
+	return waitErr(ctx, t.timeout, t.rpc.SetTxFeeAsync(fee).Receive)
 }
 
 //---------------------------------------------------------------------------//
 // transfer
 
-func (t *Client) SendCoin(addrTo string, amount btcutil.Amount) (txid string, err error) {
-	to, err := btcutil.DecodeAddress(addrTo, t.params)
+func (t *Client) SendCoin(ctx context.Context, addrTo string, amount btcutil.Amount) (txid string, err error) {
+	to, err := decodeAddress(addrTo, t.params)
 	if err != nil {
 		return "", err
 	}
 
-	chainHash, err := t.rpc.SendToAddress(to, amount)
+	chainHash, err := wait(ctx, t.timeout, t.rpc.SendToAddressAsync(to, amount).Receive)
 	if err != nil {
 		return "", err
 	}
 	return chainHash.String(), nil
 }
 
-func (t *Client) SendCoinMany(mapAmounts map[string]btcutil.Amount) (txid string, err error) {
+func (t *Client) SendCoinMany(ctx context.Context, mapAmounts map[string]btcutil.Amount) (txid string, err error) {
 	mapAddrAmounts := make(map[btcutil.Address]btcutil.Amount)
 	for address, amount := range mapAmounts {
-		btcAddr, err := btcutil.DecodeAddress(address, t.params)
+		btcAddr, err := decodeAddress(address, t.params)
 		if err != nil {
 			return "", err
 		}
 		mapAddrAmounts[btcAddr] = amount
 	}
-	chainHash, err := t.rpc.SendMany("", mapAddrAmounts)
+	chainHash, err := wait(ctx, t.timeout, t.rpc.SendManyAsync("", mapAddrAmounts).Receive)
 	if err != nil {
 		return "", err
 	}
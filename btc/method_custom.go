@@ -2,6 +2,7 @@ package btc
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -12,9 +13,9 @@ import (
 	"github.com/btcsuite/btcd/wire"
 )
 
-func (t *Client) sendCmd(req interface{}, res interface{}) (err error) {
+func (t *Client) sendCmd(ctx context.Context, req interface{}, res interface{}) (err error) {
 	chanRes := t.rpc.SendCmd(req)
-	bt, err := rpcclient.ReceiveFuture(chanRes)
+	bt, err := wait(ctx, t.timeout, func() ([]byte, error) { return rpcclient.ReceiveFuture(chanRes) })
 	if err != nil {
 		return err
 	}
@@ -25,17 +26,17 @@ func (t *Client) sendCmd(req interface{}, res interface{}) (err error) {
 	return nil
 }
 
-func (t *Client) ScanTxOutSet(addresses ...btcutil.Address) (result *ScanTxOutSetResult, err error) {
+func (t *Client) ScanTxOutSet(ctx context.Context, addresses ...btcutil.Address) (result *ScanTxOutSetResult, err error) {
 	cmd := NewScanTxOutSetCmd("start", addresses)
 	result = &ScanTxOutSetResult{}
-	err = t.sendCmd(cmd, result)
+	err = t.sendCmd(ctx, cmd, result)
 	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
-func (t *Client) SignRawTransactionWithKey(tx *wire.MsgTx, inputs []RawTxInput, privKeys []string) (txSigned *wire.MsgTx, err error) {
+func (t *Client) SignRawTransactionWithKey(ctx context.Context, tx *wire.MsgTx, inputs []RawTxInput, privKeys []string) (txSigned *wire.MsgTx, err error) {
 	var txid string
 	if tx != nil {
 		// Serialize the transaction and convert to hex string.
@@ -48,7 +49,7 @@ func (t *Client) SignRawTransactionWithKey(tx *wire.MsgTx, inputs []RawTxInput,
 
 	cmd := NewSignRawTransactionCmd(txid, &inputs, &privKeys, nil)
 	result := &SignRawTransactionResult{}
-	err = t.sendCmd(cmd, result)
+	err = t.sendCmd(ctx, cmd, result)
 	if err != nil {
 		return nil, err
 	}
@@ -68,6 +69,115 @@ func (t *Client) SignRawTransactionWithKey(tx *wire.MsgTx, inputs []RawTxInput,
 	return &msgTx, nil
 }
 
+// WalletProcessPSBT fills utxo / scripts known to node wallet and signs inputs of wallet keys if sign is true
+func (t *Client) WalletProcessPSBT(ctx context.Context, psbtB64 string, sign bool) (result *btcjson.WalletProcessPsbtResult, err error) {
+	cmd := btcjson.NewWalletProcessPsbtCmd(psbtB64, &sign, nil, nil)
+	result = &btcjson.WalletProcessPsbtResult{}
+	err = t.sendCmd(ctx, cmd, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// FinalizePSBT finalizes inputs of psbt, hex of signed tx is set if extract is true and psbt is complete
+func (t *Client) FinalizePSBT(ctx context.Context, psbtB64 string, extract bool) (result *FinalizePSBTResult, err error) {
+	cmd := NewFinalizePSBTCmd(psbtB64, &extract)
+	result = &FinalizePSBTResult{}
+	err = t.sendCmd(ctx, cmd, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ImportDescriptors lets descriptor wallet of node watch descriptors ( result per request )
+func (t *Client) ImportDescriptors(ctx context.Context, requests ...*ImportDescriptorRequest) (results []ImportDescriptorResult, err error) {
+	cmd := NewImportDescriptorsCmd(requests)
+	err = t.sendCmd(ctx, cmd, &results)
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		if result.Success == true {
+			continue
+		}
+		if result.Error != nil {
+			return results, fmt.Errorf("import descriptor %d failed | %w", i, classifyError(result.Error))
+		}
+		return results, fmt.Errorf("import descriptor %d failed | warnings : %v", i, result.Warnings)
+	}
+	return results, nil
+}
+
+// SetLabel sets label of address in wallet ( imported or watch only address )
+func (t *Client) SetLabel(ctx context.Context, address, label string) (err error) {
+	var res interface{}
+	return t.sendCmd(ctx, NewSetLabelCmd(address, label), &res)
+}
+
+// GetAddressesByLabel returns addresses of label ( key : address )
+func (t *Client) GetAddressesByLabel(ctx context.Context, label string) (addresses map[string]AddressByLabelResult, err error) {
+	err = t.sendCmd(ctx, NewGetAddressesByLabelCmd(label), &addresses)
+	if err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+// ListLabels returns labels of wallet, purpose is "send" or "receive" ( empty is all )
+func (t *Client) ListLabels(ctx context.Context, purpose string) (labels []string, err error) {
+	var purposeOpt *string
+	if purpose != "" {
+		purposeOpt = &purpose
+	}
+	err = t.sendCmd(ctx, NewListLabelsCmd(purposeOpt), &labels)
+	if err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// GetReceivedByLabel returns total amount received by addresses of label with at least minConf confirmations
+func (t *Client) GetReceivedByLabel(ctx context.Context, label string, minConf int) (amount btcutil.Amount, err error) {
+	var received float64
+	err = t.sendCmd(ctx, NewGetReceivedByLabelCmd(label, &minConf), &received)
+	if err != nil {
+		return 0, err
+	}
+	return btcutil.NewAmount(received)
+}
+
+// ListReceivedByAddress returns amount received per address of wallet with label and txids
+func (t *Client) ListReceivedByAddress(ctx context.Context, minConf int, includeEmpty, includeWatchOnly bool) (results []ListReceivedByAddressResult, err error) {
+	cmd := btcjson.NewListReceivedByAddressCmd(&minConf, &includeEmpty, &includeWatchOnly)
+	err = t.sendCmd(ctx, cmd, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// TestMempoolAccept checks if txs would be accepted to mempool without broadcasting ( result per tx )
+// maxFeeRate is btc/kvB, nil uses default of node ( 0.10 )
+func (t *Client) TestMempoolAccept(ctx context.Context, maxFeeRate *float64, txs ...*wire.MsgTx) (results []TestMempoolAcceptResult, err error) {
+	rawTxs := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
+		if err := tx.Serialize(buf); err != nil {
+			return nil, err
+		}
+		rawTxs = append(rawTxs, hex.EncodeToString(buf.Bytes()))
+	}
+
+	cmd := NewTestMempoolAcceptCmd(rawTxs, maxFeeRate)
+	err = t.sendCmd(ctx, cmd, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
 //---------------------------------------------------------------------------------//
 // custom cmd struct
 
@@ -75,6 +185,16 @@ func init() {
 	// 커스텀 cmd 등록
 	btcjson.MustRegisterCmd("scantxoutset", (*ScanTxOutSetCmd)(nil), btcjson.UFWalletOnly)
 	btcjson.MustRegisterCmd("signrawtransactionwithkey", (*SignRawTransactionCmd)(nil), btcjson.UFWalletOnly)
+	btcjson.MustRegisterCmd("finalizepsbt", (*FinalizePSBTCmd)(nil), 0)
+	btcjson.MustRegisterCmd("importdescriptors", (*ImportDescriptorsCmd)(nil), btcjson.UFWalletOnly)
+	btcjson.MustRegisterCmd("testmempoolaccept", (*TestMempoolAcceptCmd)(nil), 0)
+	btcjson.MustRegisterCmd("listwallets", (*ListWalletsCmd)(nil), btcjson.UFWalletOnly)
+	btcjson.MustRegisterCmd("setlabel", (*SetLabelCmd)(nil), btcjson.UFWalletOnly)
+	btcjson.MustRegisterCmd("getaddressesbylabel", (*GetAddressesByLabelCmd)(nil), btcjson.UFWalletOnly)
+	btcjson.MustRegisterCmd("listlabels", (*ListLabelsCmd)(nil), btcjson.UFWalletOnly)
+	btcjson.MustRegisterCmd("getreceivedbylabel", (*GetReceivedByLabelCmd)(nil), btcjson.UFWalletOnly)
+	// listreceivedbyaddress is registered by btcjson already, only result type is custom
+	// walletprocesspsbt is registered by btcjson already
 }
 
 type ScanTxOutSetCmd struct {
@@ -116,11 +236,12 @@ type UnSpents struct {
 // RawTxInput models the data needed for raw transaction input that is used in
 // the SignRawTransactionCmd struct.
 type RawTxInput struct {
-	Txid         string  `json:"txid"`
-	Vout         uint32  `json:"vout"`
-	ScriptPubKey string  `json:"scriptPubKey"`
-	RedeemScript string  `json:"redeemScript"`
-	Amount       float64 `json:"amount"`
+	Txid          string  `json:"txid"`
+	Vout          uint32  `json:"vout"`
+	ScriptPubKey  string  `json:"scriptPubKey"`
+	RedeemScript  string  `json:"redeemScript"`
+	WitnessScript string  `json:"witnessScript,omitempty"`
+	Amount        float64 `json:"amount"`
 }
 
 // SignRawTransactionCmd defines the signrawtransaction JSON-RPC command.
@@ -162,3 +283,151 @@ type SignRawTransactionResult struct {
 	Complete bool                      `json:"complete"`
 	Errors   []SignRawTransactionError `json:"errors,omitempty"`
 }
+
+// FinalizePSBTCmd defines the finalizepsbt JSON-RPC command.
+type FinalizePSBTCmd struct {
+	Psbt    string
+	Extract *bool `jsonrpcdefault:"true"`
+}
+
+func NewFinalizePSBTCmd(psbt string, extract *bool) *FinalizePSBTCmd {
+	return &FinalizePSBTCmd{
+		Psbt:    psbt,
+		Extract: extract,
+	}
+}
+
+// FinalizePSBTResult models the data from the finalizepsbt command.
+// Psbt is set if not extracted, Hex is set if extracted
+type FinalizePSBTResult struct {
+	Psbt     string `json:"psbt,omitempty"`
+	Hex      string `json:"hex,omitempty"`
+	Complete bool   `json:"complete"`
+}
+
+// ImportDescriptorsCmd defines the importdescriptors JSON-RPC command.
+type ImportDescriptorsCmd struct {
+	Requests []*ImportDescriptorRequest
+}
+
+func NewImportDescriptorsCmd(requests []*ImportDescriptorRequest) *ImportDescriptorsCmd {
+	return &ImportDescriptorsCmd{
+		Requests: requests,
+	}
+}
+
+// ImportDescriptorRequest is descriptor to import
+// Timestamp is unix time to rescan from or "now", Range is [ begin, end ] of ranged descriptor
+type ImportDescriptorRequest struct {
+	Desc      string      `json:"desc"`
+	Active    bool        `json:"active,omitempty"`
+	Range     []int       `json:"range,omitempty"`
+	NextIndex int         `json:"next_index,omitempty"`
+	Timestamp interface{} `json:"timestamp"`
+	Internal  bool        `json:"internal,omitempty"`
+	Label     string      `json:"label,omitempty"`
+}
+
+type ImportDescriptorResult struct {
+	Success  bool              `json:"success"`
+	Warnings []string          `json:"warnings,omitempty"`
+	Error    *btcjson.RPCError `json:"error,omitempty"`
+}
+
+// ListWalletsCmd defines the listwallets JSON-RPC command.
+type ListWalletsCmd struct{}
+
+// SetLabelCmd defines the setlabel JSON-RPC command.
+type SetLabelCmd struct {
+	Address string
+	Label   string
+}
+
+func NewSetLabelCmd(address, label string) *SetLabelCmd {
+	return &SetLabelCmd{
+		Address: address,
+		Label:   label,
+	}
+}
+
+// GetAddressesByLabelCmd defines the getaddressesbylabel JSON-RPC command.
+type GetAddressesByLabelCmd struct {
+	Label string
+}
+
+func NewGetAddressesByLabelCmd(label string) *GetAddressesByLabelCmd {
+	return &GetAddressesByLabelCmd{
+		Label: label,
+	}
+}
+
+// AddressByLabelResult models the data from the getaddressesbylabel command.
+type AddressByLabelResult struct {
+	Purpose string `json:"purpose"` // send, receive
+}
+
+// ListLabelsCmd defines the listlabels JSON-RPC command.
+type ListLabelsCmd struct {
+	Purpose *string
+}
+
+func NewListLabelsCmd(purpose *string) *ListLabelsCmd {
+	return &ListLabelsCmd{
+		Purpose: purpose,
+	}
+}
+
+// GetReceivedByLabelCmd defines the getreceivedbylabel JSON-RPC command.
+type GetReceivedByLabelCmd struct {
+	Label   string
+	MinConf *int `jsonrpcdefault:"1"`
+}
+
+func NewGetReceivedByLabelCmd(label string, minConf *int) *GetReceivedByLabelCmd {
+	return &GetReceivedByLabelCmd{
+		Label:   label,
+		MinConf: minConf,
+	}
+}
+
+// ListReceivedByAddressResult models the data from the listreceivedbyaddress command.
+// btcjson result has account instead of label
+type ListReceivedByAddressResult struct {
+	InvolvesWatchOnly bool     `json:"involvesWatchonly,omitempty"`
+	Address           string   `json:"address"`
+	Amount            float64  `json:"amount"`
+	Confirmations     int64    `json:"confirmations"`
+	Label             string   `json:"label"`
+	Txids             []string `json:"txids"`
+}
+
+// TestMempoolAcceptCmd defines the testmempoolaccept JSON-RPC command.
+type TestMempoolAcceptCmd struct {
+	RawTxs     []string
+	MaxFeeRate *float64 `jsonrpcdefault:"0.10"`
+}
+
+func NewTestMempoolAcceptCmd(rawTxs []string, maxFeeRate *float64) *TestMempoolAcceptCmd {
+	return &TestMempoolAcceptCmd{
+		RawTxs:     rawTxs,
+		MaxFeeRate: maxFeeRate,
+	}
+}
+
+// TestMempoolAcceptResult models the data from the testmempoolaccept command.
+// VSize and Fees are set only if allowed
+type TestMempoolAcceptResult struct {
+	Txid         string                 `json:"txid"`
+	Wtxid        string                 `json:"wtxid"`
+	PackageError string                 `json:"package-error,omitempty"`
+	Allowed      bool                   `json:"allowed"`
+	VSize        int64                  `json:"vsize,omitempty"`
+	Fees         *TestMempoolAcceptFees `json:"fees,omitempty"`
+	RejectReason string                 `json:"reject-reason,omitempty"`
+}
+
+type TestMempoolAcceptFees struct {
+	Base              float64  `json:"base"`
+	EffectiveFeeRate  float64  `json:"effective-feerate,omitempty"` // btc/kvB
+	EffectiveIncludes []string `json:"effective-includes,omitempty"`
+}
@@ -0,0 +1,77 @@
+package btc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabels(t *testing.T) {
+	client, requests := newTestRPCServer(t, map[string]string{
+		"getaddressesbylabel":   `{"bcrt1qxyz":{"purpose":"receive"}}`,
+		"listlabels":            `["", "customer-1"]`,
+		"getreceivedbylabel":    `0.015`,
+		"listreceivedbyaddress": `[{"address":"bcrt1qxyz","amount":0.015,"confirmations":3,"label":"customer-1","txids":["aa","bb"]}]`,
+		"getnewaddress":         `"bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080"`,
+	})
+	ctx := context.Background()
+
+	// private key is not dumped ( descriptor wallet )
+	address, err := client.GetNewAddressWithLabel(ctx, "customer-1")
+	require.NoError(t, err)
+	require.Equal(t, "bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080", address)
+
+	require.NoError(t, client.SetLabel(ctx, "bcrt1qxyz", "customer-1"))
+	addresses, err := client.GetAddressesByLabel(ctx, "customer-1")
+	require.NoError(t, err)
+	require.Equal(t, "receive", addresses["bcrt1qxyz"].Purpose)
+
+	labels, err := client.ListLabels(ctx, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"", "customer-1"}, labels)
+	_, err = client.ListLabels(ctx, "receive")
+	require.NoError(t, err)
+
+	received, err := client.GetReceivedByLabel(ctx, "customer-1", 6)
+	require.NoError(t, err)
+	require.Equal(t, btcutil.Amount(1500000), received)
+
+	results, err := client.ListReceivedByAddress(ctx, 1, false, true)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "customer-1", results[0].Label)
+	require.Equal(t, []string{"aa", "bb"}, results[0].Txids)
+
+	require.Equal(t, []string{
+		`/ getnewaddress ["customer-1"]`,
+		`/ setlabel ["bcrt1qxyz","customer-1"]`,
+		`/ getaddressesbylabel ["customer-1"]`,
+		`/ listlabels []`,
+		`/ listlabels ["receive"]`,
+		`/ getreceivedbylabel ["customer-1",6]`,
+		`/ listreceivedbyaddress [1,false,true]`,
+	}, *requests)
+}
+
+func TestImportDescriptors(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		result string
+		err    string
+	}{
+		{`[{"success":true},{"success":true,"warnings":["range is ignored"]}]`, ""},
+		{`[{"success":true},{"success":false,"warnings":["rescan is aborted"]}]`, "import descriptor 1 failed | warnings : [rescan is aborted]"},
+		{`[{"success":false,"error":{"code":-5,"message":"invalid descriptor"}}]`, "invalid descriptor"},
+	} {
+		client, _ := newTestRPCServer(t, map[string]string{"importdescriptors": test.result})
+		results, err := client.ImportDescriptors(ctx, &ImportDescriptorRequest{})
+		if test.err == "" {
+			require.NoError(t, err)
+			require.Len(t, results, 2)
+			continue
+		}
+		require.ErrorContains(t, err, test.err)
+	}
+}
@@ -0,0 +1,211 @@
+package btc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+type MultiSigType string
+
+const (
+	DEF_multiSig_P2SH       MultiSigType = "p2sh"
+	DEF_multiSig_P2SH_P2WSH MultiSigType = "p2sh-p2wsh"
+	DEF_multiSig_P2WSH      MultiSigType = "p2wsh"
+)
+
+// MultiSig is m-of-n multisig address
+type MultiSig struct {
+	Type     MultiSigType
+	Required int
+	PubKeys  [][]byte // order in script
+	Sorted   bool     // BIP67 sorted keys ( sortedmulti )
+
+	Script        []byte // OP_m <pubkeys> OP_n OP_CHECKMULTISIG
+	RedeemScript  []byte // P2SH, P2SH-P2WSH
+	WitnessScript []byte // P2SH-P2WSH, P2WSH
+	Address       btcutil.Address
+}
+
+// NewMultiSig creates m-of-n multisig address from hex public keys
+// sorted uses BIP67 key order, so address does not depend on order of pubKeys
+func NewMultiSig(params *chaincfg.Params, multiSigType MultiSigType, required int, pubKeys []string, sorted bool) (multiSig *MultiSig, err error) {
+	if required <= 0 || required > len(pubKeys) || len(pubKeys) > txscript.MaxPubKeysPerMultiSig {
+		return nil, fmt.Errorf("invalid multisig | required : %d | keys : %d", required, len(pubKeys))
+	}
+	multiSig = &MultiSig{
+		Type:     multiSigType,
+		Required: required,
+		PubKeys:  make([][]byte, 0, len(pubKeys)),
+		Sorted:   sorted,
+	}
+
+	addrPubKeys := make([]*btcutil.AddressPubKey, 0, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		pubKeyBytes, err := hex.DecodeString(pubKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key | %s | %w", pubKey, err)
+		}
+		addrPubKey, err := btcutil.NewAddressPubKey(pubKeyBytes, params)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key | %s | %w", pubKey, err)
+		}
+		if multiSigType != DEF_multiSig_P2SH && addrPubKey.Format() != btcutil.PKFCompressed {
+			return nil, fmt.Errorf("segwit multisig needs compressed public key | %s", pubKey)
+		}
+		addrPubKeys = append(addrPubKeys, addrPubKey)
+	}
+	if sorted == true {
+		sort.SliceStable(addrPubKeys, func(i, j int) bool {
+			return bytes.Compare(addrPubKeys[i].ScriptAddress(), addrPubKeys[j].ScriptAddress()) < 0
+		})
+	}
+	for _, addrPubKey := range addrPubKeys {
+		multiSig.PubKeys = append(multiSig.PubKeys, addrPubKey.ScriptAddress())
+	}
+
+	multiSig.Script, err = txscript.MultiSigScript(addrPubKeys, required)
+	if err != nil {
+		return nil, err
+	}
+
+	multiSig.RedeemScript, multiSig.WitnessScript, multiSig.Address, err = payToScript(params, multiSigType, multiSig.Script)
+	if err != nil {
+		return nil, err
+	}
+	return multiSig, nil
+}
+
+// payToScript returns redeem / witness script and address paying to script by script hash type
+func payToScript(params *chaincfg.Params, scriptType MultiSigType, script []byte) (redeemScript, witnessScript []byte, addr btcutil.Address, err error) {
+	switch scriptType {
+	case DEF_multiSig_P2SH:
+		addr, err = btcutil.NewAddressScriptHash(script, params)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return script, nil, addr, nil
+	case DEF_multiSig_P2SH_P2WSH, DEF_multiSig_P2WSH:
+		witnessScriptHash := sha256.Sum256(script)
+		addr, err = btcutil.NewAddressWitnessScriptHash(witnessScriptHash[:], params)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if scriptType == DEF_multiSig_P2WSH {
+			return nil, script, addr, nil
+		}
+		redeemScript, err = txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		addr, err = btcutil.NewAddressScriptHash(redeemScript, params)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return redeemScript, script, addr, nil
+	default:
+		return nil, nil, nil, fmt.Errorf("invalid script type | %s", scriptType)
+	}
+}
+
+// Descriptor returns output descriptor with checksum ( ex : sh(wsh(multi(2,...)))#checksum )
+func (t *MultiSig) Descriptor() (desc string, err error) {
+	keys := make([]string, 0, len(t.PubKeys))
+	for _, pubKey := range t.PubKeys {
+		keys = append(keys, hex.EncodeToString(pubKey))
+	}
+	multi := "multi"
+	if t.Sorted == true {
+		multi = "sortedmulti"
+	}
+	desc = fmt.Sprintf("%s(%d,%s)", multi, t.Required, strings.Join(keys, ","))
+
+	switch t.Type {
+	case DEF_multiSig_P2SH:
+		desc = "sh(" + desc + ")"
+	case DEF_multiSig_P2SH_P2WSH:
+		desc = "sh(wsh(" + desc + "))"
+	case DEF_multiSig_P2WSH:
+		desc = "wsh(" + desc + ")"
+	}
+	return AddDescriptorChecksum(desc)
+}
+
+func (t *MultiSig) PkScript() (pkScript []byte, err error) {
+	return txscript.PayToAddrScript(t.Address)
+}
+
+func (t *MultiSig) isWitness() bool {
+	return t.Type != DEF_multiSig_P2SH
+}
+
+//--------------------------------------------------------------------------------//
+// sign
+
+// Sign returns signature ( SIGHASH_ALL ) of input idx spending this multisig output
+// sigHashes is needed for segwit types
+func (t *MultiSig) Sign(msgTx *wire.MsgTx, idx int, amount btcutil.Amount, sigHashes *txscript.TxSigHashes, wif *btcutil.WIF) (sig []byte, err error) {
+	if t.indexOf(wif.SerializePubKey()) == -1 {
+		return nil, fmt.Errorf("private key is not in multisig")
+	}
+	if t.isWitness() == true {
+		return txscript.RawTxInWitnessSignature(msgTx, sigHashes, idx, int64(amount), t.Script, txscript.SigHashAll, wif.PrivKey)
+	}
+	return txscript.RawTxInSignature(msgTx, idx, t.Script, txscript.SigHashAll, wif.PrivKey)
+}
+
+// Assemble sets final scriptSig / witness of txIn from partial signatures ( key : hex public key )
+// signatures are placed in order of public keys in script
+func (t *MultiSig) Assemble(txIn *wire.TxIn, sigs map[string][]byte) (err error) {
+	ordered := make([][]byte, 0, t.Required)
+	for _, pubKey := range t.PubKeys {
+		if sig, ok := sigs[hex.EncodeToString(pubKey)]; ok == true {
+			ordered = append(ordered, sig)
+		}
+		if len(ordered) == t.Required {
+			break
+		}
+	}
+	if len(ordered) < t.Required {
+		return fmt.Errorf("not enough signatures | required : %d | signed : %d", t.Required, len(ordered))
+	}
+
+	switch t.Type {
+	case DEF_multiSig_P2SH:
+		builder := txscript.NewScriptBuilder().AddOp(txscript.OP_0) // CHECKMULTISIG pops extra item
+		for _, sig := range ordered {
+			builder.AddData(sig)
+		}
+		txIn.SignatureScript, err = builder.AddData(t.RedeemScript).Script()
+		txIn.Witness = nil
+		return err
+
+	default:
+		witness := wire.TxWitness{nil}
+		witness = append(witness, ordered...)
+		witness = append(witness, t.WitnessScript)
+		txIn.Witness = witness
+		txIn.SignatureScript = nil
+		if t.Type == DEF_multiSig_P2SH_P2WSH {
+			txIn.SignatureScript, err = txscript.NewScriptBuilder().AddData(t.RedeemScript).Script()
+		}
+		return err
+	}
+}
+
+func (t *MultiSig) indexOf(pubKey []byte) int {
+	for i, key := range t.PubKeys {
+		if bytes.Equal(key, pubKey) == true {
+			return i
+		}
+	}
+	return -1
+}
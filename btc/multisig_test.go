@@ -0,0 +1,108 @@
+package btc
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiSigAddress(t *testing.T) {
+	// BIP67 test vector
+	pubKeys := []string{
+		"02ff12471208c14bd580709cb2358d98975247d8765f92bc25eab3b2763ed605f8",
+		"02fe6f0a5a297eb38c391581c4413e084773ea23954d93f7753db7dc0adc188b2f",
+	}
+	multiSig, err := NewMultiSig(&chaincfg.MainNetParams, DEF_multiSig_P2SH, 2, pubKeys, true)
+	require.NoError(t, err)
+	require.Equal(t, "522102fe6f0a5a297eb38c391581c4413e084773ea23954d93f7753db7dc0adc188b2f2102ff12471208c14bd580709cb2358d98975247d8765f92bc25eab3b2763ed605f852ae", hex.EncodeToString(multiSig.RedeemScript))
+	require.Equal(t, "39bgKC7RFbpoCRbtD5KEdkYKtNyhpsNa3Z", multiSig.Address.EncodeAddress())
+
+	desc, err := multiSig.Descriptor()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(desc, "sh(sortedmulti(2,02fe6f0a"))
+	checksum, err := DescriptorChecksum(desc[:strings.Index(desc, "#")])
+	require.NoError(t, err)
+	require.Equal(t, checksum, desc[strings.Index(desc, "#")+1:])
+
+	// invalid
+	_, err = NewMultiSig(&chaincfg.MainNetParams, DEF_multiSig_P2SH, 3, pubKeys, true)
+	require.Error(t, err)
+	_, err = NewMultiSig(&chaincfg.MainNetParams, DEF_multiSig_P2WSH, 1, []string{"zz"}, true)
+	require.Error(t, err)
+}
+
+func TestDescriptorChecksum(t *testing.T) {
+	// BIP380 test vector
+	checksum, err := DescriptorChecksum("raw(deadbeef)")
+	require.NoError(t, err)
+	require.Equal(t, "89f8spxm", checksum)
+
+	_, err = DescriptorChecksum("raw(deadbeef)\n")
+	require.Error(t, err)
+}
+
+func TestMultiSigSign(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	wifs := []*btcutil.WIF{newTestKey(t, 4, true), newTestKey(t, 5, true), newTestKey(t, 6, true)}
+	pubKeys := make([]string, 0, len(wifs))
+	for _, wif := range wifs {
+		pubKeys = append(pubKeys, hex.EncodeToString(wif.SerializePubKey()))
+	}
+	hash, err := chainhash.NewHashFromStr(DEF_txid_dummy)
+	require.NoError(t, err)
+
+	for _, multiSigType := range []MultiSigType{DEF_multiSig_P2SH, DEF_multiSig_P2SH_P2WSH, DEF_multiSig_P2WSH} {
+		t.Run(string(multiSigType), func(t *testing.T) {
+			multiSig, err := NewMultiSig(params, multiSigType, 2, pubKeys, false)
+			require.NoError(t, err)
+			pkScript, err := multiSig.PkScript()
+			require.NoError(t, err)
+			prevOut := wire.NewTxOut(100000, pkScript)
+
+			msgTx := wire.NewMsgTx(wire.TxVersion)
+			msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, 0), nil, nil))
+			msgTx.AddTxOut(wire.NewTxOut(90000, pkScript))
+			fetcher := txscript.NewCannedPrevOutputFetcher(prevOut.PkScript, prevOut.Value)
+			sigHashes := txscript.NewTxSigHashes(msgTx, fetcher)
+
+			// collect signatures of key 3, key 1 ( out of order )
+			sigs := make(map[string][]byte)
+			for _, wif := range []*btcutil.WIF{wifs[2], wifs[0]} {
+				sig, err := multiSig.Sign(msgTx, 0, btcutil.Amount(prevOut.Value), sigHashes, wif)
+				require.NoError(t, err)
+				sigs[hex.EncodeToString(wif.SerializePubKey())] = sig
+				if len(sigs) == 1 {
+					require.Error(t, multiSig.Assemble(msgTx.TxIn[0], sigs))
+				}
+			}
+			require.NoError(t, multiSig.Assemble(msgTx.TxIn[0], sigs))
+
+			engine, err := txscript.NewEngine(prevOut.PkScript, msgTx, 0, txscript.StandardVerifyFlags, nil, sigHashes, prevOut.Value, fetcher)
+			require.NoError(t, err)
+			require.NoError(t, engine.Execute())
+
+			// size estimate covers signed input
+			vsize, err := inputVSize(pkScript, multiSig.RedeemScript, multiSig.WitnessScript)
+			require.NoError(t, err)
+			estimator := &TxSizeEstimator{}
+			require.NoError(t, estimator.AddInput(pkScript, multiSig.RedeemScript, multiSig.WitnessScript))
+			estimator.AddOutput(pkScript)
+			_, vsizeSigned := getRawTxSize(msgTx)
+			require.GreaterOrEqual(t, estimator.VSize(), int64(vsizeSigned))
+			require.Greater(t, vsize, int64(0))
+		})
+	}
+
+	// key not in multisig
+	multiSig, err := NewMultiSig(params, DEF_multiSig_P2WSH, 2, pubKeys, false)
+	require.NoError(t, err)
+	_, err = multiSig.Sign(wire.NewMsgTx(wire.TxVersion), 0, 0, nil, newTestKey(t, 7, true))
+	require.Error(t, err)
+}
@@ -0,0 +1,404 @@
+package btc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/gozmq"
+)
+
+const (
+	DEF_zmqTopic_rawBlock  = "rawblock"
+	DEF_zmqTopic_rawTx     = "rawtx"
+	DEF_zmqTopic_hashBlock = "hashblock"
+
+	DEF_notifyBuffer   = 100
+	DEF_zmqReadTimeout = 5 * time.Second
+	DEF_pollInterval   = 5 * time.Second
+	DEF_pollMaxBlocks  = 10 // blocks sent at once when polling falls behind, older ones are skipped
+)
+
+var (
+	ErrNotifyMissed = errors.New("notification missed")
+)
+
+// ZMQEndpoints are addresses of zmqpub* options of bitcoin core ( ex : tcp://127.0.0.1:28332 )
+// empty endpoint is not subscribed, topics on the same endpoint share one connection
+type ZMQEndpoints struct {
+	RawBlock  string
+	RawTx     string
+	HashBlock string
+}
+
+// Notifier delivers new blocks and mempool txs on channels until ctx is done or Close is called
+// channels are closed after stop, values are not dropped - consumer must drain every channel it gets values on
+// ( zmq delivers subscribed topics only, polling delivers Blocks, BlockHashes and Txs ), otherwise notifier waits
+type Notifier struct {
+	blocks      chan *wire.MsgBlock
+	blockHashes chan *chainhash.Hash
+	txs         chan *wire.MsgTx
+	errs        chan error
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	done   chan struct{}
+}
+
+func newNotifier(ctx context.Context) (notifier *Notifier, ctxNotify context.Context) {
+	ctxNotify, cancel := context.WithCancel(ctx)
+	notifier = &Notifier{
+		blocks:      make(chan *wire.MsgBlock, DEF_notifyBuffer),
+		blockHashes: make(chan *chainhash.Hash, DEF_notifyBuffer),
+		txs:         make(chan *wire.MsgTx, DEF_notifyBuffer),
+		errs:        make(chan error, DEF_notifyBuffer),
+		cancel:      cancel,
+		done:        make(chan struct{}),
+	}
+	return notifier, ctxNotify
+}
+
+func (t *Notifier) Blocks() <-chan *wire.MsgBlock {
+	return t.blocks
+}
+
+func (t *Notifier) BlockHashes() <-chan *chainhash.Hash {
+	return t.blockHashes
+}
+
+func (t *Notifier) Txs() <-chan *wire.MsgTx {
+	return t.txs
+}
+
+// Errs delivers errors which do not stop notifier ( dropped if nobody reads )
+// ErrNotifyMissed is sent when sequence of zmq message skips
+func (t *Notifier) Errs() <-chan error {
+	return t.errs
+}
+
+// Close stops notifier and waits until channels are closed
+func (t *Notifier) Close() {
+	t.cancel()
+	<-t.done
+}
+
+func (t *Notifier) start(run ...func()) {
+	t.wg.Add(len(run))
+	for _, fn := range run {
+		go func(fn func()) {
+			defer t.wg.Done()
+			fn()
+		}(fn)
+	}
+	go func() {
+		t.wg.Wait()
+		close(t.blocks)
+		close(t.blockHashes)
+		close(t.txs)
+		close(t.errs)
+		close(t.done)
+	}()
+}
+
+func (t *Notifier) sendErr(err error) {
+	select {
+	case t.errs <- err:
+	default:
+	}
+}
+
+// sendNotify blocks until value is read or ctx is done
+func sendNotify[T any](ctx context.Context, ch chan T, value T) (err error) {
+	select {
+	case ch <- value:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//--------------------------------------------------------------------------------//
+// zmq
+
+// SubscribeZMQ connects to zmq publishers of bitcoin core
+// reconnects automatically if node restarts ( messages while disconnected are lost, use polling or scanner to fill gap )
+func (t *Client) SubscribeZMQ(ctx context.Context, endpoints *ZMQEndpoints) (notifier *Notifier, err error) {
+	topicsByAddr := make(map[string][]string)
+	for _, endpoint := range []struct{ addr, topic string }{
+		{endpoints.RawBlock, DEF_zmqTopic_rawBlock},
+		{endpoints.RawTx, DEF_zmqTopic_rawTx},
+		{endpoints.HashBlock, DEF_zmqTopic_hashBlock},
+	} {
+		if endpoint.addr != "" {
+			topicsByAddr[endpoint.addr] = append(topicsByAddr[endpoint.addr], endpoint.topic)
+		}
+	}
+	if len(topicsByAddr) == 0 {
+		return nil, fmt.Errorf("no zmq endpoint")
+	}
+
+	conns := make([]*gozmq.Conn, 0, len(topicsByAddr))
+	for addr, topics := range topicsByAddr {
+		conn, err := gozmq.Subscribe(addr, topics, DEF_zmqReadTimeout)
+		if err != nil {
+			for _, conn := range conns {
+				conn.Close()
+			}
+			return nil, fmt.Errorf("zmq subscribe failed | %s | %w", addr, err)
+		}
+		conns = append(conns, conn)
+	}
+
+	notifier, ctx = newNotifier(ctx)
+	run := make([]func(), 0, len(conns))
+	for _, conn := range conns {
+		conn := conn
+		run = append(run, func() { notifier.runZMQ(ctx, conn) })
+	}
+	notifier.start(run...)
+	return notifier, nil
+}
+
+func (t *Notifier) runZMQ(ctx context.Context, conn *gozmq.Conn) {
+	go func() {
+		<-ctx.Done()
+		conn.Close() // unblock Receive
+	}()
+
+	sequences := make(map[string]uint32)
+	for {
+		msg, err := conn.Receive(nil)
+		if err != nil {
+			if ctx.Err() != nil || err == io.EOF {
+				return
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) == true && netErr.Timeout() == true {
+				continue // reconnected or no message
+			}
+			t.sendErr(fmt.Errorf("zmq receive failed | %w", err))
+			continue
+		}
+		err = t.handleZMQ(ctx, msg, sequences)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			t.sendErr(err)
+		}
+	}
+}
+
+// handleZMQ decodes message of [ topic, body, sequence ( 4 bytes LE ) ]
+func (t *Notifier) handleZMQ(ctx context.Context, msg [][]byte, sequences map[string]uint32) (err error) {
+	if len(msg) < 2 {
+		return fmt.Errorf("invalid zmq message | parts : %d", len(msg))
+	}
+	topic, body := string(msg[0]), msg[1]
+
+	if len(msg) >= 3 && len(msg[2]) == 4 {
+		sequence := binary.LittleEndian.Uint32(msg[2])
+		last, ok := sequences[topic]
+		sequences[topic] = sequence
+		if ok == true && sequence != last+1 {
+			err = fmt.Errorf("%w | topic : %s | sequence : %d -> %d", ErrNotifyMissed, topic, last, sequence)
+		}
+	}
+
+	switch topic {
+	case DEF_zmqTopic_rawBlock:
+		msgBlock := &wire.MsgBlock{}
+		if errDecode := msgBlock.Deserialize(bytes.NewReader(body)); errDecode != nil {
+			return fmt.Errorf("invalid raw block | %w", errDecode)
+		}
+		if errSend := sendNotify(ctx, t.blocks, msgBlock); errSend != nil {
+			return errSend
+		}
+	case DEF_zmqTopic_rawTx:
+		msgTx := &wire.MsgTx{}
+		if errDecode := msgTx.Deserialize(bytes.NewReader(body)); errDecode != nil {
+			return fmt.Errorf("invalid raw tx | %w", errDecode)
+		}
+		if errSend := sendNotify(ctx, t.txs, msgTx); errSend != nil {
+			return errSend
+		}
+	case DEF_zmqTopic_hashBlock:
+		// hash is published in rpc byte order
+		hash, errDecode := chainhash.NewHashFromStr(hex.EncodeToString(body))
+		if errDecode != nil {
+			return fmt.Errorf("invalid block hash | %w", errDecode)
+		}
+		if errSend := sendNotify(ctx, t.blockHashes, hash); errSend != nil {
+			return errSend
+		}
+	}
+	return err
+}
+
+//--------------------------------------------------------------------------------//
+// polling
+
+// pollSource is part of Client used by polling notifier
+type pollSource interface {
+	GetBlockCount(ctx context.Context) (blockNumber int64, err error)
+	GetBlockHash(ctx context.Context, blockNumber int64) (blockHash string, err error)
+	GetBlock(ctx context.Context, blockHash string) (msgBlock *wire.MsgBlock, err error)
+	GetRawMempool(ctx context.Context) (txids []string, err error)
+	GetRawTx(ctx context.Context, txid string) (msgTx *wire.MsgTx, err error)
+}
+
+// SubscribePolling delivers new blocks and mempool txs by polling rpc ( fallback if zmq is not enabled on node )
+// state at start is not delivered, interval <= 0 uses default
+func (t *Client) SubscribePolling(ctx context.Context, interval time.Duration) (notifier *Notifier, err error) {
+	return subscribePolling(ctx, t, interval)
+}
+
+func subscribePolling(ctx context.Context, source pollSource, interval time.Duration) (notifier *Notifier, err error) {
+	if interval <= 0 {
+		interval = DEF_pollInterval
+	}
+	poller := &poller{source: source, mempool: make(map[string]struct{})}
+	err = poller.init(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	notifier, ctx = newNotifier(ctx)
+	notifier.start(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := poller.poll(ctx, notifier)
+				if err != nil && ctx.Err() == nil {
+					notifier.sendErr(err)
+				}
+			}
+		}
+	})
+	return notifier, nil
+}
+
+type poller struct {
+	source pollSource
+
+	height    int64
+	blockHash string
+	mempool   map[string]struct{} // txid
+}
+
+func (t *poller) init(ctx context.Context) (err error) {
+	t.height, err = t.source.GetBlockCount(ctx)
+	if err != nil {
+		return err
+	}
+	t.blockHash, err = t.source.GetBlockHash(ctx, t.height)
+	if err != nil {
+		return err
+	}
+	txids, err := t.source.GetRawMempool(ctx)
+	if err != nil {
+		return err
+	}
+	for _, txid := range txids {
+		t.mempool[txid] = struct{}{}
+	}
+	return nil
+}
+
+func (t *poller) poll(ctx context.Context, notifier *Notifier) (err error) {
+	err = t.pollBlock(ctx, notifier)
+	if err != nil {
+		return err
+	}
+	return t.pollMempool(ctx, notifier)
+}
+
+// pollBlock sends blocks after last height, or new tip only if tip is replaced by reorg
+func (t *poller) pollBlock(ctx context.Context, notifier *Notifier) (err error) {
+	height, err := t.source.GetBlockCount(ctx)
+	if err != nil {
+		return err
+	}
+	tipHash, err := t.source.GetBlockHash(ctx, height)
+	if err != nil {
+		return err
+	}
+	if tipHash == t.blockHash {
+		return nil
+	}
+
+	from := t.height + 1
+	if height <= t.height {
+		from = height
+	}
+	if from < height-DEF_pollMaxBlocks+1 {
+		notifier.sendErr(fmt.Errorf("%w | blocks %d ~ %d are skipped", ErrNotifyMissed, from, height-DEF_pollMaxBlocks))
+		from = height - DEF_pollMaxBlocks + 1
+	}
+	for h := from; h <= height; h++ {
+		blockHash := tipHash
+		if h != height {
+			blockHash, err = t.source.GetBlockHash(ctx, h)
+			if err != nil {
+				return err
+			}
+		}
+		msgBlock, err := t.source.GetBlock(ctx, blockHash)
+		if err != nil {
+			return err
+		}
+		hash := msgBlock.BlockHash()
+		err = sendNotify(ctx, notifier.blocks, msgBlock)
+		if err != nil {
+			return err
+		}
+		err = sendNotify(ctx, notifier.blockHashes, &hash)
+		if err != nil {
+			return err
+		}
+		t.height, t.blockHash = h, blockHash
+	}
+	return nil
+}
+
+// pollMempool sends txs not seen in last poll
+// txid is seen only after it is delivered, tx failed to fetch is tried again on next poll
+func (t *poller) pollMempool(ctx context.Context, notifier *Notifier) (err error) {
+	txids, err := t.source.GetRawMempool(ctx)
+	if err != nil {
+		return err
+	}
+	mempool := make(map[string]struct{}, len(txids))
+	defer func() {
+		t.mempool = mempool
+	}()
+	for _, txid := range txids {
+		if _, ok := t.mempool[txid]; ok == true {
+			mempool[txid] = struct{}{}
+			continue
+		}
+		msgTx, err := t.source.GetRawTx(ctx, txid)
+		if err != nil {
+			continue // mined or evicted after getrawmempool, or failed
+		}
+		err = sendNotify(ctx, notifier.txs, msgTx)
+		if err != nil {
+			return err
+		}
+		mempool[txid] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package btc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func zmqSequence(sequence uint32) []byte {
+	bt := make([]byte, 4)
+	binary.LittleEndian.PutUint32(bt, sequence)
+	return bt
+}
+
+func TestNotifierZMQMessage(t *testing.T) {
+	notifier, _ := newNotifier(context.Background())
+	sequences := make(map[string]uint32)
+
+	msgBlock := chaincfg.RegressionNetParams.GenesisBlock
+	var buf bytes.Buffer
+	require.NoError(t, msgBlock.Serialize(&buf))
+	require.NoError(t, notifier.handleZMQ(context.Background(), [][]byte{[]byte(DEF_zmqTopic_rawBlock), buf.Bytes(), zmqSequence(0)}, sequences))
+	require.Equal(t, msgBlock.BlockHash(), (<-notifier.Blocks()).BlockHash())
+
+	// hash in rpc byte order
+	hash := msgBlock.BlockHash()
+	hashReversed := make([]byte, chainhash.HashSize)
+	for i := range hash {
+		hashReversed[i] = hash[chainhash.HashSize-1-i]
+	}
+	require.NoError(t, notifier.handleZMQ(context.Background(), [][]byte{[]byte(DEF_zmqTopic_hashBlock), hashReversed, zmqSequence(0)}, sequences))
+	require.Equal(t, hash, *<-notifier.BlockHashes())
+
+	msgTx := msgBlock.Transactions[0]
+	buf.Reset()
+	require.NoError(t, msgTx.Serialize(&buf))
+	require.NoError(t, notifier.handleZMQ(context.Background(), [][]byte{[]byte(DEF_zmqTopic_rawTx), buf.Bytes(), zmqSequence(7)}, sequences))
+	require.Equal(t, msgTx.TxHash(), (<-notifier.Txs()).TxHash())
+
+	// sequence gap is reported, message is still delivered
+	require.ErrorIs(t, notifier.handleZMQ(context.Background(), [][]byte{[]byte(DEF_zmqTopic_rawTx), buf.Bytes(), zmqSequence(9)}, sequences), ErrNotifyMissed)
+	require.Equal(t, msgTx.TxHash(), (<-notifier.Txs()).TxHash())
+
+	// invalid
+	require.Error(t, notifier.handleZMQ(context.Background(), [][]byte{[]byte(DEF_zmqTopic_rawTx)}, sequences))
+	require.Error(t, notifier.handleZMQ(context.Background(), [][]byte{[]byte(DEF_zmqTopic_rawBlock), []byte{1, 2}}, sequences))
+}
+
+// testPollSource is chain of empty blocks and mempool
+type testPollSource struct {
+	mtx     sync.Mutex
+	blocks  []*wire.MsgBlock
+	mempool map[string]*wire.MsgTx
+	fails   map[string]int // txid -> count of GetRawTx failures
+}
+
+func (t *testPollSource) addBlock() {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	header := wire.BlockHeader{Nonce: uint32(len(t.blocks))}
+	if len(t.blocks) > 0 {
+		header.PrevBlock = t.blocks[len(t.blocks)-1].BlockHash()
+	}
+	t.blocks = append(t.blocks, wire.NewMsgBlock(&header))
+}
+
+func (t *testPollSource) addTx(lockTime uint32) *wire.MsgTx {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	msgTx.LockTime = lockTime
+	t.mempool[msgTx.TxHash().String()] = msgTx
+	return msgTx
+}
+
+func (t *testPollSource) GetBlockCount(ctx context.Context) (blockNumber int64, err error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return int64(len(t.blocks) - 1), nil
+}
+
+func (t *testPollSource) GetBlockHash(ctx context.Context, blockNumber int64) (blockHash string, err error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.blocks[blockNumber].BlockHash().String(), nil
+}
+
+func (t *testPollSource) GetBlock(ctx context.Context, blockHash string) (msgBlock *wire.MsgBlock, err error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	for _, block := range t.blocks {
+		if block.BlockHash().String() == blockHash {
+			return block, nil
+		}
+	}
+	return nil, fmt.Errorf("block not found")
+}
+
+func (t *testPollSource) GetRawMempool(ctx context.Context) (txids []string, err error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	for txid := range t.mempool {
+		txids = append(txids, txid)
+	}
+	return txids, nil
+}
+
+func (t *testPollSource) GetRawTx(ctx context.Context, txid string) (msgTx *wire.MsgTx, err error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if t.fails[txid] > 0 {
+		t.fails[txid]--
+		return nil, fmt.Errorf("temporary failure")
+	}
+	return t.mempool[txid], nil
+}
+
+func TestNotifierPolling(t *testing.T) {
+	source := &testPollSource{mempool: make(map[string]*wire.MsgTx)}
+	source.addBlock()
+	source.addTx(1) // in mempool at start, not delivered
+
+	notifier, err := subscribePolling(context.Background(), source, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	source.addBlock()
+	source.addBlock()
+	msgTx := source.addTx(2)
+
+	for i := 1; i <= 2; i++ {
+		select {
+		case msgBlock := <-notifier.Blocks():
+			require.Equal(t, source.blocks[i].BlockHash(), msgBlock.BlockHash())
+			require.Equal(t, source.blocks[i].BlockHash(), *<-notifier.BlockHashes())
+		case <-time.After(time.Second):
+			require.Fail(t, "block is not notified")
+		}
+	}
+	select {
+	case msgTxNotified := <-notifier.Txs():
+		require.Equal(t, msgTx.TxHash(), msgTxNotified.TxHash())
+	case <-time.After(time.Second):
+		require.Fail(t, "tx is not notified")
+	}
+
+	notifier.Close()
+	_, ok := <-notifier.Blocks()
+	require.False(t, ok)
+}
+
+func TestNotifierNoLoss(t *testing.T) {
+	source := &testPollSource{mempool: make(map[string]*wire.MsgTx), fails: make(map[string]int)}
+	source.addBlock()
+
+	notifier, err := subscribePolling(context.Background(), source, time.Millisecond)
+	require.NoError(t, err)
+	defer notifier.Close()
+
+	// more txs than buffer in one poll, and tx failing to fetch once
+	txids := make(map[chainhash.Hash]struct{})
+	source.mtx.Lock()
+	for i := 0; i < DEF_notifyBuffer+20; i++ {
+		msgTx := wire.NewMsgTx(wire.TxVersion)
+		msgTx.LockTime = uint32(i + 1)
+		source.mempool[msgTx.TxHash().String()] = msgTx
+		txids[msgTx.TxHash()] = struct{}{}
+		if i == 0 {
+			source.fails[msgTx.TxHash().String()] = 1
+		}
+	}
+	source.mtx.Unlock()
+
+	for len(txids) > 0 {
+		select {
+		case msgTx := <-notifier.Txs():
+			_, ok := txids[msgTx.TxHash()]
+			require.True(t, ok, "tx is delivered twice")
+			delete(txids, msgTx.TxHash())
+		case <-time.After(time.Second):
+			require.Fail(t, "tx is not notified", "left %d", len(txids))
+		}
+	}
+}
+
+func TestNotifierCloseBlocked(t *testing.T) {
+	source := &testPollSource{mempool: make(map[string]*wire.MsgTx)}
+	source.addBlock()
+	notifier, err := subscribePolling(context.Background(), source, time.Millisecond)
+	require.NoError(t, err)
+
+	// nobody reads, notifier waits on full channel until closed
+	for i := 0; i < DEF_pollMaxBlocks; i++ {
+		source.addBlock()
+	}
+	time.Sleep(20 * time.Millisecond)
+	closed := make(chan struct{})
+	go func() {
+		notifier.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		require.Fail(t, "close is blocked")
+	}
+}
@@ -0,0 +1,320 @@
+package btc
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// transfer without node ( build and sign locally, private keys never leave this host )
+// supports P2PKH, P2SH-P2WPKH, P2WPKH and P2TR ( key path ) inputs
+type OfflineTx struct {
+	params *chaincfg.Params
+
+	unspents []*Unspent
+	txOuts   []*wire.TxOut
+	keys     *keyStore
+}
+
+// Unspent is explicit utxo to spend ( scriptPubKey, redeemScript are hex like listunspent )
+type Unspent struct {
+	Txid         string
+	Vout         uint32
+	Amount       btcutil.Amount
+	ScriptPubKey string
+	RedeemScript string // optional - derived from key for P2SH-P2WPKH
+}
+
+func (t *OfflineTx) Init(params *chaincfg.Params) {
+	t.params = params
+	t.unspents = make([]*Unspent, 0, 10)
+	t.txOuts = make([]*wire.TxOut, 0, 10)
+	t.keys = newKeyStore()
+}
+
+func (t *OfflineTx) AddFrom(unspent *Unspent) (err error) {
+	if _, err = chainhash.NewHashFromStr(unspent.Txid); err != nil {
+		return err
+	}
+	if _, err = hex.DecodeString(unspent.ScriptPubKey); err != nil {
+		return err
+	}
+	t.unspents = append(t.unspents, unspent)
+	return nil
+}
+
+func (t *OfflineTx) AddTo(address string, amount btcutil.Amount) (err error) {
+	addr, err := decodeAddress(address, t.params)
+	if err != nil {
+		return err
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return err
+	}
+	t.txOuts = append(t.txOuts, wire.NewTxOut(int64(amount), pkScript))
+	return nil
+}
+
+func (t *OfflineTx) AddKey(privKey string) (err error) {
+	return t.keys.add(privKey, t.params)
+}
+
+// Fee returns sum(inputs) - sum(outputs)
+func (t *OfflineTx) Fee() (fee btcutil.Amount) {
+	for _, unspent := range t.unspents {
+		fee += unspent.Amount
+	}
+	for _, txOut := range t.txOuts {
+		fee -= btcutil.Amount(txOut.Value)
+	}
+	return fee
+}
+
+// EstimateFee returns fee of signed tx at fee rate ( add change output before estimating )
+func (t *OfflineTx) EstimateFee(feeRate FeeRate) (fee btcutil.Amount, err error) {
+	estimator := &TxSizeEstimator{}
+	prevOuts, err := t.prevOuts()
+	if err != nil {
+		return 0, err
+	}
+	for _, prev := range prevOuts {
+		err = estimator.AddInput(prev.txOut.PkScript, prev.redeemScript, nil)
+		if err != nil {
+			return 0, err
+		}
+	}
+	for _, txOut := range t.txOuts {
+		estimator.AddOutput(txOut.PkScript)
+	}
+	return estimator.Fee(feeRate), nil
+}
+
+// Build returns unsigned tx
+func (t *OfflineTx) Build() (msgTx *wire.MsgTx, err error) {
+	if len(t.unspents) == 0 || len(t.txOuts) == 0 {
+		return nil, fmt.Errorf("no input or output")
+	}
+	if t.Fee() < 0 {
+		return nil, fmt.Errorf("output amount exceeds input amount | fee : %v", t.Fee())
+	}
+
+	msgTx = wire.NewMsgTx(wire.TxVersion)
+	for _, unspent := range t.unspents {
+		hash, err := chainhash.NewHashFromStr(unspent.Txid)
+		if err != nil {
+			return nil, err
+		}
+		msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, unspent.Vout), nil, nil))
+	}
+	for _, txOut := range t.txOuts {
+		msgTx.AddTxOut(wire.NewTxOut(txOut.Value, txOut.PkScript))
+	}
+	return msgTx, nil
+}
+
+// Sign builds and signs every input with added keys, then verifies scripts
+// fork id chain ( bitcoin cash ) is not supported, use RawTx
+func (t *OfflineTx) Sign() (msgTxSigned *wire.MsgTx, err error) {
+	if usesForkID(t.params) == true {
+		return nil, fmt.Errorf("fork id signing is not supported offline | %s", t.params.Name)
+	}
+	msgTxSigned, err = t.Build()
+	if err != nil {
+		return nil, err
+	}
+	prevOuts, err := t.prevOuts()
+	if err != nil {
+		return nil, err
+	}
+	err = signTx(msgTxSigned, prevOuts, t.keys)
+	if err != nil {
+		return nil, err
+	}
+	return msgTxSigned, nil
+}
+
+func (t *OfflineTx) prevOuts() (prevOuts []*prevOut, err error) {
+	prevOuts = make([]*prevOut, 0, len(t.unspents))
+	for _, unspent := range t.unspents {
+		pkScript, err := hex.DecodeString(unspent.ScriptPubKey)
+		if err != nil {
+			return nil, err
+		}
+		redeemScript, err := hex.DecodeString(unspent.RedeemScript)
+		if err != nil {
+			return nil, err
+		}
+		prevOuts = append(prevOuts, &prevOut{
+			txOut:        wire.NewTxOut(int64(unspent.Amount), pkScript),
+			redeemScript: redeemScript,
+		})
+	}
+	return prevOuts, nil
+}
+
+//--------------------------------------------------------------------------------//
+// sign
+
+type prevOut struct {
+	txOut        *wire.TxOut
+	redeemScript []byte
+}
+
+// keyStore indexes wif by hash160 of pubkey, by hash160 of P2SH-P2WPKH redeem script
+// and by taproot output key ( BIP86 )
+type keyStore struct {
+	byPubKeyHash map[[20]byte]*btcutil.WIF
+	byScriptHash map[[20]byte]*btcutil.WIF
+	byTaprootKey map[[32]byte]*btcutil.WIF
+}
+
+func newKeyStore() *keyStore {
+	return &keyStore{
+		byPubKeyHash: make(map[[20]byte]*btcutil.WIF),
+		byScriptHash: make(map[[20]byte]*btcutil.WIF),
+		byTaprootKey: make(map[[32]byte]*btcutil.WIF),
+	}
+}
+
+func (t *keyStore) add(privKey string, params *chaincfg.Params) (err error) {
+	wif, err := btcutil.DecodeWIF(privKey)
+	if err != nil {
+		return err
+	}
+	if wif.IsForNet(params) == false {
+		return fmt.Errorf("private key is not for network | %s", params.Name)
+	}
+
+	var pubKeyHash [20]byte
+	copy(pubKeyHash[:], btcutil.Hash160(wif.SerializePubKey()))
+	t.byPubKeyHash[pubKeyHash] = wif
+
+	if wif.CompressPubKey == true {
+		var scriptHash [20]byte
+		copy(scriptHash[:], btcutil.Hash160(p2wpkhScript(pubKeyHash[:])))
+		t.byScriptHash[scriptHash] = wif
+	}
+
+	var outputKey [32]byte
+	copy(outputKey[:], taprootOutputKey(wif.PrivKey.PubKey()))
+	t.byTaprootKey[outputKey] = wif
+	return nil
+}
+
+func (t *keyStore) getTaproot(outputKey []byte) (wif *btcutil.WIF, err error) {
+	var key [32]byte
+	copy(key[:], outputKey)
+	wif, ok := t.byTaprootKey[key]
+	if ok == false {
+		return nil, fmt.Errorf("private key not found | taproot output key : %x", outputKey)
+	}
+	return wif, nil
+}
+
+func (t *keyStore) get(hash []byte, byScript bool) (wif *btcutil.WIF, err error) {
+	var key [20]byte
+	copy(key[:], hash)
+	var ok bool
+	if byScript == true {
+		wif, ok = t.byScriptHash[key]
+	} else {
+		wif, ok = t.byPubKeyHash[key]
+	}
+	if ok == false {
+		return nil, fmt.Errorf("private key not found | hash160 : %x", hash)
+	}
+	return wif, nil
+}
+
+func p2wpkhScript(pubKeyHash []byte) []byte {
+	script, _ := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(pubKeyHash).Script()
+	return script
+}
+
+func signTx(msgTx *wire.MsgTx, prevOuts []*prevOut, keys *keyStore) (err error) {
+	if len(msgTx.TxIn) != len(prevOuts) {
+		return fmt.Errorf("input count mismatch | tx : %d | prevout : %d", len(msgTx.TxIn), len(prevOuts))
+	}
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for i, txIn := range msgTx.TxIn {
+		fetcher.AddPrevOut(txIn.PreviousOutPoint, prevOuts[i].txOut)
+	}
+	sigHashes := txscript.NewTxSigHashes(msgTx, fetcher)
+
+	for i := range msgTx.TxIn {
+		err = signInput(msgTx, i, prevOuts[i], sigHashes, keys)
+		if err != nil {
+			return fmt.Errorf("sign input %d failed | %w", i, err)
+		}
+	}
+
+	// verify signed scripts
+	for i := range msgTx.TxIn {
+		txOut := prevOuts[i].txOut
+		engine, err := txscript.NewEngine(txOut.PkScript, msgTx, i, txscript.StandardVerifyFlags, nil, sigHashes, txOut.Value, fetcher)
+		if err != nil {
+			return err
+		}
+		if err = engine.Execute(); err != nil {
+			return fmt.Errorf("verify input %d failed | %w", i, err)
+		}
+	}
+	return nil
+}
+
+func signInput(msgTx *wire.MsgTx, idx int, prev *prevOut, sigHashes *txscript.TxSigHashes, keys *keyStore) (err error) {
+	pkScript := prev.txOut.PkScript
+	txIn := msgTx.TxIn[idx]
+
+	switch txscript.GetScriptClass(pkScript) {
+	case txscript.PubKeyHashTy:
+		wif, err := keys.get(pkScript[3:23], false)
+		if err != nil {
+			return err
+		}
+		txIn.SignatureScript, err = txscript.SignatureScript(msgTx, idx, pkScript, txscript.SigHashAll, wif.PrivKey, wif.CompressPubKey)
+		return err
+
+	case txscript.WitnessV0PubKeyHashTy:
+		wif, err := keys.get(pkScript[2:22], false)
+		if err != nil {
+			return err
+		}
+		txIn.Witness, err = txscript.WitnessSignature(msgTx, sigHashes, idx, prev.txOut.Value, pkScript, txscript.SigHashAll, wif.PrivKey, true)
+		return err
+
+	case txscript.WitnessV1TaprootTy:
+		// key path spend, schnorr signature of SIGHASH_DEFAULT commits to every prevout in sigHashes
+		wif, err := keys.getTaproot(pkScript[2:34])
+		if err != nil {
+			return err
+		}
+		txIn.Witness, err = txscript.TaprootWitnessSignature(msgTx, sigHashes, idx, prev.txOut.Value, pkScript, txscript.SigHashDefault, wif.PrivKey)
+		return err
+
+	case txscript.ScriptHashTy:
+		if len(prev.redeemScript) > 0 && txscript.GetScriptClass(prev.redeemScript) != txscript.WitnessV0PubKeyHashTy {
+			return fmt.Errorf("unsupported redeem script type | %s", txscript.GetScriptClass(prev.redeemScript))
+		}
+		wif, err := keys.get(pkScript[2:22], true)
+		if err != nil {
+			return err
+		}
+		redeemScript := p2wpkhScript(btcutil.Hash160(wif.SerializePubKey()))
+		txIn.Witness, err = txscript.WitnessSignature(msgTx, sigHashes, idx, prev.txOut.Value, redeemScript, txscript.SigHashAll, wif.PrivKey, true)
+		if err != nil {
+			return err
+		}
+		txIn.SignatureScript, err = txscript.NewScriptBuilder().AddData(redeemScript).Script()
+		return err
+
+	default:
+		return fmt.Errorf("unsupported script type | %s", txscript.GetScriptClass(pkScript))
+	}
+}
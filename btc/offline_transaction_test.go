@@ -0,0 +1,96 @@
+package btc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	DEF_txid_dummy = "0f2c6a1fb0ba8a5a4d9a3e0c6f4a0b4a4cbf7c0d1e2f30415263748596a7b8c9"
+)
+
+// newTestKey returns deterministic key ( seed byte repeated )
+func newTestKey(t *testing.T, seed byte, compress bool) *btcutil.WIF {
+	privKey, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{seed}, 32))
+	wif, err := btcutil.NewWIF(privKey, &chaincfg.RegressionNetParams, compress)
+	require.NoError(t, err)
+	return wif
+}
+
+func newTestUnspent(t *testing.T, addr btcutil.Address, vout uint32, amount btcutil.Amount) *Unspent {
+	pkScript, err := txscript.PayToAddrScript(addr)
+	require.NoError(t, err)
+	return &Unspent{
+		Txid:         DEF_txid_dummy,
+		Vout:         vout,
+		Amount:       amount,
+		ScriptPubKey: hex.EncodeToString(pkScript),
+	}
+}
+
+func TestOfflineTxSign(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	wifLegacy := newTestKey(t, 1, false)
+	wifSegwit := newTestKey(t, 2, true)
+	wifNested := newTestKey(t, 3, true)
+
+	addrP2PKH, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(wifLegacy.SerializePubKey()), params)
+	require.NoError(t, err)
+	addrP2WPKH, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(wifSegwit.SerializePubKey()), params)
+	require.NoError(t, err)
+	addrP2SH, err := btcutil.NewAddressScriptHash(p2wpkhScript(btcutil.Hash160(wifNested.SerializePubKey())), params)
+	require.NoError(t, err)
+
+	tx := &OfflineTx{}
+	tx.Init(params)
+	require.NoError(t, tx.AddFrom(newTestUnspent(t, addrP2PKH, 0, 100000)))
+	require.NoError(t, tx.AddFrom(newTestUnspent(t, addrP2WPKH, 1, 200000)))
+	require.NoError(t, tx.AddFrom(newTestUnspent(t, addrP2SH, 2, 300000)))
+	require.NoError(t, tx.AddTo(addrP2WPKH.EncodeAddress(), 590000))
+	require.Equal(t, btcutil.Amount(10000), tx.Fee())
+
+	// key missing
+	_, err = tx.Sign()
+	require.Error(t, err)
+
+	for _, wif := range []*btcutil.WIF{wifLegacy, wifSegwit, wifNested} {
+		require.NoError(t, tx.AddKey(wif.String()))
+	}
+	msgTx, err := tx.Sign()
+	require.NoError(t, err)
+
+	require.Len(t, msgTx.TxIn, 3)
+	require.NotEmpty(t, msgTx.TxIn[0].SignatureScript) // P2PKH
+	require.Empty(t, msgTx.TxIn[0].Witness)
+	require.Empty(t, msgTx.TxIn[1].SignatureScript) // P2WPKH
+	require.Len(t, msgTx.TxIn[1].Witness, 2)
+	require.NotEmpty(t, msgTx.TxIn[2].SignatureScript) // P2SH-P2WPKH
+	require.Len(t, msgTx.TxIn[2].Witness, 2)
+}
+
+func TestOfflineTxInvalid(t *testing.T) {
+	tx := &OfflineTx{}
+	tx.Init(&chaincfg.RegressionNetParams)
+
+	// mainnet key on regtest
+	privKey, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{1}, 32))
+	wif, err := btcutil.NewWIF(privKey, &chaincfg.MainNetParams, true)
+	require.NoError(t, err)
+	require.Error(t, tx.AddKey(wif.String()))
+
+	// output exceeds input
+	wifSegwit := newTestKey(t, 2, true)
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(wifSegwit.SerializePubKey()), &chaincfg.RegressionNetParams)
+	require.NoError(t, err)
+	require.NoError(t, tx.AddFrom(newTestUnspent(t, addr, 0, 1000)))
+	require.NoError(t, tx.AddTo(addr.EncodeAddress(), 2000))
+	_, err = tx.Build()
+	require.Error(t, err)
+}
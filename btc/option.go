@@ -0,0 +1,226 @@
+package btc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/btcsuite/btcd/rpcclient"
+)
+
+// Option configures rpc connection of Client
+//
+// rpcclient builds its own http transport,
+// so client certificate and custom transport are applied by local forwarding proxy set as ConnConfig.Proxy
+type Option func(client *Client, config *rpcclient.ConnConfig) error
+
+// WithTimeout sets default timeout of every request ( applied when ctx has no earlier deadline )
+func WithTimeout(timeout time.Duration) Option {
+	return func(client *Client, config *rpcclient.ConnConfig) error {
+		client.timeout = timeout
+		return nil
+	}
+}
+
+// WithTLS enables TLS ( bitcoin core does not provide TLS, use with tls terminating proxy or hosted node )
+// caPEM is optional, system roots are used if empty
+func WithTLS(caPEM []byte) Option {
+	return func(client *Client, config *rpcclient.ConnConfig) error {
+		config.DisableTLS = false
+		config.Certificates = caPEM
+		return nil
+	}
+}
+
+// WithClientCert sets client certificate for mutual tls ( enables TLS )
+func WithClientCert(certPEM, keyPEM []byte) Option {
+	return func(client *Client, config *rpcclient.ConnConfig) error {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return err
+		}
+		config.DisableTLS = false
+		client.clientCerts = append(client.clientCerts, cert)
+		return nil
+	}
+}
+
+// WithTransport sends requests by custom http transport ( tls and proxy options are applied on top of it )
+func WithTransport(transport *http.Transport) Option {
+	return func(client *Client, config *rpcclient.ConnConfig) error {
+		client.transport = transport
+		return nil
+	}
+}
+
+// WithTLSCAFile enables TLS with ca bundle file
+func WithTLSCAFile(caFile string) Option {
+	return func(client *Client, config *rpcclient.ConnConfig) error {
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			return err
+		}
+		return WithTLS(caPEM)(client, config)
+	}
+}
+
+func WithBasicAuth(user, pass string) Option {
+	return func(client *Client, config *rpcclient.ConnConfig) error {
+		config.User = user
+		config.Pass = pass
+		return nil
+	}
+}
+
+// WithCookieAuth uses .cookie file of bitcoin core instead of user / pass
+func WithCookieAuth(cookiePath string) Option {
+	return func(client *Client, config *rpcclient.ConnConfig) error {
+		config.CookiePath = cookiePath
+		return nil
+	}
+}
+
+// WithBearerToken sets authorization header for hosted node providers
+func WithBearerToken(token string) Option {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+func WithHeader(key, value string) Option {
+	return func(client *Client, config *rpcclient.ConnConfig) error {
+		if config.ExtraHeaders == nil {
+			config.ExtraHeaders = make(map[string]string)
+		}
+		config.ExtraHeaders[key] = value
+		return nil
+	}
+}
+
+// WithProxy sets http proxy ( user info in url is used as proxy auth )
+func WithProxy(proxyURL string) Option {
+	return func(client *Client, config *rpcclient.ConnConfig) error {
+		proxy, err := url.Parse(proxyURL)
+		if err != nil {
+			return err
+		}
+		config.Proxy = proxyURL
+		if proxy.User != nil {
+			config.ProxyUser = proxy.User.Username()
+			config.ProxyPass, _ = proxy.User.Password()
+		}
+		return nil
+	}
+}
+
+//--------------------------------------------------------------------------------//
+// forwarder
+
+// forwarder is local http proxy passing requests of rpcclient to custom transport
+// rpcclient sends plain http to forwarder, forwarder connects node by tls of transport
+// requests to other hosts are refused, forwarder is not open proxy presenting client certificate
+type forwarder struct {
+	listener  net.Listener
+	server    *http.Server
+	transport *http.Transport
+	scheme    string // scheme of node
+	host      string // host:port of node
+}
+
+// openForwarder starts forwarder if client certificate or custom transport is set, and routes config through it
+func (t *Client) openForwarder(config *rpcclient.ConnConfig) (err error) {
+	if t.transport == nil && len(t.clientCerts) == 0 {
+		return nil
+	}
+
+	var transport *http.Transport
+	if t.transport != nil {
+		transport = t.transport.Clone()
+	} else {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	scheme := "http"
+	if config.DisableTLS == false {
+		scheme = "https"
+		tlsConfig := &tls.Config{}
+		if transport.TLSClientConfig != nil {
+			tlsConfig = transport.TLSClientConfig.Clone() // keep settings of custom transport
+		}
+		if len(config.Certificates) > 0 {
+			pool := x509.NewCertPool()
+			if pool.AppendCertsFromPEM(config.Certificates) == false {
+				return fmt.Errorf("invalid ca certificate")
+			}
+			tlsConfig.RootCAs = pool
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, t.clientCerts...)
+		transport.TLSClientConfig = tlsConfig
+	} else if len(t.clientCerts) > 0 {
+		return fmt.Errorf("client certificate needs tls")
+	}
+	if config.Proxy != "" {
+		proxy, err := url.Parse(config.Proxy)
+		if err != nil {
+			return err
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	node, err := url.Parse("http://" + config.Host)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	t.forwarder = &forwarder{
+		listener:  listener,
+		transport: transport,
+		scheme:    scheme,
+		host:      node.Host,
+	}
+	t.forwarder.server = &http.Server{Handler: t.forwarder}
+	go t.forwarder.server.Serve(listener)
+
+	config.DisableTLS = true
+	config.Proxy = "http://" + listener.Addr().String()
+	config.ProxyUser = ""
+	config.ProxyPass = ""
+	return nil
+}
+
+func (t *forwarder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// request to proxy has absolute url, only node is allowed
+	if r.Method == http.MethodConnect || r.URL.Host != t.host {
+		http.Error(w, "forbidden host", http.StatusForbidden)
+		return
+	}
+	req := r.Clone(r.Context())
+	req.RequestURI = ""
+	req.URL.Scheme = t.scheme
+	req.Header.Del("Proxy-Authorization")
+	req.Header.Del("Proxy-Connection")
+
+	res, err := t.transport.RoundTrip(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer res.Body.Close()
+	for key, values := range res.Header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(res.StatusCode)
+	io.Copy(w, res.Body)
+}
+
+func (t *forwarder) Close() error {
+	t.transport.CloseIdleConnections()
+	return t.server.Close()
+}
@@ -0,0 +1,160 @@
+package btc
+
+import (
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// params of bitcoin family chains, use with Client.Open like chaincfg.MainNetParams
+// only fields used for addresses, keys and rpc are set ( no genesis block, checkpoints, deployments )
+// importing btc registers them to process global registry of chaincfg ( chaincfg.Register, needed by btcutil.DecodeAddress )
+// some base58 ids are shared with bitcoin ( LTC testnet P2PKH 0x6f, DOGE testnet P2SH 0xc4, BCH legacy 0x00 / 0x05 ),
+// such address is valid on several networks, see DetectAddressNetworks
+var (
+	LTCMainNetParams = newForkParams(chaincfg.MainNetParams, func(params *chaincfg.Params) {
+		params.Name = "litecoin"
+		params.Net = 0xdbb6c0fb
+		params.DefaultPort = "9333"
+		params.GenesisHash = mustHash("12a765e31ffd4059bada1e25190f6e98c99d9714d334efa41a195a7e7e04bfe2")
+		params.Bech32HRPSegwit = "ltc"
+		params.PubKeyHashAddrID = 0x30 // L
+		params.ScriptHashAddrID = 0x32 // M
+		params.PrivateKeyID = 0xb0
+		params.HDCoinType = 2
+	})
+	LTCTestNetParams = newForkParams(chaincfg.TestNet3Params, func(params *chaincfg.Params) {
+		params.Name = "litecoin-testnet4"
+		params.Net = 0xf1c8d2fd
+		params.DefaultPort = "19335"
+		params.GenesisHash = mustHash("4966625a4b2851d9fdee139e56211a0d88575f59ed816ff5e6a63deb4e3e29a0")
+		params.Bech32HRPSegwit = "tltc"
+		params.PubKeyHashAddrID = 0x6f // m, n
+		params.ScriptHashAddrID = 0x3a // Q
+		params.PrivateKeyID = 0xef
+	})
+
+	// legacy address is the same as bitcoin, use CashAddr with EncodeAddress
+	// node must run with -usecashaddr=0, rpcclient decodes addresses returned by node with btcutil
+	BCHMainNetParams = newForkParams(chaincfg.MainNetParams, func(params *chaincfg.Params) {
+		params.Name = "bitcoincash"
+		params.Net = 0xe8f3e1e3
+		params.Bech32HRPSegwit = "" // no segwit
+		params.HDCoinType = 145
+	})
+	BCHTestNetParams = newForkParams(chaincfg.TestNet3Params, func(params *chaincfg.Params) {
+		params.Name = "bitcoincash-testnet"
+		params.Net = 0xf4f3e5f4
+		params.Bech32HRPSegwit = ""
+	})
+
+	DOGEMainNetParams = newForkParams(chaincfg.MainNetParams, func(params *chaincfg.Params) {
+		params.Name = "dogecoin"
+		params.Net = 0xc0c0c0c0
+		params.DefaultPort = "22556"
+		params.GenesisHash = mustHash("1a91e3dace36e2be3bf030a65679fe821aa1d6ef92e7c9902eb318182c355691")
+		params.Bech32HRPSegwit = ""    // no segwit
+		params.PubKeyHashAddrID = 0x1e // D
+		params.ScriptHashAddrID = 0x16 // 9, A
+		params.PrivateKeyID = 0x9e
+		params.HDPrivateKeyID = [4]byte{0x02, 0xfa, 0xc3, 0x98} // dgpv
+		params.HDPublicKeyID = [4]byte{0x02, 0xfa, 0xca, 0xfd}  // dgub
+		params.HDCoinType = 3
+	})
+	DOGETestNetParams = newForkParams(chaincfg.TestNet3Params, func(params *chaincfg.Params) {
+		params.Name = "dogecoin-testnet"
+		params.Net = 0xdcb7c1fc
+		params.DefaultPort = "44556"
+		params.GenesisHash = mustHash("bb0a78264637406b6360aad926284d544d7049f45189db5664f3c4d07350559e")
+		params.Bech32HRPSegwit = ""
+		params.PubKeyHashAddrID = 0x71 // n
+		params.ScriptHashAddrID = 0xc4 // 2
+		params.PrivateKeyID = 0xf1
+	})
+)
+
+// CashAddr prefix of chains using CashAddr
+var cashAddrPrefixes = map[wire.BitcoinNet]string{
+	BCHMainNetParams.Net: "bitcoincash",
+	BCHTestNetParams.Net: "bchtest",
+}
+
+// magic of signed message of chains not using bitcoin magic ( bitcoin cash keeps it )
+var messageMagics = map[wire.BitcoinNet]string{
+	LTCMainNetParams.Net:  "Litecoin Signed Message:\n",
+	LTCTestNetParams.Net:  "Litecoin Signed Message:\n",
+	DOGEMainNetParams.Net: "Dogecoin Signed Message:\n",
+	DOGETestNetParams.Net: "Dogecoin Signed Message:\n",
+}
+
+// chains using SIGHASH_FORKID replay protection
+var forkIDNets = map[wire.BitcoinNet]struct{}{
+	BCHMainNetParams.Net: {},
+	BCHTestNetParams.Net: {},
+}
+
+func init() {
+	// register bech32 prefix and address ids for btcutil.DecodeAddress
+	for _, params := range []*chaincfg.Params{&LTCMainNetParams, &LTCTestNetParams, &BCHMainNetParams, &BCHTestNetParams, &DOGEMainNetParams, &DOGETestNetParams} {
+		err := chaincfg.Register(params)
+		if err != nil && errors.Is(err, chaincfg.ErrDuplicateNet) == false {
+			panic("failed to register network | " + params.Name + " | " + err.Error())
+		}
+	}
+}
+
+func newForkParams(base chaincfg.Params, set func(params *chaincfg.Params)) chaincfg.Params {
+	params := base
+	params.GenesisBlock = nil
+	params.Checkpoints = nil
+	params.DNSSeeds = nil
+	set(&params)
+	return params
+}
+
+func mustHash(hash string) *chainhash.Hash {
+	h, err := chainhash.NewHashFromStr(hash)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+func cashAddrPrefix(params *chaincfg.Params) string {
+	return cashAddrPrefixes[params.Net]
+}
+
+func messageMagic(params *chaincfg.Params) string {
+	if magic, ok := messageMagics[params.Net]; ok == true {
+		return magic
+	}
+	return DEF_messageMagic
+}
+
+//--------------------------------------------------------------------------------//
+// fork id
+
+const (
+	DEF_sigHashForkID txscript.SigHashType = 0x40
+)
+
+func usesForkID(params *chaincfg.Params) bool {
+	_, ok := forkIDNets[params.Net]
+	return ok
+}
+
+// rawTxInForkIDSignature signs input with SIGHASH_ALL | SIGHASH_FORKID
+// digest is BIP143 of legacy script ( subScript : pkScript of P2PKH, redeem script of P2SH ) with fork id 0
+func rawTxInForkIDSignature(msgTx *wire.MsgTx, idx int, subScript []byte, amount int64, sigHashes *txscript.TxSigHashes, privKey *btcec.PrivateKey) (sig []byte, err error) {
+	hashType := txscript.SigHashAll | DEF_sigHashForkID
+	hash, err := txscript.CalcWitnessSigHash(subScript, sigHashes, hashType, msgTx, idx, amount)
+	if err != nil {
+		return nil, err
+	}
+	return append(ecdsa.Sign(privKey, hash).Serialize(), byte(hashType)), nil
+}
@@ -0,0 +1,179 @@
+package btc
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForkParams(t *testing.T) {
+	// BIP173 program on litecoin
+	addr, err := decodeAddress("ltc1qw508d6qejxtdg4y5r3zarvary0c5xw7kgmn4n9", &LTCMainNetParams)
+	require.NoError(t, err)
+	require.Equal(t, "751e76e8199196d454941c45d1b3a323f1433bd6", hex.EncodeToString(addr.ScriptAddress()))
+	_, err = decodeAddress("ltc1qw508d6qejxtdg4y5r3zarvary0c5xw7kgmn4n9", &chaincfg.MainNetParams)
+	require.Error(t, err)
+
+	// addresses of litecoin / dogecoin from btcutil and public vectors ( testnet P2PKH / P2SH ids are shared with bitcoin testnet )
+	for _, test := range []struct {
+		params   *chaincfg.Params
+		address  string
+		addrType AddressType
+		hash     string // hash160, empty if not checked
+	}{
+		{&LTCMainNetParams, "LM2WMpR1Rp6j3Sa59cMXMs1SPzj9eXpGc1", DEF_addressType_P2PKH, "13c60d8e68d7349f5b4ca362c3954b15045061b1"},
+		{&LTCMainNetParams, "MVcg9uEvtWuP5N6V48EHfEtbz48qR8TKZ9", DEF_addressType_P2SH, "ee34ac676bdaf6e370c8c820b948edfad3a873d8"},
+		{&LTCMainNetParams, "LVg2kJoFNg45Nbpy53h7Fe1wKyeXVRhMH9", DEF_addressType_P2PKH, ""},
+		{&LTCTestNetParams, "QVk4MvUu7Wb7tZ1wvAeiUvdF7wxhvpyLLK", DEF_addressType_P2SH, ""},
+		{&LTCTestNetParams, "mrX9vMRYLfVy1BnZbc5gZjuyaqH3ZW2ZHz", DEF_addressType_P2PKH, "78b316a08647d5b77283e512d3603f1f1c8de68f"},
+		{&DOGEMainNetParams, "DH5yaieqoZN36fDVciNyRueRGvGLR3mr7L", DEF_addressType_P2PKH, ""},
+		{&DOGETestNetParams, "nqNjvWut21qMKyZb4EPWBEUuVDSHuypVUa", DEF_addressType_P2PKH, ""},
+		{&DOGETestNetParams, "2MzQwSSnBHWHqSAqtTVQ6v47XtaisrJa1Vc", DEF_addressType_P2SH, ""},
+	} {
+		parsed, err := ParseAddress(test.address, test.params)
+		require.NoError(t, err, test.address)
+		require.Equal(t, test.address, parsed.Address)
+		require.Equal(t, test.addrType, parsed.Type, test.address)
+		if test.hash != "" {
+			addr, err := decodeAddress(test.address, test.params)
+			require.NoError(t, err)
+			require.Equal(t, test.hash, hex.EncodeToString(addr.ScriptAddress()))
+		}
+		if test.params.Net != LTCTestNetParams.Net && test.params.Net != DOGETestNetParams.Net {
+			_, err = decodeAddress(test.address, &chaincfg.MainNetParams)
+			require.Error(t, err, test.address)
+		}
+	}
+
+	// WIF of private key 1 ( generator point )
+	generator := "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	for _, test := range []struct {
+		params   *chaincfg.Params
+		wif      string
+		compress bool
+	}{
+		{&LTCMainNetParams, "T33ydQRKp4FCW5LCLLUB7deioUMoveiwekdwUwyfRDeGZm76aUjV", true},
+		{&LTCMainNetParams, "6u823ozcyt2rjPH8Z2ErsSXJB5PPQwK7VVTwwN4mxLBFrao69XQ", false},
+		{&DOGEMainNetParams, "QNcdLVw8fHkixm6NNyN6nVwxKek4u7qrioRbQmjxac5TVoTtZuot", true},
+	} {
+		wif, err := btcutil.DecodeWIF(test.wif)
+		require.NoError(t, err, test.wif)
+		require.True(t, wif.IsForNet(test.params), test.wif)
+		require.False(t, wif.IsForNet(&chaincfg.MainNetParams), test.wif)
+		require.Equal(t, test.compress, wif.CompressPubKey)
+		require.Equal(t, generator, hex.EncodeToString(wif.PrivKey.PubKey().SerializeCompressed()))
+
+		encoded, err := btcutil.NewWIF(wif.PrivKey, test.params, test.compress)
+		require.NoError(t, err)
+		require.Equal(t, test.wif, encoded.String())
+	}
+
+	// WIF of dogecoin testnet, re-encoded with params
+	wif, err := btcutil.DecodeWIF("ci5prbqz7jXyFPVWKkHhPq4a9N8Dag3TpeRfuqqC2Nfr7gSqx1fy")
+	require.NoError(t, err)
+	require.True(t, wif.IsForNet(&DOGETestNetParams))
+	encoded, err := btcutil.NewWIF(wif.PrivKey, &DOGETestNetParams, wif.CompressPubKey)
+	require.NoError(t, err)
+	require.Equal(t, "ci5prbqz7jXyFPVWKkHhPq4a9N8Dag3TpeRfuqqC2Nfr7gSqx1fy", encoded.String())
+
+	// CashAddr of bitcoin cash
+	privKey := newTestKey(t, 1, true).PrivKey
+	bchAddr, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(privKey.PubKey().SerializeCompressed()), &BCHMainNetParams)
+	require.NoError(t, err)
+	address := EncodeAddress(bchAddr, &BCHMainNetParams)
+	require.True(t, strings.HasPrefix(address, "bitcoincash:q"))
+	decoded, err := decodeAddress(address, &BCHMainNetParams)
+	require.NoError(t, err)
+	require.Equal(t, bchAddr.ScriptAddress(), decoded.ScriptAddress())
+}
+
+func TestRawTxSignForkID(t *testing.T) {
+	params := &BCHTestNetParams
+	wif := newTestKey(t, 1, true)
+	addr, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(wif.SerializePubKey()), params)
+	require.NoError(t, err)
+	pkScript, err := txscript.PayToAddrScript(addr)
+	require.NoError(t, err)
+
+	wifs := []*btcutil.WIF{newTestKey(t, 4, true), newTestKey(t, 5, true), newTestKey(t, 6, true)}
+	pubKeys := make([]string, 0, len(wifs))
+	for _, wif := range wifs {
+		pubKeys = append(pubKeys, hex.EncodeToString(wif.SerializePubKey()))
+	}
+	multiSig, err := NewMultiSig(params, DEF_multiSig_P2SH, 2, pubKeys, true)
+	require.NoError(t, err)
+	multiSigScript, err := multiSig.PkScript()
+	require.NoError(t, err)
+
+	rawTx := &RawTx{}
+	require.NoError(t, rawTx.Init(&Client{params: params}, EncodeAddress(addr, params), 0))
+	require.NoError(t, rawTx.AddFrom(wif.String(), EncodeAddress(addr, params)))
+	require.NoError(t, rawTx.AddFromMultiSig(multiSig, wifs[0].String(), wifs[2].String()))
+
+	hash, err := chainhash.NewHashFromStr(DEF_txid_dummy)
+	require.NoError(t, err)
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, 0), nil, nil))
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, 1), nil, nil))
+	msgTx.AddTxOut(wire.NewTxOut(290000, pkScript))
+	utxos := []*utxo{
+		{Txid: DEF_txid_dummy, Vout: 0, FromAmount: 0.001, ScriptPubKey: hex.EncodeToString(pkScript)},
+		{Txid: DEF_txid_dummy, Vout: 1, FromAmount: 0.002, ScriptPubKey: hex.EncodeToString(multiSigScript)},
+	}
+
+	msgTxSigned, err := rawTx.sign(context.Background(), msgTx, utxos)
+	require.NoError(t, err)
+
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	fetcher.AddPrevOut(msgTx.TxIn[0].PreviousOutPoint, wire.NewTxOut(100000, pkScript))
+	fetcher.AddPrevOut(msgTx.TxIn[1].PreviousOutPoint, wire.NewTxOut(200000, multiSigScript))
+	sigHashes := txscript.NewTxSigHashes(msgTxSigned, fetcher)
+	verify := func(idx int, subScript []byte, amount int64, sig []byte, pubKey []byte) bool {
+		require.Equal(t, byte(0x41), sig[len(sig)-1])
+		hash, err := txscript.CalcWitnessSigHash(subScript, sigHashes, txscript.SigHashAll|DEF_sigHashForkID, msgTxSigned, idx, amount)
+		require.NoError(t, err)
+		signature, err := ecdsa.ParseDERSignature(sig[:len(sig)-1])
+		require.NoError(t, err)
+		key, err := btcutil.NewAddressPubKey(pubKey, params)
+		require.NoError(t, err)
+		return signature.Verify(hash, key.PubKey())
+	}
+
+	// P2PKH : <sig> <pubkey>
+	pushes, err := txscript.PushedData(msgTxSigned.TxIn[0].SignatureScript)
+	require.NoError(t, err)
+	require.Len(t, pushes, 2)
+	require.True(t, verify(0, pkScript, 100000, pushes[0], pushes[1]))
+
+	// P2SH multisig : OP_0 <sig> <sig> <redeem script>, signatures in key order
+	pushes, err = txscript.PushedData(msgTxSigned.TxIn[1].SignatureScript)
+	require.NoError(t, err)
+	require.Len(t, pushes, 4)
+	require.Equal(t, multiSig.RedeemScript, pushes[3])
+	signed := 0
+	for _, pubKey := range multiSig.PubKeys {
+		for _, sig := range pushes[1:3] {
+			if verify(1, multiSig.Script, 200000, sig, pubKey) == true {
+				signed++
+			}
+		}
+	}
+	require.Equal(t, 2, signed)
+
+	// offline signing is not supported
+	tx := &OfflineTx{}
+	tx.Init(params)
+	require.NoError(t, tx.AddFrom(newTestUnspent(t, addr, 0, 100000)))
+	require.NoError(t, tx.AddTo(EncodeAddress(addr, params), 90000))
+	_, err = tx.Sign()
+	require.Error(t, err)
+}
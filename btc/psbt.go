@@ -0,0 +1,481 @@
+package btc
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// PSBT is partially signed bitcoin transaction ( BIP174 ) to pass around hardware wallets and co-signers
+type PSBT struct {
+	packet *psbt.Packet
+}
+
+// NewPSBT creates PSBT of unsigned tx ( utxo of inputs must be added with updater of Packet )
+func NewPSBT(msgTx *wire.MsgTx) (p *PSBT, err error) {
+	packet, err := psbt.NewFromUnsignedTx(msgTx)
+	if err != nil {
+		return nil, err
+	}
+	return &PSBT{packet: packet}, nil
+}
+
+func DecodePSBT(b64 string) (p *PSBT, err error) {
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(b64), true)
+	if err != nil {
+		return nil, err
+	}
+	return &PSBT{packet: packet}, nil
+}
+
+func (t *PSBT) Encode() (b64 string, err error) {
+	return t.packet.B64Encode()
+}
+
+func (t *PSBT) Packet() *psbt.Packet {
+	return t.packet
+}
+
+func (t *PSBT) Fee() (fee btcutil.Amount, err error) {
+	return t.packet.GetTxFee()
+}
+
+// IsComplete returns true if every input is finalized
+func (t *PSBT) IsComplete() bool {
+	return t.packet.IsComplete()
+}
+
+//--------------------------------------------------------------------------------//
+// create
+
+// KeyOrigin is BIP32 origin of key ( HDWallet.KeyOrigin ), hardware wallets find signing key and verify change by it
+type KeyOrigin struct {
+	PubKey      []byte   // compressed public key
+	Fingerprint uint32   // master key fingerprint in PSBT byte order
+	Path        []uint32 // m / purpose' / coin_type' / account' / change / index
+	Taproot     bool     // key of BIP86 P2TR output ( internal key )
+}
+
+// AddKeyOrigin adds origin of key of wallet address, BuildPSBT writes it to inputs and outputs paying to the address
+func (t *RawTx) AddKeyOrigin(wallet *HDWallet, change bool, index uint32) (err error) {
+	if wallet.params.Net != t.client.params.Net {
+		return fmt.Errorf("wallet is not for network | %s", t.client.params.Name)
+	}
+	origin, err := wallet.KeyOrigin(change, index)
+	if err != nil {
+		return err
+	}
+	address, err := wallet.Address(change, index)
+	if err != nil {
+		return err
+	}
+	addr, err := decodeAddress(address, t.client.params)
+	if err != nil {
+		return err
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return err
+	}
+	t.keyOrigins[hex.EncodeToString(pkScript)] = origin
+	return nil
+}
+
+// BuildPSBT builds funded tx like Build and returns it as PSBT with utxo of every input
+// legacy inputs need previous tx ( getrawtransaction ) to be signed
+// key origin of inputs and outputs is added if known ( AddKeyOrigin )
+func (t *RawTx) BuildPSBT(ctx context.Context) (p *PSBT, err error) {
+	msgTx, err := t.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p, err = NewPSBT(msgTx)
+	if err != nil {
+		return nil, err
+	}
+	updater, err := psbt.NewUpdater(p.packet)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, utxo := range t.utxos {
+		pkScript, redeemScript, witnessScript, err := utxo.scripts()
+		if err != nil {
+			return nil, err
+		}
+		amount, err := btcutil.NewAmount(utxo.FromAmount)
+		if err != nil {
+			return nil, err
+		}
+
+		isWitness := txscript.IsWitnessProgram(pkScript) || txscript.IsWitnessProgram(redeemScript)
+		if isWitness == true {
+			err = updater.AddInWitnessUtxo(wire.NewTxOut(int64(amount), pkScript), i)
+		} else {
+			var msgTxPrev *wire.MsgTx
+			msgTxPrev, err = t.client.GetRawTx(ctx, utxo.Txid)
+			if err != nil {
+				return nil, err
+			}
+			err = updater.AddInNonWitnessUtxo(msgTxPrev, i)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(redeemScript) > 0 {
+			err = updater.AddInRedeemScript(redeemScript, i)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(witnessScript) > 0 {
+			err = updater.AddInWitnessScript(witnessScript, i)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	t.addKeyOrigins(p)
+	return p, nil
+}
+
+// addKeyOrigins writes origin of keys to inputs and outputs of known pkScript ( BIP174, BIP371 for P2TR )
+func (t *RawTx) addKeyOrigins(p *PSBT) {
+	for i := range p.packet.Inputs {
+		pInput := &p.packet.Inputs[i]
+		prevOut, err := p.prevOut(i)
+		if err != nil {
+			continue
+		}
+		origin, ok := t.keyOrigins[hex.EncodeToString(prevOut.PkScript)]
+		if ok == false {
+			continue
+		}
+		if origin.Taproot == true {
+			pInput.TaprootInternalKey, pInput.TaprootBip32Derivation = origin.taproot()
+		} else {
+			pInput.Bip32Derivation = combineBip32Derivation(pInput.Bip32Derivation, []*psbt.Bip32Derivation{origin.bip32()})
+		}
+	}
+	for i, txOut := range p.packet.UnsignedTx.TxOut {
+		pOutput := &p.packet.Outputs[i]
+		origin, ok := t.keyOrigins[hex.EncodeToString(txOut.PkScript)]
+		if ok == false {
+			continue
+		}
+		if origin.Taproot == true {
+			pOutput.TaprootInternalKey, pOutput.TaprootBip32Derivation = origin.taproot()
+		} else {
+			pOutput.Bip32Derivation = combineBip32Derivation(pOutput.Bip32Derivation, []*psbt.Bip32Derivation{origin.bip32()})
+		}
+	}
+}
+
+func (t *KeyOrigin) bip32() *psbt.Bip32Derivation {
+	return &psbt.Bip32Derivation{
+		PubKey:               t.PubKey,
+		MasterKeyFingerprint: t.Fingerprint,
+		Bip32Path:            t.Path,
+	}
+}
+
+// taproot returns x only internal key and its derivation ( key path only, no leaf hashes )
+func (t *KeyOrigin) taproot() (internalKey []byte, derivations []*psbt.TaprootBip32Derivation) {
+	internalKey = t.PubKey[1:]
+	return internalKey, []*psbt.TaprootBip32Derivation{{
+		XOnlyPubKey:          internalKey,
+		MasterKeyFingerprint: t.Fingerprint,
+		Bip32Path:            t.Path,
+	}}
+}
+
+//--------------------------------------------------------------------------------//
+// sign
+
+// Sign adds partial signature of every input spendable by private keys ( SIGHASH_ALL )
+// supports P2PKH, P2WPKH, P2SH-P2WPKH, P2TR key path ( SIGHASH_DEFAULT ) and multisig in P2SH, P2SH-P2WSH and P2WSH
+func (t *PSBT) Sign(privKeys ...string) (signed int, err error) {
+	wifs := make([]*btcutil.WIF, 0, len(privKeys))
+	for _, privKey := range privKeys {
+		wif, err := btcutil.DecodeWIF(privKey)
+		if err != nil {
+			return 0, err
+		}
+		wifs = append(wifs, wif)
+	}
+
+	msgTx := t.packet.UnsignedTx
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for i, txIn := range msgTx.TxIn {
+		prevOut, err := t.prevOut(i)
+		if err != nil {
+			return 0, err
+		}
+		fetcher.AddPrevOut(txIn.PreviousOutPoint, prevOut)
+	}
+	sigHashes := txscript.NewTxSigHashes(msgTx, fetcher)
+
+	updater, err := psbt.NewUpdater(t.packet)
+	if err != nil {
+		return 0, err
+	}
+	for i := range msgTx.TxIn {
+		for _, wif := range wifs {
+			ok, err := t.signInput(updater, i, wif, fetcher.FetchPrevOutput(msgTx.TxIn[i].PreviousOutPoint), sigHashes)
+			if err != nil {
+				return signed, fmt.Errorf("sign input %d failed | %w", i, err)
+			}
+			if ok == true {
+				signed++
+			}
+		}
+	}
+	return signed, nil
+}
+
+func (t *PSBT) prevOut(idx int) (prevOut *wire.TxOut, err error) {
+	pInput := t.packet.Inputs[idx]
+	if pInput.WitnessUtxo != nil {
+		return pInput.WitnessUtxo, nil
+	}
+	if pInput.NonWitnessUtxo != nil {
+		outIdx := t.packet.UnsignedTx.TxIn[idx].PreviousOutPoint.Index
+		if int(outIdx) < len(pInput.NonWitnessUtxo.TxOut) {
+			return pInput.NonWitnessUtxo.TxOut[outIdx], nil
+		}
+	}
+	return nil, fmt.Errorf("utxo of input %d is missing", idx)
+}
+
+// signInput returns false if key is not for input or already signed
+func (t *PSBT) signInput(updater *psbt.Updater, idx int, wif *btcutil.WIF, prevOut *wire.TxOut, sigHashes *txscript.TxSigHashes) (ok bool, err error) {
+	pInput := &t.packet.Inputs[idx]
+	if pInput.FinalScriptSig != nil || pInput.FinalScriptWitness != nil || pInput.TaprootKeySpendSig != nil {
+		return false, nil
+	}
+	pubKey := wif.SerializePubKey()
+	for _, partialSig := range pInput.PartialSigs {
+		if bytes.Equal(partialSig.PubKey, pubKey) == true {
+			return false, nil
+		}
+	}
+
+	msgTx := t.packet.UnsignedTx
+	pkScript := prevOut.PkScript
+	pubKeyHash := btcutil.Hash160(pubKey)
+
+	var sig, redeemScript, witnessScript []byte
+	switch txscript.GetScriptClass(pkScript) {
+	case txscript.WitnessV1TaprootTy:
+		// schnorr signature goes to key spend field, not partial sigs
+		internalKey := wif.PrivKey.PubKey()
+		if bytes.Equal(pkScript[2:34], taprootOutputKey(internalKey)) == false {
+			return false, nil
+		}
+		sig, err = txscript.RawTxInTaprootSignature(msgTx, sigHashes, idx, prevOut.Value, pkScript, nil, txscript.SigHashDefault, wif.PrivKey)
+		if err != nil {
+			return false, err
+		}
+		pInput.TaprootKeySpendSig = sig
+		pInput.TaprootInternalKey = schnorr.SerializePubKey(internalKey)
+		return true, nil
+
+	case txscript.PubKeyHashTy:
+		if bytes.Equal(pkScript[3:23], pubKeyHash) == false {
+			return false, nil
+		}
+		sig, err = txscript.RawTxInSignature(msgTx, idx, pkScript, txscript.SigHashAll, wif.PrivKey)
+
+	case txscript.WitnessV0PubKeyHashTy:
+		if bytes.Equal(pkScript[2:22], pubKeyHash) == false {
+			return false, nil
+		}
+		sig, err = txscript.RawTxInWitnessSignature(msgTx, sigHashes, idx, prevOut.Value, pkScript, txscript.SigHashAll, wif.PrivKey)
+
+	case txscript.WitnessV0ScriptHashTy:
+		witnessScript = pInput.WitnessScript
+		if containsPubKey(witnessScript, pubKey) == false {
+			return false, nil
+		}
+		sig, err = txscript.RawTxInWitnessSignature(msgTx, sigHashes, idx, prevOut.Value, witnessScript, txscript.SigHashAll, wif.PrivKey)
+
+	case txscript.ScriptHashTy:
+		redeemScript = pInput.RedeemScript
+		if redeemScript == nil && wif.CompressPubKey == true {
+			// P2SH-P2WPKH of key
+			redeemScript = p2wpkhScript(pubKeyHash)
+		}
+		if bytes.Equal(btcutil.Hash160(redeemScript), pkScript[2:22]) == false {
+			return false, nil
+		}
+		switch txscript.GetScriptClass(redeemScript) {
+		case txscript.WitnessV0PubKeyHashTy:
+			if bytes.Equal(redeemScript[2:22], pubKeyHash) == false {
+				return false, nil
+			}
+			sig, err = txscript.RawTxInWitnessSignature(msgTx, sigHashes, idx, prevOut.Value, redeemScript, txscript.SigHashAll, wif.PrivKey)
+		case txscript.WitnessV0ScriptHashTy:
+			witnessScript = pInput.WitnessScript
+			if containsPubKey(witnessScript, pubKey) == false {
+				return false, nil
+			}
+			sig, err = txscript.RawTxInWitnessSignature(msgTx, sigHashes, idx, prevOut.Value, witnessScript, txscript.SigHashAll, wif.PrivKey)
+		default:
+			if containsPubKey(redeemScript, pubKey) == false {
+				return false, nil
+			}
+			sig, err = txscript.RawTxInSignature(msgTx, idx, redeemScript, txscript.SigHashAll, wif.PrivKey)
+		}
+
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	_, err = updater.Sign(idx, sig, pubKey, redeemScript, witnessScript)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func containsPubKey(script, pubKey []byte) bool {
+	if len(script) == 0 {
+		return false
+	}
+	pushes, err := txscript.PushedData(script)
+	if err != nil {
+		return false
+	}
+	for _, push := range pushes {
+		if bytes.Equal(push, pubKey) == true {
+			return true
+		}
+	}
+	return false
+}
+
+//--------------------------------------------------------------------------------//
+// combine, finalize, extract
+
+// Combine merges signatures and fields of other PSBTs of the same unsigned tx
+func (t *PSBT) Combine(others ...*PSBT) (err error) {
+	txHash := t.packet.UnsignedTx.TxHash()
+	for _, other := range others {
+		if other.packet.UnsignedTx.TxHash() != txHash {
+			return fmt.Errorf("unsigned tx mismatch | %s | %s", txHash, other.packet.UnsignedTx.TxHash())
+		}
+		for i, pInput := range other.packet.Inputs {
+			combineInput(&t.packet.Inputs[i], &pInput)
+		}
+		for i, pOutput := range other.packet.Outputs {
+			combineOutput(&t.packet.Outputs[i], &pOutput)
+		}
+	}
+	return t.packet.SanityCheck()
+}
+
+func combineInput(dst, src *psbt.PInput) {
+	if dst.NonWitnessUtxo == nil {
+		dst.NonWitnessUtxo = src.NonWitnessUtxo
+	}
+	if dst.WitnessUtxo == nil {
+		dst.WitnessUtxo = src.WitnessUtxo
+	}
+	if dst.SighashType == 0 {
+		dst.SighashType = src.SighashType
+	}
+	if dst.RedeemScript == nil {
+		dst.RedeemScript = src.RedeemScript
+	}
+	if dst.WitnessScript == nil {
+		dst.WitnessScript = src.WitnessScript
+	}
+	if dst.FinalScriptSig == nil {
+		dst.FinalScriptSig = src.FinalScriptSig
+	}
+	if dst.FinalScriptWitness == nil {
+		dst.FinalScriptWitness = src.FinalScriptWitness
+	}
+	if dst.TaprootKeySpendSig == nil {
+		dst.TaprootKeySpendSig = src.TaprootKeySpendSig
+	}
+	if dst.TaprootInternalKey == nil {
+		dst.TaprootInternalKey = src.TaprootInternalKey
+	}
+	if dst.TaprootMerkleRoot == nil {
+		dst.TaprootMerkleRoot = src.TaprootMerkleRoot
+	}
+	if dst.TaprootBip32Derivation == nil {
+		dst.TaprootBip32Derivation = src.TaprootBip32Derivation
+	}
+
+	for _, partialSig := range src.PartialSigs {
+		exist := false
+		for _, partialSigDst := range dst.PartialSigs {
+			if bytes.Equal(partialSigDst.PubKey, partialSig.PubKey) == true {
+				exist = true
+				break
+			}
+		}
+		if exist == false {
+			dst.PartialSigs = append(dst.PartialSigs, partialSig)
+		}
+	}
+	dst.Bip32Derivation = combineBip32Derivation(dst.Bip32Derivation, src.Bip32Derivation)
+}
+
+func combineOutput(dst, src *psbt.POutput) {
+	if dst.RedeemScript == nil {
+		dst.RedeemScript = src.RedeemScript
+	}
+	if dst.WitnessScript == nil {
+		dst.WitnessScript = src.WitnessScript
+	}
+	if dst.TaprootInternalKey == nil {
+		dst.TaprootInternalKey = src.TaprootInternalKey
+	}
+	if dst.TaprootBip32Derivation == nil {
+		dst.TaprootBip32Derivation = src.TaprootBip32Derivation
+	}
+	dst.Bip32Derivation = combineBip32Derivation(dst.Bip32Derivation, src.Bip32Derivation)
+}
+
+func combineBip32Derivation(dst, src []*psbt.Bip32Derivation) []*psbt.Bip32Derivation {
+	for _, derivation := range src {
+		exist := false
+		for _, derivationDst := range dst {
+			if bytes.Equal(derivationDst.PubKey, derivation.PubKey) == true {
+				exist = true
+				break
+			}
+		}
+		if exist == false {
+			dst = append(dst, derivation)
+		}
+	}
+	return dst
+}
+
+// Finalize builds final scriptSig / witness of every input ( error if signatures are not enough )
+func (t *PSBT) Finalize() (err error) {
+	return psbt.MaybeFinalizeAll(t.packet)
+}
+
+// Extract returns signed tx of finalized PSBT
+func (t *PSBT) Extract() (msgTxSigned *wire.MsgTx, err error) {
+	if t.packet.IsComplete() == false {
+		return nil, fmt.Errorf("psbt is not finalized")
+	}
+	return psbt.Extract(t.packet)
+}
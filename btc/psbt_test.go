@@ -0,0 +1,226 @@
+package btc
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPSBT(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	wifLegacy := newTestKey(t, 1, true)
+	wifSegwit := newTestKey(t, 2, true)
+	wifNested := newTestKey(t, 3, true)
+	wifMultiSig := []*btcutil.WIF{newTestKey(t, 4, true), newTestKey(t, 5, true), newTestKey(t, 6, true)}
+
+	addrP2PKH, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(wifLegacy.SerializePubKey()), params)
+	require.NoError(t, err)
+	p2pkhScript, err := txscript.PayToAddrScript(addrP2PKH)
+	require.NoError(t, err)
+	p2wpkhScriptSegwit := p2wpkhScript(btcutil.Hash160(wifSegwit.SerializePubKey()))
+	addrP2SH, err := btcutil.NewAddressScriptHash(p2wpkhScript(btcutil.Hash160(wifNested.SerializePubKey())), params)
+	require.NoError(t, err)
+	p2shScript, err := txscript.PayToAddrScript(addrP2SH)
+	require.NoError(t, err)
+
+	pubKeys := make([]*btcutil.AddressPubKey, 0, len(wifMultiSig))
+	for _, wif := range wifMultiSig {
+		pubKey, err := btcutil.NewAddressPubKey(wif.SerializePubKey(), params)
+		require.NoError(t, err)
+		pubKeys = append(pubKeys, pubKey)
+	}
+	witnessScript, err := txscript.MultiSigScript(pubKeys, 2)
+	require.NoError(t, err)
+	witnessScriptHash := sha256.Sum256(witnessScript)
+	addrP2WSH, err := btcutil.NewAddressWitnessScriptHash(witnessScriptHash[:], params)
+	require.NoError(t, err)
+	p2wshScript, err := txscript.PayToAddrScript(addrP2WSH)
+	require.NoError(t, err)
+
+	// previous tx of legacy input
+	msgTxPrev := wire.NewMsgTx(wire.TxVersion)
+	msgTxPrev.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, 0), nil, nil))
+	msgTxPrev.AddTxOut(wire.NewTxOut(100000, p2pkhScript))
+	hashPrev := msgTxPrev.TxHash()
+	hashDummy, err := chainhash.NewHashFromStr(DEF_txid_dummy)
+	require.NoError(t, err)
+
+	prevOuts := []*wire.TxOut{
+		msgTxPrev.TxOut[0],
+		wire.NewTxOut(200000, p2wpkhScriptSegwit),
+		wire.NewTxOut(300000, p2shScript),
+		wire.NewTxOut(400000, p2wshScript),
+	}
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&hashPrev, 0), nil, nil))
+	for i := uint32(1); i <= 3; i++ {
+		msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hashDummy, i), nil, nil))
+	}
+	msgTx.AddTxOut(wire.NewTxOut(990000, p2wpkhScriptSegwit))
+
+	p, err := NewPSBT(msgTx)
+	require.NoError(t, err)
+	updater, err := psbt.NewUpdater(p.Packet())
+	require.NoError(t, err)
+	require.NoError(t, updater.AddInNonWitnessUtxo(msgTxPrev, 0))
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, updater.AddInWitnessUtxo(prevOuts[i], i))
+	}
+	require.NoError(t, updater.AddInWitnessScript(witnessScript, 3))
+	fee, err := p.Fee()
+	require.NoError(t, err)
+	require.Equal(t, btcutil.Amount(10000), fee)
+
+	// export to co-signer
+	b64, err := p.Encode()
+	require.NoError(t, err)
+	pCoSigner, err := DecodePSBT(b64)
+	require.NoError(t, err)
+
+	// single sig keys + first multisig key
+	signed, err := p.Sign(wifLegacy.String(), wifSegwit.String(), wifNested.String(), wifMultiSig[0].String())
+	require.NoError(t, err)
+	require.Equal(t, 4, signed)
+	require.Error(t, p.Finalize()) // multisig needs 2 signatures
+
+	// second multisig key on co-signer
+	signed, err = pCoSigner.Sign(wifMultiSig[2].String())
+	require.NoError(t, err)
+	require.Equal(t, 1, signed)
+
+	require.NoError(t, p.Combine(pCoSigner))
+	require.NoError(t, p.Finalize())
+	require.True(t, p.IsComplete())
+	msgTxSigned, err := p.Extract()
+	require.NoError(t, err)
+
+	// verify scripts
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for i, txIn := range msgTxSigned.TxIn {
+		fetcher.AddPrevOut(txIn.PreviousOutPoint, prevOuts[i])
+	}
+	sigHashes := txscript.NewTxSigHashes(msgTxSigned, fetcher)
+	for i, prevOut := range prevOuts {
+		engine, err := txscript.NewEngine(prevOut.PkScript, msgTxSigned, i, txscript.StandardVerifyFlags, nil, sigHashes, prevOut.Value, fetcher)
+		require.NoError(t, err)
+		require.NoError(t, engine.Execute())
+	}
+
+	// psbt of other tx
+	msgTxOther := msgTx.Copy()
+	msgTxOther.TxOut[0].Value--
+	pOther, err := NewPSBT(msgTxOther)
+	require.NoError(t, err)
+	require.Error(t, p.Combine(pOther))
+}
+
+func TestPSBTKeyOrigin(t *testing.T) {
+	client, _ := newTestRPCServer(t, map[string]string{})
+	params := client.params
+	seed, err := hex.DecodeString(DEF_seed_test)
+	require.NoError(t, err)
+
+	// BIP86 vector, internal key of m/86'/0'/0'/0/0 of master 73c5da0a
+	walletMain, err := NewHDWalletFromSeed(&chaincfg.MainNetParams, DEF_purpose_BIP86, seed, 0)
+	require.NoError(t, err)
+	origin, err := walletMain.KeyOrigin(false, 0)
+	require.NoError(t, err)
+	require.Equal(t, "cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115", hex.EncodeToString(origin.PubKey[1:]))
+	fingerprint := make([]byte, 4)
+	binary.LittleEndian.PutUint32(fingerprint, origin.Fingerprint)
+	require.Equal(t, "73c5da0a", hex.EncodeToString(fingerprint))
+	require.Equal(t, []uint32{0x80000000 + 86, 0x80000000, 0x80000000, 0, 0}, origin.Path)
+
+	// watch only wallet of account key has no origin
+	xpub, err := walletMain.AccountKey()
+	require.NoError(t, err)
+	watchOnly, err := NewHDWallet(&chaincfg.MainNetParams, DEF_purpose_BIP86, xpub, 0)
+	require.NoError(t, err)
+	_, err = watchOnly.KeyOrigin(false, 0)
+	require.Error(t, err)
+
+	walletTR, err := NewHDWalletFromSeed(params, DEF_purpose_BIP86, seed, 0)
+	require.NoError(t, err)
+	walletWPKH, err := NewHDWalletFromSeed(params, DEF_purpose_BIP84, seed, 0)
+	require.NoError(t, err)
+	rawTx := &RawTx{}
+	require.NoError(t, rawTx.Init(client, "bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080", 0))
+	require.NoError(t, rawTx.AddKeyOrigin(walletTR, false, 0))
+	require.NoError(t, rawTx.AddKeyOrigin(walletWPKH, true, 3))
+	require.Error(t, rawTx.AddKeyOrigin(walletMain, false, 0)) // other network
+
+	pkScripts := make([][]byte, 0, 2)
+	for _, key := range []struct {
+		wallet *HDWallet
+		change bool
+		index  uint32
+	}{
+		{walletTR, false, 0},
+		{walletWPKH, true, 3},
+	} {
+		address, err := key.wallet.Address(key.change, key.index)
+		require.NoError(t, err)
+		addr, err := btcutil.DecodeAddress(address, params)
+		require.NoError(t, err)
+		pkScript, err := txscript.PayToAddrScript(addr)
+		require.NoError(t, err)
+		pkScripts = append(pkScripts, pkScript)
+	}
+
+	// P2TR input of wallet, change output to P2WPKH of wallet
+	hashDummy, err := chainhash.NewHashFromStr(DEF_txid_dummy)
+	require.NoError(t, err)
+	prevOut := wire.NewTxOut(100000, pkScripts[0])
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hashDummy, 0), nil, nil))
+	msgTx.AddTxOut(wire.NewTxOut(99000, pkScripts[1]))
+	p, err := NewPSBT(msgTx)
+	require.NoError(t, err)
+	updater, err := psbt.NewUpdater(p.Packet())
+	require.NoError(t, err)
+	require.NoError(t, updater.AddInWitnessUtxo(prevOut, 0))
+	rawTx.addKeyOrigins(p)
+
+	// origins survive encoding
+	b64, err := p.Encode()
+	require.NoError(t, err)
+	p, err = DecodePSBT(b64)
+	require.NoError(t, err)
+	originTR, err := walletTR.KeyOrigin(false, 0)
+	require.NoError(t, err)
+	pInput := p.Packet().Inputs[0]
+	require.Equal(t, originTR.PubKey[1:], pInput.TaprootInternalKey)
+	require.Len(t, pInput.TaprootBip32Derivation, 1)
+	require.Equal(t, originTR.Path, pInput.TaprootBip32Derivation[0].Bip32Path)
+	require.Equal(t, originTR.Fingerprint, pInput.TaprootBip32Derivation[0].MasterKeyFingerprint)
+	originWPKH, err := walletWPKH.KeyOrigin(true, 3)
+	require.NoError(t, err)
+	pOutput := p.Packet().Outputs[0]
+	require.Len(t, pOutput.Bip32Derivation, 1)
+	require.Equal(t, originWPKH.PubKey, pOutput.Bip32Derivation[0].PubKey)
+	require.Equal(t, originWPKH.Path, pOutput.Bip32Derivation[0].Bip32Path)
+
+	// sign, finalize and verify key path spend
+	privKey, err := walletTR.PrivKey(false, 0)
+	require.NoError(t, err)
+	signed, err := p.Sign(privKey)
+	require.NoError(t, err)
+	require.Equal(t, 1, signed)
+	require.NoError(t, p.Finalize())
+	msgTxSigned, err := p.Extract()
+	require.NoError(t, err)
+	fetcher := txscript.NewCannedPrevOutputFetcher(prevOut.PkScript, prevOut.Value)
+	sigHashes := txscript.NewTxSigHashes(msgTxSigned, fetcher)
+	engine, err := txscript.NewEngine(prevOut.PkScript, msgTxSigned, 0, txscript.StandardVerifyFlags, nil, sigHashes, prevOut.Value, fetcher)
+	require.NoError(t, err)
+	require.NoError(t, engine.Execute())
+}
@@ -1,46 +1,92 @@
 package btc
 
 import (
+	"bytes"
+	"context"
+	"encoding/hex"
 	"fmt"
-	"math"
 	"strings"
 
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 )
 
+const (
+	DEF_maxDataSize    = txscript.MaxDataCarrierSize // bytes of OP_RETURN data ( -datacarriersize 83 of bitcoin core, script included )
+	DEF_maxDataOutputs = 1                           // more OP_RETURN outputs are not relayed by default
+)
+
 // transfer without wallet support ( use privkey only )
 type RawTx struct {
 	client      *Client
 	balanceAddr btcutil.Address
-	fee         btcutil.Amount
+	feeRate     FeeRate
 
 	fromPrivKeys []string
 	fromAddrs    []btcutil.Address
 	toAmounts    map[btcutil.Address]btcutil.Amount
+	toData       [][]byte // pkScript of OP_RETURN outputs
+
+	multiSigs    map[string]*MultiSig       // key : hex pkScript
+	multiSigKeys map[string][]*btcutil.WIF // key : hex pkScript
+
+	timeLocks    map[string]*TimeLock    // key : hex pkScript
+	timeLockKeys map[string]*btcutil.WIF // key : hex pkScript
+
+	keyOrigins map[string]*KeyOrigin // key : hex pkScript, written to PSBT
+
+	coinSelector CoinSelector
+	backend      Backend        // utxo of addresses out of wallet, scantxoutset if nil
+	dustLimit    btcutil.Amount // change under dust limit is left as fee
+	rbf          bool           // signal BIP125 replaceability
+
+	lockTime  uint32                   // nLockTime, raised by CLTV utxo
+	sequences map[wire.OutPoint]uint32 // nSequence of input, CSV utxo needs at least its lock
+
+	utxos []*utxo // filled by Build, used by Sign
 }
 
+// fee is btc/kB ( bitcoin core unit ), use SetFeeRate for sat/vB
 func (t *RawTx) Init(client *Client, balanceAddr string, fee float64) (err error) {
 	t.client = client
 	t.fromPrivKeys = make([]string, 0, 10)
 	t.fromAddrs = make([]btcutil.Address, 0, 10)
 	t.toAmounts = make(map[btcutil.Address]btcutil.Amount)
-
-	t.balanceAddr, err = btcutil.DecodeAddress(balanceAddr, t.client.params)
+	t.multiSigs = make(map[string]*MultiSig)
+	t.multiSigKeys = make(map[string][]*btcutil.WIF)
+	t.timeLocks = make(map[string]*TimeLock)
+	t.timeLockKeys = make(map[string]*btcutil.WIF)
+	t.keyOrigins = make(map[string]*KeyOrigin)
+	t.sequences = make(map[wire.OutPoint]uint32)
+	t.coinSelector = &BranchAndBoundSelector{Fallback: &KnapsackSelector{}}
+	t.dustLimit = DEF_dustLimit
+
+	t.balanceAddr, err = decodeAddress(balanceAddr, t.client.params)
 	if err != nil {
 		return err
 	}
-	t.fee, err = btcutil.NewAmount(fee)
+	feeBTCPerKB, err := btcutil.NewAmount(fee)
 	if err != nil {
 		return err
 	}
+	return t.SetFeeRate(NewFeeRateFromBTCPerKB(feeBTCPerKB))
+}
+
+func (t *RawTx) SetFeeRate(feeRate FeeRate) (err error) {
+	if feeRate < 0 {
+		return fmt.Errorf("invalid fee rate | %v", feeRate)
+	}
+	t.feeRate = feeRate
 	return nil
 }
 
+// AddFrom adds from address with its private key
+// P2PKH, P2WPKH, P2SH-P2WPKH and P2TR ( key path ) utxo are signed locally, private key is not sent to node
 func (t *RawTx) AddFrom(privKey, address string) (err error) {
-	btcAddr, err := btcutil.DecodeAddress(address, t.client.params)
+	btcAddr, err := decodeAddress(address, t.client.params)
 	if err != nil {
 		return err
 	}
@@ -50,8 +96,62 @@ func (t *RawTx) AddFrom(privKey, address string) (err error) {
 	return nil
 }
 
+// AddFromMultiSig adds multisig address as from address
+// utxo of multisig are signed locally with privKeys ( at least required count of keys )
+func (t *RawTx) AddFromMultiSig(multiSig *MultiSig, privKeys ...string) (err error) {
+	if multiSig.Address.IsForNet(t.client.params) == false {
+		return fmt.Errorf("multisig address is not for network | %s", t.client.params.Name)
+	}
+	pkScript, err := multiSig.PkScript()
+	if err != nil {
+		return err
+	}
+	wifs := make([]*btcutil.WIF, 0, len(privKeys))
+	for _, privKey := range privKeys {
+		wif, err := btcutil.DecodeWIF(privKey)
+		if err != nil {
+			return err
+		}
+		if multiSig.indexOf(wif.SerializePubKey()) == -1 {
+			return fmt.Errorf("private key is not in multisig | %s", multiSig.Address)
+		}
+		wifs = append(wifs, wif)
+	}
+
+	key := hex.EncodeToString(pkScript)
+	t.multiSigs[key] = multiSig
+	t.multiSigKeys[key] = wifs
+	t.fromAddrs = append(t.fromAddrs, multiSig.Address)
+	return nil
+}
+
+// AddFromTimeLock adds time locked address as from address
+// utxo is spent only after it is mature, nLockTime and nSequence are set by Build
+func (t *RawTx) AddFromTimeLock(timeLock *TimeLock, privKey string) (err error) {
+	if timeLock.Address.IsForNet(t.client.params) == false {
+		return fmt.Errorf("time lock address is not for network | %s", t.client.params.Name)
+	}
+	pkScript, err := timeLock.PkScript()
+	if err != nil {
+		return err
+	}
+	wif, err := btcutil.DecodeWIF(privKey)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(wif.SerializePubKey(), timeLock.PubKey) == false {
+		return fmt.Errorf("private key is not of time lock | %s", timeLock.Address)
+	}
+
+	key := hex.EncodeToString(pkScript)
+	t.timeLocks[key] = timeLock
+	t.timeLockKeys[key] = wif
+	t.fromAddrs = append(t.fromAddrs, timeLock.Address)
+	return nil
+}
+
 func (t *RawTx) AddTo(address string, amount btcutil.Amount) (err error) {
-	addr, err := btcutil.DecodeAddress(address, t.client.params)
+	addr, err := decodeAddress(address, t.client.params)
 	if err != nil {
 		return err
 	}
@@ -59,26 +159,139 @@ func (t *RawTx) AddTo(address string, amount btcutil.Amount) (err error) {
 	return nil
 }
 
-func (t *RawTx) SendTx() (txid string, err error) {
-	utxo, err := t.utxoGet()
+// AddData adds OP_RETURN output carrying data ( zero amount ), placed after to outputs
+// data is limited to standardness of bitcoin core, size is counted in fee
+func (t *RawTx) AddData(data []byte) (err error) {
+	if len(data) > DEF_maxDataSize {
+		return fmt.Errorf("data is too large | size : %d | max : %d", len(data), DEF_maxDataSize)
+	}
+	if len(t.toData) >= DEF_maxDataOutputs {
+		return fmt.Errorf("too many OP_RETURN outputs | max : %d", DEF_maxDataOutputs)
+	}
+	pkScript, err := txscript.NullDataScript(data)
 	if err != nil {
-		return "", err
+		return err
+	}
+	t.toData = append(t.toData, pkScript)
+	return nil
+}
+
+// SetCoinSelector changes strategy to pick utxo ( default : branch and bound, knapsack if no exact match )
+func (t *RawTx) SetCoinSelector(coinSelector CoinSelector) {
+	t.coinSelector = coinSelector
+}
+
+func (t *RawTx) SetDustLimit(dustLimit btcutil.Amount) {
+	t.dustLimit = dustLimit
+}
+
+// SetRBF signals opt-in replace by fee on every input ( required to use BumpFee later )
+func (t *RawTx) SetRBF(rbf bool) {
+	t.rbf = rbf
+}
+
+// SetLockTime sets nLockTime of tx ( block height, or unix time if over 500000000 )
+func (t *RawTx) SetLockTime(lockTime uint32) {
+	t.lockTime = lockTime
+}
+
+// SetSequence sets nSequence of input spending txid:vout if it is selected
+func (t *RawTx) SetSequence(txid string, vout uint32, sequence uint32) (err error) {
+	hash, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		return err
 	}
+	t.sequences[*wire.NewOutPoint(hash, vout)] = sequence
+	return nil
+}
 
-	msgTx, leftAmount, err := t.make(utxo)
+func (t *RawTx) SendTx(ctx context.Context) (txid string, err error) {
+	msgTxFunded, err := t.Build(ctx)
 	if err != nil {
 		return "", err
 	}
-	msgTxFunded, err := t.fund(msgTx, utxo, leftAmount)
+	msgTxSigned, err := t.Sign(ctx, msgTxFunded)
 	if err != nil {
 		return "", err
 	}
-	msgTxSigned, err := t.sign(msgTxFunded, utxo)
+	_, err = t.Validate(ctx, msgTxSigned)
 	if err != nil {
 		return "", err
 	}
+	return t.Send(ctx, msgTxSigned)
+}
+
+// Build collects utxo of from addresses and returns funded unsigned tx ( change to balance address )
+// only utxo picked by coin selector are spent
+func (t *RawTx) Build(ctx context.Context) (msgTxFunded *wire.MsgTx, err error) {
+	utxos, err := t.utxoGet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	utxos, err = t.utxoMature(ctx, utxos)
+	if err != nil {
+		return nil, err
+	}
+	utxos, err = t.utxoSelect(utxos)
+	if err != nil {
+		return nil, err
+	}
+
+	msgTx, leftAmount, err := t.make(ctx, utxos)
+	if err != nil {
+		return nil, err
+	}
+	if t.rbf == true {
+		for _, txIn := range msgTx.TxIn {
+			txIn.Sequence = DEF_sequenceRBF
+		}
+	}
+	err = t.setLockTime(msgTx, utxos)
+	if err != nil {
+		return nil, err
+	}
+	msgTxFunded, err = t.fund(msgTx, utxos, leftAmount)
+	if err != nil {
+		return nil, err
+	}
+	t.utxos = utxos
+	return msgTxFunded, nil
+}
+
+// Sign signs tx made by Build with from private keys
+func (t *RawTx) Sign(ctx context.Context, msgTxFunded *wire.MsgTx) (msgTxSigned *wire.MsgTx, err error) {
+	if t.utxos == nil {
+		return nil, fmt.Errorf("tx is not built yet")
+	}
+	return t.sign(ctx, msgTxFunded, t.utxos)
+}
+
+// ValidateResult is result of mempool acceptance dry run
+type ValidateResult struct {
+	Txid             string
+	Allowed          bool
+	RejectReason     string
+	VSize            int64
+	Fee              btcutil.Amount
+	EffectiveFeeRate FeeRate // sat/vB, includes package of ancestors if any
+}
+
+// Validate checks if signed tx would be accepted to mempool ( testmempoolaccept ) without broadcasting
+// if rejected, result is returned with error of reject reason ( errors.Is with ErrTxFeeTooLow, ErrTxMempoolConflict ... )
+func (t *RawTx) Validate(ctx context.Context, msgTxSigned *wire.MsgTx) (result *ValidateResult, err error) {
+	results, err := t.client.TestMempoolAccept(ctx, nil, msgTxSigned)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != 1 {
+		return nil, fmt.Errorf("invalid testmempoolaccept result count | %d", len(results))
+	}
+	return newValidateResult(&results[0])
+}
 
-	return t.send(msgTxSigned)
+// Send broadcasts signed tx
+func (t *RawTx) Send(ctx context.Context, msgTxSigned *wire.MsgTx) (txid string, err error) {
+	return t.send(ctx, msgTxSigned)
 }
 
 //--------------------------------------------------------------------------------//
@@ -91,13 +304,32 @@ type utxo struct {
 	FromAmount   float64 `json:"from_amount"`
 	ScriptPubKey string  `json:"scriptPubKey"`
 	RedeemScript string  `json:"redeemScript"`
+
+	WitnessScript string `json:"witnessScript"`
+	Confirmations int64  `json:"confirmations"`
 }
 
-func (t *RawTx) utxoGet() (utxos []*utxo, err error) {
+func (t *utxo) scripts() (pkScript, redeemScript, witnessScript []byte, err error) {
+	pkScript, err = hex.DecodeString(t.ScriptPubKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	redeemScript, err = hex.DecodeString(t.RedeemScript)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	witnessScript, err = hex.DecodeString(t.WitnessScript)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return pkScript, redeemScript, witnessScript, nil
+}
+
+func (t *RawTx) utxoGet(ctx context.Context) (utxos []*utxo, err error) {
 	// 1. separate address in wallet / out wallet
 	var addressesInWallet, addressesOutWallet []btcutil.Address
 	for _, address := range t.fromAddrs {
-		addrInfo, err := t.client.GetAddressInfo(address.String())
+		addrInfo, err := t.client.GetAddressInfo(ctx, address.String())
 		if err != nil {
 			return nil, err
 		}
@@ -112,20 +344,28 @@ func (t *RawTx) utxoGet() (utxos []*utxo, err error) {
 	// 2. get utxo in wallet
 	var utxosInWallet []btcjson.ListUnspentResult
 	if len(addressesInWallet) > 0 {
-		utxosInWallet, err = t.client.GetListUnspent(0, 0, addressesInWallet...)
+		utxosInWallet, err = t.client.GetListUnspent(ctx, 0, 0, addressesInWallet...)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	// 3. get utxo out wallet
+	// 3. get utxo out wallet ( indexed backend if set, else scantxoutset )
 	var utxosOutWallet []UnSpents
-	if len(addressesOutWallet) > 0 {
-		scanTxOutSet, err := t.client.ScanTxOutSet(addressesOutWallet...)
+	var utxosBackend []*utxo
+	var scanHeight int32
+	if len(addressesOutWallet) > 0 && t.backend != nil {
+		utxosBackend, err = t.utxoGetBackend(ctx, addressesOutWallet)
+		if err != nil {
+			return nil, err
+		}
+	} else if len(addressesOutWallet) > 0 {
+		scanTxOutSet, err := t.client.ScanTxOutSet(ctx, addressesOutWallet...)
 		if err != nil {
 			return nil, err
 		}
 		utxosOutWallet = scanTxOutSet.Unspents
+		scanHeight = scanTxOutSet.Height
 	}
 
 	// 4. make utxo
@@ -138,6 +378,8 @@ func (t *RawTx) utxoGet() (utxos []*utxo, err error) {
 			FromAmount:   utxoInWallet.Amount,
 			ScriptPubKey: utxoInWallet.ScriptPubKey,
 			RedeemScript: utxoInWallet.RedeemScript,
+
+			Confirmations: utxoInWallet.Confirmations,
 		}
 		utxos = append(utxos, utxo)
 	}
@@ -153,13 +395,135 @@ func (t *RawTx) utxoGet() (utxos []*utxo, err error) {
 			FromAddr:     walletAddr,
 			FromAmount:   utxoOutWallet.Amount,
 			ScriptPubKey: utxoOutWallet.ScriptPubKey,
+
+			Confirmations: int64(scanHeight - utxoOutWallet.Height + 1),
 		}
 		utxos = append(utxos, utxo)
 	}
+	utxos = append(utxos, utxosBackend...)
+	t.utxoSetMultiSig(utxos)
+	t.utxoSetTimeLock(utxos)
 
 	return utxos, nil
 }
 
+// utxoSetMultiSig sets redeem / witness script of multisig utxo
+func (t *RawTx) utxoSetMultiSig(utxos []*utxo) {
+	for _, utxo := range utxos {
+		multiSig, ok := t.multiSigs[utxo.ScriptPubKey]
+		if ok == false {
+			continue
+		}
+		utxo.RedeemScript = hex.EncodeToString(multiSig.RedeemScript)
+		utxo.WitnessScript = hex.EncodeToString(multiSig.WitnessScript)
+	}
+}
+
+// utxoSetTimeLock sets redeem / witness script of time locked utxo
+func (t *RawTx) utxoSetTimeLock(utxos []*utxo) {
+	for _, utxo := range utxos {
+		timeLock, ok := t.timeLocks[utxo.ScriptPubKey]
+		if ok == false {
+			continue
+		}
+		utxo.RedeemScript = hex.EncodeToString(timeLock.RedeemScript)
+		utxo.WitnessScript = hex.EncodeToString(timeLock.WitnessScript)
+	}
+}
+
+// utxoMature drops time locked utxo not spendable in next block
+func (t *RawTx) utxoMature(ctx context.Context, utxos []*utxo) (mature []*utxo, err error) {
+	if len(t.timeLocks) == 0 {
+		return utxos, nil
+	}
+	tipHeight, err := t.client.GetBlockCount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mature = make([]*utxo, 0, len(utxos))
+	for _, utxo := range utxos {
+		timeLock, ok := t.timeLocks[utxo.ScriptPubKey]
+		if ok == true && timeLock.IsMature(tipHeight, utxo.Confirmations) == false {
+			continue
+		}
+		mature = append(mature, utxo)
+	}
+	return mature, nil
+}
+
+// utxoSelect picks utxo covering to amounts and fee with coin selector
+func (t *RawTx) utxoSelect(utxos []*utxo) (selected []*utxo, err error) {
+	// size of candidate inputs, tx has witness if any candidate has
+	baseSizes := make([]int64, 0, len(utxos))
+	witnessSizes := make([]int64, 0, len(utxos))
+	var hasWitness bool
+	for _, utxo := range utxos {
+		pkScript, redeemScript, witnessScript, err := utxo.scripts()
+		if err != nil {
+			return nil, err
+		}
+		baseSize, witnessSize, err := estimateInputSize(pkScript, redeemScript, witnessScript)
+		if err != nil {
+			return nil, err
+		}
+		baseSizes = append(baseSizes, baseSize)
+		witnessSizes = append(witnessSizes, witnessSize)
+		hasWitness = hasWitness || witnessSize > 0
+	}
+
+	// target = to amounts + fee of tx without input
+	// input count ( of every candidate ) and segwit marker / flag are included, as fund pays them once inputs are added
+	estimator := &TxSizeEstimator{inputCount: len(utxos), hasWitness: hasWitness}
+	var target btcutil.Amount
+	for addr, amount := range t.toAmounts {
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, err
+		}
+		estimator.AddOutput(pkScript)
+		target += amount
+	}
+	for _, pkScript := range t.toData {
+		estimator.AddOutput(pkScript)
+	}
+	target += estimator.Fee(t.feeRate)
+
+	changeScript, err := txscript.PayToAddrScript(t.balanceAddr)
+	if err != nil {
+		return nil, err
+	}
+	costOfChange := t.feeRate.Fee(outputSize(changeScript)) + t.dustLimit
+
+	coins := make([]*Coin, 0, len(utxos))
+	utxoByCoin := make(map[*Coin]*utxo, len(utxos))
+	for i, utxo := range utxos {
+		amount, err := btcutil.NewAmount(utxo.FromAmount)
+		if err != nil {
+			return nil, err
+		}
+		vsize := (inputWeight(baseSizes[i], witnessSizes[i], hasWitness) + 3) / 4
+		coin := &Coin{
+			Txid:           utxo.Txid,
+			Vout:           utxo.Vout,
+			Amount:         amount,
+			Confirmations:  utxo.Confirmations,
+			EffectiveValue: amount - t.feeRate.Fee(vsize),
+		}
+		coins = append(coins, coin)
+		utxoByCoin[coin] = utxo
+	}
+
+	coinsSelected, err := t.coinSelector.Select(coins, target, costOfChange)
+	if err != nil {
+		return nil, fmt.Errorf("coin selection failed | target : %v | %w", target, err)
+	}
+	selected = make([]*utxo, 0, len(coinsSelected))
+	for _, coin := range coinsSelected {
+		selected = append(selected, utxoByCoin[coin])
+	}
+	return selected, nil
+}
+
 func decodeDescToAddr(desc string) (addr string, err error) {
 	posFront := strings.Index(desc, "addr(")
 	posEnd := strings.Index(desc, ")")
@@ -170,7 +534,7 @@ func decodeDescToAddr(desc string) (addr string, err error) {
 	return desc[posFront+5 : posEnd], nil
 }
 
-func (t *RawTx) make(utxos []*utxo) (msgTx *wire.MsgTx, leftAmount btcutil.Amount, err error) {
+func (t *RawTx) make(ctx context.Context, utxos []*utxo) (msgTx *wire.MsgTx, leftAmount btcutil.Amount, err error) {
 	// conv utxo to input
 	txsInput := make([]btcjson.TransactionInput, 0, len(utxos))
 	for _, utxo := range utxos {
@@ -180,10 +544,13 @@ func (t *RawTx) make(utxos []*utxo) (msgTx *wire.MsgTx, leftAmount btcutil.Amoun
 		txsInput = append(txsInput, txInput)
 	}
 
-	msgTx, err = t.client.rpc.CreateRawTransaction(txsInput, t.toAmounts, nil)
+	msgTx, err = wait(ctx, t.client.timeout, t.client.rpc.CreateRawTransactionAsync(txsInput, t.toAmounts, nil).Receive)
 	if err != nil {
 		return nil, 0, err
 	}
+	for _, pkScript := range t.toData {
+		msgTx.AddTxOut(wire.NewTxOut(0, pkScript))
+	}
 
 	// set amount left ( from amounts total - to amounts total )
 	leftAmount, err = btcutil.NewAmount(0)
@@ -208,47 +575,68 @@ func (t *RawTx) make(utxos []*utxo) (msgTx *wire.MsgTx, leftAmount btcutil.Amoun
 	return msgTx, leftAmount, nil
 }
 
-func (t *RawTx) fund(msgTx *wire.MsgTx, utxos []*utxo, leftAmount btcutil.Amount) (msgTxFunded *wire.MsgTx, err error) {
-	// set amount left without fee = sum(vin) - sum(vout) - fee
-	var leftAmountWithoutFee int64
-	{
-		// sign tx ( to calculate fee )
-		msgTxSigned, err := t.sign(msgTx, utxos)
-		if err != nil {
-			return nil, err
+// setLockTime sets nLockTime and nSequence of inputs by options and time locked utxo
+func (t *RawTx) setLockTime(msgTx *wire.MsgTx, utxos []*utxo) (err error) {
+	if len(msgTx.TxIn) != len(utxos) {
+		return fmt.Errorf("input count mismatch | tx : %d | utxo : %d", len(msgTx.TxIn), len(utxos))
+	}
+	msgTx.LockTime = t.lockTime
+	for i, txIn := range msgTx.TxIn {
+		if sequence, ok := t.sequences[txIn.PreviousOutPoint]; ok == true {
+			txIn.Sequence = sequence
 		}
-
-		// get transaction size
-		_, vsize := getRawTxSize(msgTxSigned)
-
-		// 단위 수수료 변경 ( btc per kb -> satoshi per byte )
-		feePerByteSatoshi := int64(t.fee.ToUnit(btcutil.AmountSatoshi + btcutil.AmountKiloBTC))
-		if feePerByteSatoshi < 0 {
-			return nil, fmt.Errorf("invalid fee per byte ( satoshi ) | %v", feePerByteSatoshi)
+		if timeLock, ok := t.timeLocks[utxos[i].ScriptPubKey]; ok == true {
+			err = timeLock.Apply(msgTx, i)
+			if err != nil {
+				return err
+			}
 		}
+	}
 
-		// p2pkh size = 34
-		feeRawTx := int64(vsize+34) * feePerByteSatoshi
-
-		// left amount 를 satoshi 단위로 변경
-		leftAmountSatoshi := int64(leftAmount.ToUnit(btcutil.AmountSatoshi))
-		if leftAmountSatoshi < 0 {
-			return nil, fmt.Errorf("invalid left amount ( satoshi ) | %v", leftAmount)
+	// nLockTime is ignored if every input is final
+	if msgTx.LockTime != 0 {
+		for _, txIn := range msgTx.TxIn {
+			if txIn.Sequence == wire.MaxTxInSequenceNum {
+				txIn.Sequence = wire.MaxTxInSequenceNum - 1
+			}
 		}
+	}
+	return nil
+}
 
-		// amount left 에서 전체 수수료로 뺀 값이 balance address 에 들어갈 금액
-		leftAmountWithoutFee = leftAmountSatoshi - feeRawTx
-		if leftAmountWithoutFee < 0 {
-			return nil, fmt.Errorf("not enough amount left without fee | left : %v | fee - %v", leftAmount, feeRawTx)
+func (t *RawTx) fund(msgTx *wire.MsgTx, utxos []*utxo, leftAmount btcutil.Amount) (msgTxFunded *wire.MsgTx, err error) {
+	// estimate vsize of signed tx by script type ( no trial signing )
+	estimator := &TxSizeEstimator{}
+	for _, utxo := range utxos {
+		pkScript, redeemScript, witnessScript, err := utxo.scripts()
+		if err != nil {
+			return nil, err
 		}
+		err = estimator.AddInput(pkScript, redeemScript, witnessScript)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, txOut := range msgTx.TxOut {
+		estimator.AddOutput(txOut.PkScript)
+	}
+	if feeNoChange := estimator.Fee(t.feeRate); leftAmount < feeNoChange {
+		return nil, fmt.Errorf("not enough amount left without fee | left : %v | fee - %v", leftAmount, feeNoChange)
 	}
 
-	// add balance address to vout
-	toAddr, err := txscript.PayToAddrScript(t.balanceAddr)
+	// amount left 에서 전체 수수료로 뺀 값이 balance address 에 들어갈 금액
+	changeScript, err := txscript.PayToAddrScript(t.balanceAddr)
 	if err != nil {
 		return nil, err
 	}
-	msgTx.AddTxOut(wire.NewTxOut(leftAmountWithoutFee, toAddr))
+	estimator.AddOutput(changeScript)
+	change := leftAmount - estimator.Fee(t.feeRate)
+
+	// change under dust limit is left as fee
+	if change < t.dustLimit {
+		return msgTx, nil
+	}
+	msgTx.AddTxOut(wire.NewTxOut(int64(change), changeScript))
 
 	return msgTx, nil
 }
@@ -259,31 +647,248 @@ func getRawTxSize(msgTx *wire.MsgTx) (size, vsize int) {
 
 	weight := (sizeNoWitness)*3 + size
 	// round up
-	vsize = int(math.Round(float64(weight) / 4))
+	vsize = (weight + 3) / 4
 	return size, vsize
 }
 
-func (t *RawTx) sign(msgTxFunded *wire.MsgTx, utxos []*utxo) (msgTxSigned *wire.MsgTx, err error) {
-	rawTxInput := make([]RawTxInput, 0, len(utxos))
-	for _, utxo := range utxos {
-		rawTxInput = append(rawTxInput, RawTxInput{
-			Txid:         utxo.Txid,
-			Vout:         utxo.Vout,
-			ScriptPubKey: utxo.ScriptPubKey,
-			RedeemScript: utxo.RedeemScript,
-			Amount:       utxo.FromAmount,
-		})
+func (t *RawTx) sign(ctx context.Context, msgTxFunded *wire.MsgTx, utxos []*utxo) (msgTxSigned *wire.MsgTx, err error) {
+	t.utxoSetMultiSig(utxos)
+	t.utxoSetTimeLock(utxos)
+
+	msgTxSigned = msgTxFunded.Copy()
+	if usesForkID(t.client.params) == true {
+		err = t.signForkID(msgTxSigned, utxos)
+		if err != nil {
+			return nil, err
+		}
+		return msgTxSigned, nil
 	}
 
-	msgTxSigned, err = t.client.SignRawTransactionWithKey(msgTxFunded, rawTxInput, t.fromPrivKeys)
+	if len(t.multiSigs) > 0 {
+		err = t.signMultiSig(msgTxSigned, utxos)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(t.timeLocks) > 0 {
+		err = t.signTimeLock(msgTxSigned, utxos)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = t.signSingleKey(msgTxSigned, utxos)
 	if err != nil {
 		return nil, err
 	}
 	return msgTxSigned, nil
 }
 
-func (t *RawTx) send(msgTxSigned *wire.MsgTx) (txid string, err error) {
-	hash, err := t.client.rpc.SendRawTransaction(msgTxSigned, false)
+// prevOutFetcher returns prevouts of every input ( segwit v1 sighash commits to all of them )
+func (t *RawTx) prevOutFetcher(msgTx *wire.MsgTx, utxos []*utxo) (fetcher *txscript.MultiPrevOutFetcher, err error) {
+	if len(msgTx.TxIn) != len(utxos) {
+		return nil, fmt.Errorf("input count mismatch | tx : %d | utxo : %d", len(msgTx.TxIn), len(utxos))
+	}
+	fetcher = txscript.NewMultiPrevOutFetcher(nil)
+	for i, utxo := range utxos {
+		pkScript, _, _, err := utxo.scripts()
+		if err != nil {
+			return nil, err
+		}
+		amount, err := btcutil.NewAmount(utxo.FromAmount)
+		if err != nil {
+			return nil, err
+		}
+		fetcher.AddPrevOut(msgTx.TxIn[i].PreviousOutPoint, wire.NewTxOut(int64(amount), pkScript))
+	}
+	return fetcher, nil
+}
+
+// signSingleKey signs P2PKH, P2WPKH, P2SH-P2WPKH and P2TR utxo by keys of AddFrom ( same as OfflineTx, node is not used )
+func (t *RawTx) signSingleKey(msgTx *wire.MsgTx, utxos []*utxo) (err error) {
+	fetcher, err := t.prevOutFetcher(msgTx, utxos)
+	if err != nil {
+		return err
+	}
+	var keys *keyStore
+	var sigHashes *txscript.TxSigHashes
+	for i, utxo := range utxos {
+		if _, ok := t.multiSigs[utxo.ScriptPubKey]; ok == true {
+			continue
+		}
+		if _, ok := t.timeLocks[utxo.ScriptPubKey]; ok == true {
+			continue
+		}
+		if keys == nil {
+			keys = newKeyStore()
+			for _, privKey := range t.fromPrivKeys {
+				err = keys.add(privKey, t.client.params)
+				if err != nil {
+					return err
+				}
+			}
+			sigHashes = txscript.NewTxSigHashes(msgTx, fetcher)
+		}
+		_, redeemScript, _, err := utxo.scripts()
+		if err != nil {
+			return err
+		}
+		txOut := fetcher.FetchPrevOutput(msgTx.TxIn[i].PreviousOutPoint)
+		err = signInput(msgTx, i, &prevOut{txOut: txOut, redeemScript: redeemScript}, sigHashes, keys)
+		if err != nil {
+			return fmt.Errorf("sign input %d failed | %w", i, err)
+		}
+	}
+	return nil
+}
+
+// signMultiSig collects partial signatures of multisig keys and assembles final scriptSig / witness
+func (t *RawTx) signMultiSig(msgTx *wire.MsgTx, utxos []*utxo) (err error) {
+	fetcher, err := t.prevOutFetcher(msgTx, utxos)
+	if err != nil {
+		return err
+	}
+	sigHashes := txscript.NewTxSigHashes(msgTx, fetcher)
+
+	for i, utxo := range utxos {
+		multiSig, ok := t.multiSigs[utxo.ScriptPubKey]
+		if ok == false {
+			continue
+		}
+		amount := btcutil.Amount(fetcher.FetchPrevOutput(msgTx.TxIn[i].PreviousOutPoint).Value)
+
+		sigs := make(map[string][]byte)
+		for _, wif := range t.multiSigKeys[utxo.ScriptPubKey] {
+			sig, err := multiSig.Sign(msgTx, i, amount, sigHashes, wif)
+			if err != nil {
+				return fmt.Errorf("sign input %d failed | %w", i, err)
+			}
+			sigs[hex.EncodeToString(wif.SerializePubKey())] = sig
+		}
+		err = multiSig.Assemble(msgTx.TxIn[i], sigs)
+		if err != nil {
+			return fmt.Errorf("sign input %d failed | %w", i, err)
+		}
+	}
+	return nil
+}
+
+// signTimeLock signs time locked utxo, tx must satisfy lock ( set by Build )
+func (t *RawTx) signTimeLock(msgTx *wire.MsgTx, utxos []*utxo) (err error) {
+	fetcher, err := t.prevOutFetcher(msgTx, utxos)
+	if err != nil {
+		return err
+	}
+	sigHashes := txscript.NewTxSigHashes(msgTx, fetcher)
+
+	for i, utxo := range utxos {
+		timeLock, ok := t.timeLocks[utxo.ScriptPubKey]
+		if ok == false {
+			continue
+		}
+		amount := btcutil.Amount(fetcher.FetchPrevOutput(msgTx.TxIn[i].PreviousOutPoint).Value)
+		sig, err := timeLock.Sign(msgTx, i, amount, sigHashes, t.timeLockKeys[utxo.ScriptPubKey])
+		if err != nil {
+			return fmt.Errorf("sign input %d failed | %w", i, err)
+		}
+		err = timeLock.Assemble(msgTx.TxIn[i], sig)
+		if err != nil {
+			return fmt.Errorf("sign input %d failed | %w", i, err)
+		}
+	}
+	return nil
+}
+
+// signForkID signs P2PKH and P2SH multisig utxo with SIGHASH_FORKID ( bitcoin cash ), node is not used
+func (t *RawTx) signForkID(msgTx *wire.MsgTx, utxos []*utxo) (err error) {
+	fetcher, err := t.prevOutFetcher(msgTx, utxos)
+	if err != nil {
+		return err
+	}
+	sigHashes := txscript.NewTxSigHashes(msgTx, fetcher)
+	keys := newKeyStore()
+	for _, privKey := range t.fromPrivKeys {
+		err = keys.add(privKey, t.client.params)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, utxo := range utxos {
+		txOut := fetcher.FetchPrevOutput(msgTx.TxIn[i].PreviousOutPoint)
+
+		if multiSig, ok := t.multiSigs[utxo.ScriptPubKey]; ok == true {
+			if multiSig.Type != DEF_multiSig_P2SH {
+				return fmt.Errorf("sign input %d failed | unsupported multisig type | %s", i, multiSig.Type)
+			}
+			sigs := make(map[string][]byte)
+			for _, wif := range t.multiSigKeys[utxo.ScriptPubKey] {
+				sig, err := rawTxInForkIDSignature(msgTx, i, multiSig.Script, txOut.Value, sigHashes, wif.PrivKey)
+				if err != nil {
+					return fmt.Errorf("sign input %d failed | %w", i, err)
+				}
+				sigs[hex.EncodeToString(wif.SerializePubKey())] = sig
+			}
+			err = multiSig.Assemble(msgTx.TxIn[i], sigs)
+			if err != nil {
+				return fmt.Errorf("sign input %d failed | %w", i, err)
+			}
+			continue
+		}
+
+		if txscript.GetScriptClass(txOut.PkScript) != txscript.PubKeyHashTy {
+			return fmt.Errorf("sign input %d failed | unsupported script type | %s", i, txscript.GetScriptClass(txOut.PkScript))
+		}
+		wif, err := keys.get(txOut.PkScript[3:23], false)
+		if err != nil {
+			return fmt.Errorf("sign input %d failed | %w", i, err)
+		}
+		sig, err := rawTxInForkIDSignature(msgTx, i, txOut.PkScript, txOut.Value, sigHashes, wif.PrivKey)
+		if err != nil {
+			return fmt.Errorf("sign input %d failed | %w", i, err)
+		}
+		msgTx.TxIn[i].SignatureScript, err = txscript.NewScriptBuilder().AddData(sig).AddData(wif.SerializePubKey()).Script()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newValidateResult(res *TestMempoolAcceptResult) (result *ValidateResult, err error) {
+	result = &ValidateResult{
+		Txid:         res.Txid,
+		Allowed:      res.Allowed,
+		RejectReason: res.RejectReason,
+		VSize:        res.VSize,
+	}
+	if res.Fees != nil {
+		result.Fee, err = btcutil.NewAmount(res.Fees.Base)
+		if err != nil {
+			return nil, err
+		}
+		if res.Fees.EffectiveFeeRate > 0 {
+			effectiveFeeRate, err := btcutil.NewAmount(res.Fees.EffectiveFeeRate)
+			if err != nil {
+				return nil, err
+			}
+			result.EffectiveFeeRate = NewFeeRateFromBTCPerKB(effectiveFeeRate)
+		} else if res.VSize > 0 {
+			result.EffectiveFeeRate = FeeRate(float64(result.Fee) / float64(res.VSize))
+		}
+	}
+
+	if res.Allowed == false {
+		reason := res.RejectReason
+		if reason == "" {
+			reason = res.PackageError
+		}
+		return result, fmt.Errorf("tx rejected by mempool | txid : %s | %w", res.Txid, classifyError(btcjson.NewRPCError(btcjson.ErrRPCVerifyRejected, reason)))
+	}
+	return result, nil
+}
+
+func (t *RawTx) send(ctx context.Context, msgTxSigned *wire.MsgTx) (txid string, err error) {
+	hash, err := wait(ctx, t.client.timeout, t.client.rpc.SendRawTransactionAsync(msgTxSigned, false).Receive)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,210 @@
+package btc
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateResult(t *testing.T) {
+	// testmempoolaccept results of bitcoin core
+	var results []TestMempoolAcceptResult
+	err := json.Unmarshal([]byte(`[
+		{"txid":"a","wtxid":"b","allowed":true,"vsize":141,"fees":{"base":0.00002820,"effective-feerate":0.00020000,"effective-includes":["b"]}},
+		{"txid":"c","wtxid":"d","allowed":false,"reject-reason":"min relay fee not met, 100 < 141"},
+		{"txid":"e","wtxid":"f","allowed":false,"reject-reason":"txn-mempool-conflict"}
+	]`), &results)
+	require.NoError(t, err)
+
+	result, err := newValidateResult(&results[0])
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+	require.Equal(t, int64(141), result.VSize)
+	require.Equal(t, btcutil.Amount(2820), result.Fee)
+	require.Equal(t, FeeRate(20), result.EffectiveFeeRate)
+
+	result, err = newValidateResult(&results[1])
+	require.ErrorIs(t, err, ErrTxFeeTooLow)
+	require.False(t, result.Allowed)
+	require.Equal(t, "min relay fee not met, 100 < 141", result.RejectReason)
+
+	_, err = newValidateResult(&results[2])
+	require.ErrorIs(t, err, ErrTxMempoolConflict)
+
+	// cmd
+	marshaled, err := btcjson.MarshalCmd(btcjson.RpcVersion1, 1, NewTestMempoolAcceptCmd([]string{"00"}, nil))
+	require.NoError(t, err)
+	require.Contains(t, string(marshaled), `"method":"testmempoolaccept","params":[["00"]]`)
+}
+
+func TestRawTxData(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	wif := newTestKey(t, 1, true)
+	from, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(wif.SerializePubKey()), params)
+	require.NoError(t, err)
+	fromScript, err := txscript.PayToAddrScript(from)
+	require.NoError(t, err)
+	to, err := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), params)
+	require.NoError(t, err)
+	toScript, err := txscript.PayToAddrScript(to)
+	require.NoError(t, err)
+
+	// createrawtransaction of node has to output only
+	hash, err := chainhash.NewHashFromStr(DEF_txid_dummy)
+	require.NoError(t, err)
+	msgTxCreated := wire.NewMsgTx(wire.TxVersion)
+	msgTxCreated.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, 0), nil, nil))
+	msgTxCreated.AddTxOut(wire.NewTxOut(50000, toScript))
+	var buf bytes.Buffer
+	require.NoError(t, msgTxCreated.Serialize(&buf))
+
+	client, _ := newTestRPCServer(t, map[string]string{
+		"getaddressinfo":       `{"ismine":true}`,
+		"listunspent":          `[{"txid":"` + DEF_txid_dummy + `","vout":0,"address":"` + from.EncodeAddress() + `","scriptPubKey":"` + hex.EncodeToString(fromScript) + `","amount":0.001,"confirmations":6,"spendable":true}]`,
+		"createrawtransaction": `"` + hex.EncodeToString(buf.Bytes()) + `"`,
+	})
+	rawTx := &RawTx{}
+	require.NoError(t, rawTx.Init(client, from.EncodeAddress(), 0))
+	require.NoError(t, rawTx.SetFeeRate(10))
+	require.NoError(t, rawTx.AddFrom(wif.String(), from.EncodeAddress()))
+	require.NoError(t, rawTx.AddTo(to.EncodeAddress(), 50000))
+
+	// standardness
+	require.Error(t, rawTx.AddData(make([]byte, DEF_maxDataSize+1)))
+	root := chainhash.DoubleHashB([]byte("merkle root"))
+	require.NoError(t, rawTx.AddData(root))
+	require.Error(t, rawTx.AddData(root))
+
+	msgTx, err := rawTx.Build(context.Background())
+	require.NoError(t, err)
+	require.Len(t, msgTx.TxOut, 3)
+	require.Equal(t, toScript, msgTx.TxOut[0].PkScript)
+	require.Equal(t, int64(0), msgTx.TxOut[1].Value)
+	require.Equal(t, txscript.NullDataTy, txscript.GetScriptClass(msgTx.TxOut[1].PkScript))
+	pushes, err := txscript.PushedData(msgTx.TxOut[1].PkScript)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{root}, pushes)
+	require.Equal(t, fromScript, msgTx.TxOut[2].PkScript)
+
+	// fee covers OP_RETURN output
+	estimator := &TxSizeEstimator{}
+	require.NoError(t, estimator.AddInput(fromScript, nil, nil))
+	for _, txOut := range msgTx.TxOut {
+		estimator.AddOutput(txOut.PkScript)
+	}
+	fee := btcutil.Amount(100000 - 50000 - msgTx.TxOut[2].Value)
+	require.Equal(t, estimator.Fee(10), fee)
+}
+
+func TestRawTxSelectExact(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), params)
+	require.NoError(t, err)
+	pkScript, err := txscript.PayToAddrScript(addr)
+	require.NoError(t, err)
+
+	// 1 sat/vB, P2WPKH input 68 vB, tx of one P2WPKH output 42 vB ( marker / flag included )
+	// fund needs 110 sat over to amount, 109 was an exact match without input overhead
+	for _, test := range []struct {
+		fee   btcutil.Amount
+		exact bool
+	}{
+		{109, false},
+		{110, true},
+	} {
+		rawTx := &RawTx{}
+		require.NoError(t, rawTx.Init(&Client{params: params}, addr.EncodeAddress(), 0))
+		require.NoError(t, rawTx.SetFeeRate(1))
+		rawTx.SetCoinSelector(&BranchAndBoundSelector{})
+		require.NoError(t, rawTx.AddTo(addr.EncodeAddress(), 10000))
+
+		amount := 10000 + test.fee
+		utxos := []*utxo{{Txid: DEF_txid_dummy, Vout: 0, FromAmount: amount.ToBTC(), ScriptPubKey: hex.EncodeToString(pkScript)}}
+		selected, err := rawTx.utxoSelect(utxos)
+		if test.exact == false {
+			require.ErrorIs(t, err, ErrInsufficientFunds)
+			continue
+		}
+		require.NoError(t, err)
+		require.Len(t, selected, 1)
+
+		hash, err := chainhash.NewHashFromStr(DEF_txid_dummy)
+		require.NoError(t, err)
+		msgTx := wire.NewMsgTx(wire.TxVersion)
+		msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, 0), nil, nil))
+		msgTx.AddTxOut(wire.NewTxOut(10000, pkScript))
+		msgTxFunded, err := rawTx.fund(msgTx, selected, test.fee)
+		require.NoError(t, err)
+		require.Len(t, msgTxFunded.TxOut, 1) // no change
+	}
+}
+
+func TestRawTxSignLocal(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	balanceAddr, err := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), params)
+	require.NoError(t, err)
+	rawTx := &RawTx{}
+	require.NoError(t, rawTx.Init(&Client{params: params}, balanceAddr.EncodeAddress(), 0)) // client without rpc, node is not called
+
+	hash, err := chainhash.NewHashFromStr(DEF_txid_dummy)
+	require.NoError(t, err)
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	var utxos []*utxo
+	for i, newAddr := range []func(wif *btcutil.WIF) (btcutil.Address, []byte){
+		func(wif *btcutil.WIF) (btcutil.Address, []byte) { // P2PKH
+			addr, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(wif.SerializePubKey()), params)
+			require.NoError(t, err)
+			return addr, nil
+		},
+		func(wif *btcutil.WIF) (btcutil.Address, []byte) { // P2WPKH
+			addr, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(wif.SerializePubKey()), params)
+			require.NoError(t, err)
+			return addr, nil
+		},
+		func(wif *btcutil.WIF) (btcutil.Address, []byte) { // P2SH-P2WPKH
+			redeemScript := p2wpkhScript(btcutil.Hash160(wif.SerializePubKey()))
+			addr, err := btcutil.NewAddressScriptHash(redeemScript, params)
+			require.NoError(t, err)
+			return addr, redeemScript
+		},
+	} {
+		wif := newTestKey(t, byte(i+1), true)
+		addr, redeemScript := newAddr(wif)
+		require.NoError(t, rawTx.AddFrom(wif.String(), addr.EncodeAddress()))
+
+		pkScript, err := txscript.PayToAddrScript(addr)
+		require.NoError(t, err)
+		outPoint := wire.NewOutPoint(hash, uint32(i))
+		msgTx.AddTxIn(wire.NewTxIn(outPoint, nil, nil))
+		fetcher.AddPrevOut(*outPoint, wire.NewTxOut(100000, pkScript))
+		utxos = append(utxos, &utxo{Txid: DEF_txid_dummy, Vout: uint32(i), FromAmount: 0.001, ScriptPubKey: hex.EncodeToString(pkScript), RedeemScript: hex.EncodeToString(redeemScript)})
+	}
+	msgTx.AddTxOut(wire.NewTxOut(290000, newTestP2WPKHScript(t, 9)))
+
+	msgTxSigned, err := rawTx.sign(context.Background(), msgTx, utxos)
+	require.NoError(t, err)
+
+	sigHashes := txscript.NewTxSigHashes(msgTxSigned, fetcher)
+	for i, txIn := range msgTxSigned.TxIn {
+		prevOut := fetcher.FetchPrevOutput(txIn.PreviousOutPoint)
+		engine, err := txscript.NewEngine(prevOut.PkScript, msgTxSigned, i, txscript.StandardVerifyFlags, nil, sigHashes, prevOut.Value, fetcher)
+		require.NoError(t, err)
+		require.NoError(t, engine.Execute(), "input %d", i)
+	}
+
+	// utxo without key is not left unsigned
+	rawTx.fromPrivKeys = rawTx.fromPrivKeys[:2]
+	_, err = rawTx.sign(context.Background(), msgTx, utxos)
+	require.ErrorContains(t, err, "sign input 2 failed")
+}
@@ -0,0 +1,317 @@
+package btc
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+const (
+	DEF_scanReorgDepth  = 100 // block hashes kept to find fork point
+	DEF_scanBatchBlocks = 10  // blocks fetched at once by batch request when behind
+)
+
+var (
+	ErrReorgTooDeep = errors.New("reorg is deeper than kept block hashes")
+)
+
+type ScanEventType string
+
+const (
+	DEF_scanEvent_deposit  ScanEventType = "deposit"
+	DEF_scanEvent_rollback ScanEventType = "rollback" // block is orphaned, deposits of block must be reverted
+)
+
+// ScanEvent is emitted in chain order ( rollback events from tip to fork point )
+type ScanEvent struct {
+	Type        ScanEventType
+	BlockHeight int64
+	BlockHash   string
+	Deposit     *Deposit // deposit event only
+}
+
+// Deposit is output paying to watched address
+type Deposit struct {
+	Txid          string
+	Vout          uint32
+	Address       string
+	Amount        btcutil.Amount
+	BlockHeight   int64
+	BlockHash     string
+	Confirmations int64 // at scan time
+}
+
+// blockSource is part of Client used by Scanner
+type blockSource interface {
+	GetBlockCount(ctx context.Context) (blockNumber int64, err error)
+	GetBlockHash(ctx context.Context, blockNumber int64) (blockHash string, err error)
+	GetBlockInfoWithTx(ctx context.Context, blockHash string) (blockInfo *btcjson.GetBlockVerboseTxResult, err error)
+}
+
+// batchBlockSource is implemented by Client, blocks are fetched in batch if source implements it
+type batchBlockSource interface {
+	GetBlocksWithTx(ctx context.Context, from, to int64) (blockInfos []*btcjson.GetBlockVerboseTxResult, err error)
+}
+
+// Scanner walks blocks from saved height to tip and emits deposits to watched addresses
+// save Height / BlockHash after Scan and pass them to NewScanner on restart
+type Scanner struct {
+	client blockSource
+	params *chaincfg.Params
+
+	mtx     sync.RWMutex        // guards watches and scan state, Height / BlockHash / Watch can be called while Scan runs
+	watches map[string]struct{} // encoded address
+
+	height int64
+	hashes map[int64]string // recent scanned block hashes ( height -> hash )
+	depth  int64
+}
+
+// NewScanner creates scanner continuing after height ( blockHash of height is optional, used to detect reorg on restart )
+func NewScanner(client *Client, height int64, blockHash string) *Scanner {
+	return newScanner(client, client.params, height, blockHash)
+}
+
+func newScanner(client blockSource, params *chaincfg.Params, height int64, blockHash string) *Scanner {
+	scanner := &Scanner{
+		client:  client,
+		params:  params,
+		watches: make(map[string]struct{}),
+		height:  height,
+		hashes:  make(map[int64]string),
+		depth:   DEF_scanReorgDepth,
+	}
+	if blockHash != "" {
+		scanner.hashes[height] = blockHash
+	}
+	return scanner
+}
+
+// SetReorgDepth changes count of block hashes kept to find fork point
+func (t *Scanner) SetReorgDepth(depth int64) (err error) {
+	if depth <= 0 {
+		return fmt.Errorf("invalid reorg depth | %d", depth)
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.depth = depth
+	return nil
+}
+
+func (t *Scanner) Watch(addresses ...string) (err error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	for _, address := range addresses {
+		addr, err := decodeAddress(address, t.params)
+		if err != nil {
+			return err
+		}
+		t.watches[EncodeAddress(addr, t.params)] = struct{}{}
+	}
+	return nil
+}
+
+func (t *Scanner) Unwatch(addresses ...string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	for _, address := range addresses {
+		if addr, err := decodeAddress(address, t.params); err == nil {
+			address = EncodeAddress(addr, t.params)
+		}
+		delete(t.watches, address)
+	}
+}
+
+func (t *Scanner) isWatched(address string) bool {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+	_, ok := t.watches[address]
+	return ok
+}
+
+// Height returns last scanned block height
+func (t *Scanner) Height() int64 {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+	return t.height
+}
+
+// BlockHash returns hash of last scanned block ( empty if unknown )
+func (t *Scanner) BlockHash() string {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+	return t.hashes[t.height]
+}
+
+// lastBlock returns last scanned height and its hash ( ok is false if hash is unknown )
+func (t *Scanner) lastBlock() (height int64, hash string, ok bool) {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+	hash, ok = t.hashes[t.height]
+	return t.height, hash, ok
+}
+
+// Scan walks blocks up to tip and calls handler for every event
+// if handler returns error, scan stops and the block is scanned again on next Scan ( handler should be idempotent by txid / vout )
+func (t *Scanner) Scan(ctx context.Context, handler func(event *ScanEvent) error) (err error) {
+	for {
+		tip, err := t.client.GetBlockCount(ctx)
+		if err != nil {
+			return err
+		}
+		height, hash, ok := t.lastBlock()
+		if height >= tip {
+			if ok == false {
+				return nil
+			}
+			if height == tip {
+				nodeHash, err := t.client.GetBlockHash(ctx, height)
+				if err != nil {
+					return err
+				}
+				if nodeHash == hash {
+					return nil
+				}
+			}
+			// chain is switched to fork of same or lower height, no new block reveals it
+			err = t.rollback(ctx, tip, handler)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		blocks, err := t.fetch(ctx, height+1, tip)
+		if err != nil {
+			return err
+		}
+		for _, block := range blocks {
+			// previous block is changed -> reorg ( or chain changed while fetching batch )
+			if _, prevHash, ok := t.lastBlock(); ok == true && prevHash != block.PreviousHash {
+				err = t.rollback(ctx, tip, handler)
+				if err != nil {
+					return err
+				}
+				break
+			}
+			err = t.scanBlock(block, handler)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// fetch returns next blocks from height ( up to DEF_scanBatchBlocks if source supports batch )
+func (t *Scanner) fetch(ctx context.Context, from, tip int64) (blocks []*btcjson.GetBlockVerboseTxResult, err error) {
+	if source, ok := t.client.(batchBlockSource); ok == true {
+		return source.GetBlocksWithTx(ctx, from, min(tip, from+DEF_scanBatchBlocks-1))
+	}
+	blockHash, err := t.client.GetBlockHash(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	block, err := t.client.GetBlockInfoWithTx(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	return []*btcjson.GetBlockVerboseTxResult{block}, nil
+}
+
+func (t *Scanner) scanBlock(block *btcjson.GetBlockVerboseTxResult, handler func(event *ScanEvent) error) (err error) {
+	deposits, err := t.deposits(block)
+	if err != nil {
+		return err
+	}
+	for _, deposit := range deposits {
+		err = handler(&ScanEvent{
+			Type:        DEF_scanEvent_deposit,
+			BlockHeight: block.Height,
+			BlockHash:   block.Hash,
+			Deposit:     deposit,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.height = block.Height
+	t.hashes[t.height] = block.Hash
+	delete(t.hashes, t.height-t.depth)
+	return nil
+}
+
+// rollback emits rollback of orphaned blocks and rewinds to fork point ( blocks above tip are orphaned )
+func (t *Scanner) rollback(ctx context.Context, tip int64, handler func(event *ScanEvent) error) (err error) {
+	for {
+		height, hash, ok := t.lastBlock()
+		if ok == false {
+			return fmt.Errorf("%w | height : %d", ErrReorgTooDeep, height)
+		}
+		if height <= tip {
+			nodeHash, err := t.client.GetBlockHash(ctx, height)
+			if err != nil {
+				return err
+			}
+			if nodeHash == hash {
+				return nil
+			}
+		}
+
+		err = handler(&ScanEvent{
+			Type:        DEF_scanEvent_rollback,
+			BlockHeight: height,
+			BlockHash:   hash,
+		})
+		if err != nil {
+			return err
+		}
+		t.mtx.Lock()
+		delete(t.hashes, height)
+		t.height = height - 1
+		t.mtx.Unlock()
+	}
+}
+
+// deposits decodes every output of block and returns outputs to watched addresses
+func (t *Scanner) deposits(block *btcjson.GetBlockVerboseTxResult) (deposits []*Deposit, err error) {
+	for _, tx := range block.Tx {
+		for _, vout := range tx.Vout {
+			pkScript, err := hex.DecodeString(vout.ScriptPubKey.Hex)
+			if err != nil {
+				return nil, fmt.Errorf("invalid script | txid : %s | vout : %d | %w", tx.Txid, vout.N, err)
+			}
+			_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript, t.params)
+			if err != nil || len(addrs) != 1 {
+				continue // non standard, bare multisig, op_return
+			}
+			address := EncodeAddress(addrs[0], t.params)
+			if t.isWatched(address) == false {
+				continue
+			}
+			amount, err := btcutil.NewAmount(vout.Value)
+			if err != nil {
+				return nil, err
+			}
+			deposits = append(deposits, &Deposit{
+				Txid:          tx.Txid,
+				Vout:          vout.N,
+				Address:       address,
+				Amount:        amount,
+				BlockHeight:   block.Height,
+				BlockHash:     block.Hash,
+				Confirmations: block.Confirmations,
+			})
+		}
+	}
+	return deposits, nil
+}
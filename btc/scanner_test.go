@@ -0,0 +1,261 @@
+package btc
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/stretchr/testify/require"
+)
+
+// testChain is in memory block source, blocks[i] is block of height i
+type testChain struct {
+	blocks []*btcjson.GetBlockVerboseTxResult
+}
+
+func (t *testChain) GetBlockCount(ctx context.Context) (blockNumber int64, err error) {
+	return int64(len(t.blocks) - 1), nil
+}
+
+func (t *testChain) GetBlockHash(ctx context.Context, blockNumber int64) (blockHash string, err error) {
+	if blockNumber < 0 || blockNumber >= int64(len(t.blocks)) {
+		return "", fmt.Errorf("block height out of range")
+	}
+	return t.blocks[blockNumber].Hash, nil
+}
+
+func (t *testChain) GetBlockInfoWithTx(ctx context.Context, blockHash string) (blockInfo *btcjson.GetBlockVerboseTxResult, err error) {
+	for _, block := range t.blocks {
+		if block.Hash == blockHash {
+			block.Confirmations = int64(len(t.blocks)) - block.Height
+			return block, nil
+		}
+	}
+	return nil, fmt.Errorf("block not found")
+}
+
+// add appends block paying amount to pkScript, fork is prefix of block hash
+func (t *testChain) add(fork string, pkScript []byte, amount float64) {
+	height := int64(len(t.blocks))
+	block := &btcjson.GetBlockVerboseTxResult{
+		Hash:   fmt.Sprintf("%s-%d", fork, height),
+		Height: height,
+	}
+	if height > 0 {
+		block.PreviousHash = t.blocks[height-1].Hash
+	}
+	block.Tx = []btcjson.TxRawResult{{
+		Txid: fmt.Sprintf("tx-%s", block.Hash),
+		Vout: []btcjson.Vout{{Value: amount, N: 0, ScriptPubKey: btcjson.ScriptPubKeyResult{Hex: hex.EncodeToString(pkScript)}}},
+	}}
+	t.blocks = append(t.blocks, block)
+}
+
+func TestScanner(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	addrWatched, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(newTestKey(t, 1, true).SerializePubKey()), params)
+	require.NoError(t, err)
+	addrOther, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(newTestKey(t, 2, true).SerializePubKey()), params)
+	require.NoError(t, err)
+	scriptWatched, err := txscript.PayToAddrScript(addrWatched)
+	require.NoError(t, err)
+	scriptOther, err := txscript.PayToAddrScript(addrOther)
+	require.NoError(t, err)
+
+	chain := &testChain{}
+	chain.add("a", scriptOther, 1)
+	chain.add("a", scriptWatched, 0.1)
+	chain.add("a", scriptOther, 1)
+	chain.add("a", scriptWatched, 0.2)
+
+	scanner := newScanner(chain, params, 0, chain.blocks[0].Hash)
+	require.NoError(t, scanner.Watch(addrWatched.EncodeAddress()))
+
+	var events []*ScanEvent
+	handler := func(event *ScanEvent) error {
+		events = append(events, event)
+		return nil
+	}
+	require.NoError(t, scanner.Scan(context.Background(), handler))
+	require.Len(t, events, 2)
+	require.Equal(t, DEF_scanEvent_deposit, events[0].Type)
+	require.Equal(t, "tx-a-1", events[0].Deposit.Txid)
+	require.Equal(t, btcutil.Amount(10000000), events[0].Deposit.Amount)
+	require.Equal(t, int64(3), events[0].Deposit.Confirmations)
+	require.Equal(t, "tx-a-3", events[1].Deposit.Txid)
+	require.Equal(t, int64(3), scanner.Height())
+	require.Equal(t, "a-3", scanner.BlockHash())
+
+	// reorg of 2 blocks ( fork after height 1 )
+	chain.blocks = chain.blocks[:2]
+	chain.add("b", scriptWatched, 0.3)
+	chain.add("b", scriptOther, 1)
+	chain.add("b", scriptWatched, 0.4)
+
+	events = nil
+	require.NoError(t, scanner.Scan(context.Background(), handler))
+	require.Len(t, events, 4)
+	require.Equal(t, DEF_scanEvent_rollback, events[0].Type)
+	require.Equal(t, "a-3", events[0].BlockHash)
+	require.Equal(t, DEF_scanEvent_rollback, events[1].Type)
+	require.Equal(t, "a-2", events[1].BlockHash)
+	require.Equal(t, "tx-b-2", events[2].Deposit.Txid)
+	require.Equal(t, "tx-b-4", events[3].Deposit.Txid)
+	require.Equal(t, "b-4", scanner.BlockHash())
+
+	// handler error stops scan, block is scanned again
+	chain.add("b", scriptWatched, 0.5)
+	require.Error(t, scanner.Scan(context.Background(), func(event *ScanEvent) error { return fmt.Errorf("db error") }))
+	require.Equal(t, int64(4), scanner.Height())
+	events = nil
+	require.NoError(t, scanner.Scan(context.Background(), handler))
+	require.Len(t, events, 1)
+	require.Equal(t, "tx-b-5", events[0].Deposit.Txid)
+
+	// reorg deeper than kept hashes
+	require.Error(t, scanner.SetReorgDepth(0))
+	require.Error(t, scanner.SetReorgDepth(-1))
+	require.NoError(t, scanner.SetReorgDepth(2))
+	chain.add("b", scriptOther, 1)
+	chain.add("b", scriptOther, 1)
+	require.NoError(t, scanner.Scan(context.Background(), handler))
+	chain.blocks = chain.blocks[:4]
+	for i := 0; i < 5; i++ {
+		chain.add("c", scriptOther, 1)
+	}
+	require.ErrorIs(t, scanner.Scan(context.Background(), handler), ErrReorgTooDeep)
+}
+
+func TestScannerShorterFork(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	addrWatched, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(newTestKey(t, 1, true).SerializePubKey()), params)
+	require.NoError(t, err)
+	scriptWatched, err := txscript.PayToAddrScript(addrWatched)
+	require.NoError(t, err)
+
+	chain := &testChain{}
+	for i := 0; i < 4; i++ {
+		chain.add("a", scriptWatched, 0.1)
+	}
+	scanner := newScanner(chain, params, 0, chain.blocks[0].Hash)
+	require.NoError(t, scanner.Watch(addrWatched.EncodeAddress()))
+
+	var events []*ScanEvent
+	handler := func(event *ScanEvent) error {
+		events = append(events, event)
+		return nil
+	}
+	require.NoError(t, scanner.Scan(context.Background(), handler))
+	require.Equal(t, "a-3", scanner.BlockHash())
+
+	// fork of same height ( tip is not raised )
+	chain.blocks = chain.blocks[:3]
+	chain.add("b", scriptWatched, 0.2)
+	events = nil
+	require.NoError(t, scanner.Scan(context.Background(), handler))
+	require.Len(t, events, 2)
+	require.Equal(t, DEF_scanEvent_rollback, events[0].Type)
+	require.Equal(t, "a-3", events[0].BlockHash)
+	require.Equal(t, "tx-b-3", events[1].Deposit.Txid)
+	require.Equal(t, "b-3", scanner.BlockHash())
+
+	// shorter fork ( fork after height 1, tip is lowered )
+	chain.blocks = chain.blocks[:2]
+	chain.add("c", scriptWatched, 0.3)
+	events = nil
+	require.NoError(t, scanner.Scan(context.Background(), handler))
+	require.Len(t, events, 3)
+	require.Equal(t, DEF_scanEvent_rollback, events[0].Type)
+	require.Equal(t, "b-3", events[0].BlockHash)
+	require.Equal(t, DEF_scanEvent_rollback, events[1].Type)
+	require.Equal(t, "a-2", events[1].BlockHash)
+	require.Equal(t, "tx-c-2", events[2].Deposit.Txid)
+	require.Equal(t, int64(2), scanner.Height())
+	require.Equal(t, "c-2", scanner.BlockHash())
+
+	// blocks above tip are orphaned only, chain is same up to tip
+	chain.blocks = chain.blocks[:2]
+	events = nil
+	require.NoError(t, scanner.Scan(context.Background(), handler))
+	require.Len(t, events, 1)
+	require.Equal(t, DEF_scanEvent_rollback, events[0].Type)
+	require.Equal(t, "c-2", events[0].BlockHash)
+	require.Equal(t, "a-1", scanner.BlockHash())
+
+	// nothing changed
+	events = nil
+	require.NoError(t, scanner.Scan(context.Background(), handler))
+	require.Empty(t, events)
+}
+
+// testBatchChain fetches blocks in batch like Client
+type testBatchChain struct {
+	*testChain
+	batches int
+}
+
+func (t *testBatchChain) GetBlocksWithTx(ctx context.Context, from, to int64) (blockInfos []*btcjson.GetBlockVerboseTxResult, err error) {
+	t.batches++
+	for height := from; height <= to; height++ {
+		blockHash, err := t.GetBlockHash(ctx, height)
+		if err != nil {
+			return nil, err
+		}
+		blockInfo, err := t.GetBlockInfoWithTx(ctx, blockHash)
+		if err != nil {
+			return nil, err
+		}
+		blockInfos = append(blockInfos, blockInfo)
+	}
+	return blockInfos, nil
+}
+
+func TestScannerBatch(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	addrWatched, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(newTestKey(t, 1, true).SerializePubKey()), params)
+	require.NoError(t, err)
+	scriptWatched, err := txscript.PayToAddrScript(addrWatched)
+	require.NoError(t, err)
+
+	chain := &testBatchChain{testChain: &testChain{}}
+	for i := 0; i < 2*DEF_scanBatchBlocks+1; i++ {
+		chain.add("a", scriptWatched, 0.1)
+	}
+	scanner := newScanner(chain, params, 0, chain.blocks[0].Hash)
+	require.NoError(t, scanner.Watch(addrWatched.EncodeAddress()))
+
+	var events []*ScanEvent
+	handler := func(event *ScanEvent) error {
+		events = append(events, event)
+		return nil
+	}
+	// state is read while scanning ( go test -race )
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for scanner.Height() < int64(2*DEF_scanBatchBlocks) {
+			_ = scanner.BlockHash()
+		}
+	}()
+	require.NoError(t, scanner.Scan(context.Background(), handler))
+	<-done
+	require.Len(t, events, 2*DEF_scanBatchBlocks)
+	require.Equal(t, 2, chain.batches)
+	require.Equal(t, int64(2*DEF_scanBatchBlocks), scanner.Height())
+
+	// reorg of 1 block is detected in batch
+	chain.blocks = chain.blocks[:len(chain.blocks)-1]
+	chain.add("b", scriptWatched, 0.2)
+	chain.add("b", scriptWatched, 0.3)
+	events = nil
+	require.NoError(t, scanner.Scan(context.Background(), handler))
+	require.Len(t, events, 3)
+	require.Equal(t, DEF_scanEvent_rollback, events[0].Type)
+	require.Equal(t, "tx-b-20", events[1].Deposit.Txid)
+	require.Equal(t, "tx-b-21", events[2].Deposit.Txid)
+}
@@ -0,0 +1,57 @@
+package btc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// NewTaprootAddress returns P2TR address of private key ( BIP86 key path only, no script tree )
+func NewTaprootAddress(privKey string, params *chaincfg.Params) (address string, err error) {
+	wif, err := btcutil.DecodeWIF(privKey)
+	if err != nil {
+		return "", err
+	}
+	if wif.IsForNet(params) == false {
+		return "", fmt.Errorf("private key is not for network | %s", params.Name)
+	}
+	addr, err := btcutil.NewAddressTaproot(taprootOutputKey(wif.PrivKey.PubKey()), params)
+	if err != nil {
+		return "", err
+	}
+	return addr.EncodeAddress(), nil
+}
+
+// taprootOutputKey returns x-only output key of internal key tweaked without script tree
+func taprootOutputKey(internalKey *btcec.PublicKey) []byte {
+	return schnorr.SerializePubKey(txscript.ComputeTaprootKeyNoScript(internalKey))
+}
+
+// decodeAddress decodes address and checks network ( CashAddr or legacy for bitcoin cash )
+// btcutil decodes witness v1 program of 20 bytes as P2WPKH, so segwit address is checked by re-encoding
+func decodeAddress(address string, params *chaincfg.Params) (addr btcutil.Address, err error) {
+	if cashAddrPrefix(params) != "" {
+		if addr, err = DecodeCashAddr(address, params); err == nil {
+			return addr, nil
+		}
+	}
+	addr, err = btcutil.DecodeAddress(address, params)
+	if err != nil {
+		return nil, err
+	}
+	if addr.IsForNet(params) == false {
+		return nil, fmt.Errorf("address is not for network | %s | %s", address, params.Name)
+	}
+	switch addr.(type) {
+	case *btcutil.AddressWitnessPubKeyHash, *btcutil.AddressWitnessScriptHash, *btcutil.AddressTaproot:
+		if strings.EqualFold(addr.EncodeAddress(), address) == false {
+			return nil, fmt.Errorf("invalid segwit address | %s", address)
+		}
+	}
+	return addr, nil
+}
@@ -0,0 +1,160 @@
+package btc
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaprootAddress(t *testing.T) {
+	// BIP86 test vector ( m/86'/0'/0'/0/0 )
+	seed, err := hex.DecodeString(DEF_seed_test)
+	require.NoError(t, err)
+	wallet, err := NewHDWalletFromSeed(&chaincfg.MainNetParams, DEF_purpose_BIP86, seed, 0)
+	require.NoError(t, err)
+	wif, err := wallet.PrivKey(false, 0)
+	require.NoError(t, err)
+	address, err := NewTaprootAddress(wif, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	require.Equal(t, "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr", address)
+	_, err = NewTaprootAddress(wif, &chaincfg.RegressionNetParams)
+	require.Error(t, err)
+
+	// bech32m decoding
+	addr, err := decodeAddress(address, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	require.IsType(t, &btcutil.AddressTaproot{}, addr)
+	_, err = decodeAddress(address, &chaincfg.RegressionNetParams)
+	require.Error(t, err)
+
+	// witness v1 with bech32 checksum
+	program, err := bech32.ConvertBits(addr.ScriptAddress(), 8, 5, true)
+	require.NoError(t, err)
+	invalid, err := bech32.Encode("bc", append([]byte{1}, program...))
+	require.NoError(t, err)
+	_, err = decodeAddress(invalid, &chaincfg.MainNetParams)
+	require.Error(t, err)
+
+	// witness v1 of 20 bytes is not P2WPKH
+	program, err = bech32.ConvertBits(make([]byte, 20), 8, 5, true)
+	require.NoError(t, err)
+	invalid, err = bech32.EncodeM("bc", append([]byte{1}, program...))
+	require.NoError(t, err)
+	_, err = decodeAddress(invalid, &chaincfg.MainNetParams)
+	require.Error(t, err)
+}
+
+func TestTaprootSign(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	wifTaproot := newTestKey(t, 1, true)
+	wifSegwit := newTestKey(t, 2, true)
+
+	address, err := NewTaprootAddress(wifTaproot.String(), params)
+	require.NoError(t, err)
+	addrP2TR, err := decodeAddress(address, params)
+	require.NoError(t, err)
+	addrP2WPKH, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(wifSegwit.SerializePubKey()), params)
+	require.NoError(t, err)
+
+	// P2TR + P2WPKH -> P2TR
+	tx := &OfflineTx{}
+	tx.Init(params)
+	require.NoError(t, tx.AddFrom(newTestUnspent(t, addrP2TR, 0, 100000)))
+	require.NoError(t, tx.AddFrom(newTestUnspent(t, addrP2WPKH, 1, 200000)))
+	require.NoError(t, tx.AddTo(address, 290000))
+	fee, err := tx.EstimateFee(1)
+	require.NoError(t, err)
+
+	require.NoError(t, tx.AddKey(wifSegwit.String()))
+	_, err = tx.Sign()
+	require.Error(t, err) // taproot key missing
+	require.NoError(t, tx.AddKey(wifTaproot.String()))
+	msgTx, err := tx.Sign()
+	require.NoError(t, err)
+	require.Len(t, msgTx.TxIn[0].Witness, 1)
+	require.Len(t, msgTx.TxIn[0].Witness[0], DEF_sizeSchnorrSig)
+
+	// estimate covers signed tx, schnorr signature has fixed size
+	_, vsize := getRawTxSize(msgTx)
+	require.GreaterOrEqual(t, int64(fee), int64(vsize))
+	require.LessOrEqual(t, int64(fee)-int64(vsize), int64(1))
+}
+
+func TestTaprootPSBT(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	wif := newTestKey(t, 1, true)
+	address, err := NewTaprootAddress(wif.String(), params)
+	require.NoError(t, err)
+	addr, err := decodeAddress(address, params)
+	require.NoError(t, err)
+	pkScript, err := txscript.PayToAddrScript(addr)
+	require.NoError(t, err)
+
+	hash, err := chainhash.NewHashFromStr(DEF_txid_dummy)
+	require.NoError(t, err)
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, 0), nil, nil))
+	msgTx.AddTxOut(wire.NewTxOut(90000, pkScript))
+	prevOut := wire.NewTxOut(100000, pkScript)
+
+	p, err := NewPSBT(msgTx)
+	require.NoError(t, err)
+	p.Packet().Inputs[0].WitnessUtxo = prevOut
+
+	// other key is skipped
+	signed, err := p.Sign(newTestKey(t, 2, true).String())
+	require.NoError(t, err)
+	require.Equal(t, 0, signed)
+	signed, err = p.Sign(wif.String())
+	require.NoError(t, err)
+	require.Equal(t, 1, signed)
+
+	require.NoError(t, p.Finalize())
+	msgTxSigned, err := p.Extract()
+	require.NoError(t, err)
+
+	fetcher := txscript.NewCannedPrevOutputFetcher(prevOut.PkScript, prevOut.Value)
+	sigHashes := txscript.NewTxSigHashes(msgTxSigned, fetcher)
+	engine, err := txscript.NewEngine(prevOut.PkScript, msgTxSigned, 0, txscript.StandardVerifyFlags, nil, sigHashes, prevOut.Value, fetcher)
+	require.NoError(t, err)
+	require.NoError(t, engine.Execute())
+}
+
+func TestRawTxSignTaproot(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	wif := newTestKey(t, 1, true)
+	address, err := NewTaprootAddress(wif.String(), params)
+	require.NoError(t, err)
+	addr, err := decodeAddress(address, params)
+	require.NoError(t, err)
+	pkScript, err := txscript.PayToAddrScript(addr)
+	require.NoError(t, err)
+
+	rawTx := &RawTx{}
+	require.NoError(t, rawTx.Init(&Client{params: params}, address, 0))
+	require.NoError(t, rawTx.AddFrom(wif.String(), address))
+
+	hash, err := chainhash.NewHashFromStr(DEF_txid_dummy)
+	require.NoError(t, err)
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, 0), nil, nil))
+	msgTx.AddTxOut(wire.NewTxOut(90000, pkScript))
+	utxos := []*utxo{{Txid: DEF_txid_dummy, FromAddr: address, FromAmount: 0.001, ScriptPubKey: hex.EncodeToString(pkScript)}}
+
+	msgTxSigned, err := rawTx.sign(context.Background(), msgTx, utxos)
+	require.NoError(t, err)
+
+	fetcher := txscript.NewCannedPrevOutputFetcher(pkScript, 100000)
+	sigHashes := txscript.NewTxSigHashes(msgTxSigned, fetcher)
+	engine, err := txscript.NewEngine(pkScript, msgTxSigned, 0, txscript.StandardVerifyFlags, nil, sigHashes, 100000, fetcher)
+	require.NoError(t, err)
+	require.NoError(t, engine.Execute())
+}
@@ -0,0 +1,209 @@
+package btc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+type TimeLockType string
+
+const (
+	DEF_timeLock_CLTV TimeLockType = "cltv" // absolute, BIP65 ( nLockTime )
+	DEF_timeLock_CSV  TimeLockType = "csv"  // relative to confirmation of utxo, BIP112 ( nSequence )
+
+	DEF_lockTimeThreshold = txscript.LockTimeThreshold // nLockTime under this is block height, else unix time
+)
+
+// SequenceBlocks returns nSequence of relative lock by blocks ( BIP68 )
+func SequenceBlocks(blocks uint16) uint32 {
+	return uint32(blocks)
+}
+
+// SequenceSeconds returns nSequence of relative lock by time ( BIP68, rounded up to 512 seconds )
+func SequenceSeconds(seconds uint32) (sequence uint32, err error) {
+	units := (uint64(seconds) + (1 << wire.SequenceLockTimeGranularity) - 1) >> wire.SequenceLockTimeGranularity
+	if units > wire.SequenceLockTimeMask {
+		return 0, fmt.Errorf("relative lock time is too long | %d", seconds)
+	}
+	return wire.SequenceLockTimeIsSeconds | uint32(units), nil
+}
+
+// TimeLock is single key output spendable after lock time
+type TimeLock struct {
+	Type       TimeLockType
+	Lock       uint32 // nLockTime ( CLTV ) or nSequence ( CSV ) required to spend
+	PubKey     []byte
+	ScriptType MultiSigType // script hash type of address ( same as multisig )
+
+	Script        []byte // <lock> OP_CHECKLOCKTIMEVERIFY ( OP_CHECKSEQUENCEVERIFY ) OP_DROP <pubkey> OP_CHECKSIG
+	RedeemScript  []byte // P2SH, P2SH-P2WSH
+	WitnessScript []byte // P2SH-P2WSH, P2WSH
+	Address       btcutil.Address
+}
+
+// NewTimeLock creates time locked address of hex public key
+// lock of CLTV is block height or unix time, lock of CSV is nSequence ( SequenceBlocks, SequenceSeconds )
+func NewTimeLock(params *chaincfg.Params, scriptType MultiSigType, timeLockType TimeLockType, lock uint32, pubKey string) (timeLock *TimeLock, err error) {
+	var opLock byte
+	switch timeLockType {
+	case DEF_timeLock_CLTV:
+		opLock = txscript.OP_CHECKLOCKTIMEVERIFY
+		if lock == 0 {
+			return nil, fmt.Errorf("invalid lock time | %d", lock)
+		}
+	case DEF_timeLock_CSV:
+		opLock = txscript.OP_CHECKSEQUENCEVERIFY
+		if lock&wire.SequenceLockTimeMask == 0 || lock&^(wire.SequenceLockTimeIsSeconds|wire.SequenceLockTimeMask) != 0 {
+			return nil, fmt.Errorf("invalid relative lock | %d", lock)
+		}
+	default:
+		return nil, fmt.Errorf("invalid time lock type | %s", timeLockType)
+	}
+
+	pubKeyBytes, err := hex.DecodeString(pubKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key | %s | %w", pubKey, err)
+	}
+	addrPubKey, err := btcutil.NewAddressPubKey(pubKeyBytes, params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key | %s | %w", pubKey, err)
+	}
+	if scriptType != DEF_multiSig_P2SH && addrPubKey.Format() != btcutil.PKFCompressed {
+		return nil, fmt.Errorf("segwit time lock needs compressed public key | %s", pubKey)
+	}
+
+	timeLock = &TimeLock{
+		Type:       timeLockType,
+		Lock:       lock,
+		PubKey:     addrPubKey.ScriptAddress(),
+		ScriptType: scriptType,
+	}
+	timeLock.Script, err = txscript.NewScriptBuilder().
+		AddInt64(int64(lock)).AddOp(opLock).AddOp(txscript.OP_DROP).
+		AddData(timeLock.PubKey).AddOp(txscript.OP_CHECKSIG).
+		Script()
+	if err != nil {
+		return nil, err
+	}
+	timeLock.RedeemScript, timeLock.WitnessScript, timeLock.Address, err = payToScript(params, scriptType, timeLock.Script)
+	if err != nil {
+		return nil, err
+	}
+	return timeLock, nil
+}
+
+func (t *TimeLock) PkScript() (pkScript []byte, err error) {
+	return txscript.PayToAddrScript(t.Address)
+}
+
+func (t *TimeLock) isWitness() bool {
+	return t.ScriptType != DEF_multiSig_P2SH
+}
+
+// isByTime returns true if lock is unix time ( CLTV ) or multiple of 512 seconds ( CSV )
+func (t *TimeLock) isByTime() bool {
+	if t.Type == DEF_timeLock_CLTV {
+		return t.Lock >= DEF_lockTimeThreshold
+	}
+	return t.Lock&wire.SequenceLockTimeIsSeconds != 0
+}
+
+// IsMature returns true if utxo of confirmations can be spent at height of tip
+// lock by time can not be checked without median time past, it is left to node ( ErrTxNonFinal )
+func (t *TimeLock) IsMature(tipHeight, confirmations int64) bool {
+	if t.isByTime() == true {
+		return true
+	}
+	if t.Type == DEF_timeLock_CLTV {
+		return tipHeight >= int64(t.Lock) // mined in next block, nLockTime < height of block
+	}
+	return confirmations >= int64(t.Lock&wire.SequenceLockTimeMask)
+}
+
+// Apply sets nLockTime, nSequence and version of tx required to spend input idx
+func (t *TimeLock) Apply(msgTx *wire.MsgTx, idx int) (err error) {
+	txIn := msgTx.TxIn[idx]
+	switch t.Type {
+	case DEF_timeLock_CLTV:
+		if msgTx.LockTime != 0 && (msgTx.LockTime >= DEF_lockTimeThreshold) != t.isByTime() {
+			return fmt.Errorf("lock time type mismatch | tx : %d | input %d : %d", msgTx.LockTime, idx, t.Lock)
+		}
+		if msgTx.LockTime < t.Lock {
+			msgTx.LockTime = t.Lock
+		}
+		if txIn.Sequence == wire.MaxTxInSequenceNum {
+			txIn.Sequence = wire.MaxTxInSequenceNum - 1 // final input disables nLockTime
+		}
+	case DEF_timeLock_CSV:
+		if msgTx.Version < 2 {
+			msgTx.Version = 2 // BIP68
+		}
+		if txIn.Sequence&wire.SequenceLockTimeDisabled != 0 {
+			txIn.Sequence = t.Lock
+			return nil
+		}
+		if txIn.Sequence&wire.SequenceLockTimeIsSeconds != t.Lock&wire.SequenceLockTimeIsSeconds || txIn.Sequence&wire.SequenceLockTimeMask < t.Lock&wire.SequenceLockTimeMask {
+			return fmt.Errorf("sequence does not satisfy relative lock | input %d : %d | lock : %d", idx, txIn.Sequence, t.Lock)
+		}
+	}
+	return nil
+}
+
+//--------------------------------------------------------------------------------//
+// sign
+
+// Sign returns signature ( SIGHASH_ALL ) of input idx spending this time locked output
+// Apply must be called before, nLockTime and nSequence are committed by signature
+func (t *TimeLock) Sign(msgTx *wire.MsgTx, idx int, amount btcutil.Amount, sigHashes *txscript.TxSigHashes, wif *btcutil.WIF) (sig []byte, err error) {
+	if bytes.Equal(wif.SerializePubKey(), t.PubKey) == false {
+		return nil, fmt.Errorf("private key is not of time lock")
+	}
+	if t.isWitness() == true {
+		return txscript.RawTxInWitnessSignature(msgTx, sigHashes, idx, int64(amount), t.Script, txscript.SigHashAll, wif.PrivKey)
+	}
+	return txscript.RawTxInSignature(msgTx, idx, t.Script, txscript.SigHashAll, wif.PrivKey)
+}
+
+// Assemble sets final scriptSig / witness of txIn from signature
+func (t *TimeLock) Assemble(txIn *wire.TxIn, sig []byte) (err error) {
+	switch t.ScriptType {
+	case DEF_multiSig_P2SH:
+		txIn.SignatureScript, err = txscript.NewScriptBuilder().AddData(sig).AddData(t.RedeemScript).Script()
+		txIn.Witness = nil
+		return err
+
+	default:
+		txIn.Witness = wire.TxWitness{sig, t.WitnessScript}
+		txIn.SignatureScript = nil
+		if t.ScriptType == DEF_multiSig_P2SH_P2WSH {
+			txIn.SignatureScript, err = txscript.NewScriptBuilder().AddData(t.RedeemScript).Script()
+		}
+		return err
+	}
+}
+
+// isTimeLockScript returns true if script is <lock> OP_CHECKLOCKTIMEVERIFY ( OP_CHECKSEQUENCEVERIFY ) OP_DROP <pubkey> OP_CHECKSIG
+func isTimeLockScript(script []byte) bool {
+	tokenizer := txscript.MakeScriptTokenizer(0, script)
+	var ops []byte
+	var pubKey []byte
+	for tokenizer.Next() == true {
+		ops = append(ops, tokenizer.Opcode())
+		if len(ops) == 4 {
+			pubKey = tokenizer.Data()
+		}
+	}
+	if tokenizer.Err() != nil || len(ops) != 5 {
+		return false
+	}
+	if ops[1] != txscript.OP_CHECKLOCKTIMEVERIFY && ops[1] != txscript.OP_CHECKSEQUENCEVERIFY {
+		return false
+	}
+	return ops[2] == txscript.OP_DROP && (len(pubKey) == 33 || len(pubKey) == 65) && ops[4] == txscript.OP_CHECKSIG
+}
@@ -0,0 +1,189 @@
+package btc
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeLockSign(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	wif := newTestKey(t, 4, true)
+	pubKey := hex.EncodeToString(wif.SerializePubKey())
+	hash, err := chainhash.NewHashFromStr(DEF_txid_dummy)
+	require.NoError(t, err)
+	sequenceSeconds, err := SequenceSeconds(3600)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name         string
+		timeLockType TimeLockType
+		lock         uint32
+	}{
+		{"cltv height", DEF_timeLock_CLTV, 100},
+		{"cltv time", DEF_timeLock_CLTV, 1700000000},
+		{"csv blocks", DEF_timeLock_CSV, SequenceBlocks(144)},
+		{"csv seconds", DEF_timeLock_CSV, sequenceSeconds},
+	} {
+		for _, scriptType := range []MultiSigType{DEF_multiSig_P2SH, DEF_multiSig_P2SH_P2WSH, DEF_multiSig_P2WSH} {
+			t.Run(test.name+" "+string(scriptType), func(t *testing.T) {
+				timeLock, err := NewTimeLock(params, scriptType, test.timeLockType, test.lock, pubKey)
+				require.NoError(t, err)
+				require.True(t, isTimeLockScript(timeLock.Script))
+				pkScript, err := timeLock.PkScript()
+				require.NoError(t, err)
+				prevOut := wire.NewTxOut(100000, pkScript)
+
+				spend := func(apply bool) error {
+					msgTx := wire.NewMsgTx(1)
+					msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, 0), nil, nil))
+					msgTx.AddTxOut(wire.NewTxOut(90000, pkScript))
+					if apply == true {
+						require.NoError(t, timeLock.Apply(msgTx, 0))
+					}
+					fetcher := txscript.NewCannedPrevOutputFetcher(prevOut.PkScript, prevOut.Value)
+					sigHashes := txscript.NewTxSigHashes(msgTx, fetcher)
+					sig, err := timeLock.Sign(msgTx, 0, btcutil.Amount(prevOut.Value), sigHashes, wif)
+					require.NoError(t, err)
+					require.NoError(t, timeLock.Assemble(msgTx.TxIn[0], sig))
+
+					// size estimate covers signed input
+					estimator := &TxSizeEstimator{}
+					require.NoError(t, estimator.AddInput(pkScript, timeLock.RedeemScript, timeLock.WitnessScript))
+					estimator.AddOutput(pkScript)
+					_, vsizeSigned := getRawTxSize(msgTx)
+					require.GreaterOrEqual(t, estimator.VSize(), int64(vsizeSigned))
+
+					engine, err := txscript.NewEngine(prevOut.PkScript, msgTx, 0, txscript.StandardVerifyFlags, nil, sigHashes, prevOut.Value, fetcher)
+					require.NoError(t, err)
+					return engine.Execute()
+				}
+				require.NoError(t, spend(true))
+				require.Error(t, spend(false)) // nLockTime / nSequence not set
+			})
+		}
+	}
+
+	// invalid
+	_, err = NewTimeLock(params, DEF_multiSig_P2WSH, DEF_timeLock_CLTV, 0, pubKey)
+	require.Error(t, err)
+	_, err = NewTimeLock(params, DEF_multiSig_P2WSH, DEF_timeLock_CSV, wire.SequenceLockTimeDisabled|10, pubKey)
+	require.Error(t, err)
+	_, err = NewTimeLock(params, DEF_multiSig_P2WSH, DEF_timeLock_CSV, 0, pubKey)
+	require.Error(t, err)
+	_, err = NewTimeLock(params, DEF_multiSig_P2WSH, DEF_timeLock_CSV, SequenceBlocks(10), "02")
+	require.Error(t, err)
+	_, err = SequenceSeconds(512 * (wire.SequenceLockTimeMask + 1))
+	require.Error(t, err)
+	require.Equal(t, wire.SequenceLockTimeIsSeconds|uint32(8), sequenceSeconds)
+
+	// key not of time lock
+	timeLock, err := NewTimeLock(params, DEF_multiSig_P2WSH, DEF_timeLock_CLTV, 100, pubKey)
+	require.NoError(t, err)
+	_, err = timeLock.Sign(wire.NewMsgTx(wire.TxVersion), 0, 0, nil, newTestKey(t, 5, true))
+	require.Error(t, err)
+
+	// lock time of height and time can not be mixed
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, 0), nil, nil))
+	msgTx.LockTime = 1700000000
+	require.Error(t, timeLock.Apply(msgTx, 0))
+}
+
+func TestTimeLockMature(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	pubKey := hex.EncodeToString(newTestKey(t, 4, true).SerializePubKey())
+	cltv, err := NewTimeLock(params, DEF_multiSig_P2WSH, DEF_timeLock_CLTV, 100, pubKey)
+	require.NoError(t, err)
+	csv, err := NewTimeLock(params, DEF_multiSig_P2WSH, DEF_timeLock_CSV, SequenceBlocks(10), pubKey)
+	require.NoError(t, err)
+
+	require.False(t, cltv.IsMature(99, 50))
+	require.True(t, cltv.IsMature(100, 0))
+	require.False(t, csv.IsMature(200, 9))
+	require.True(t, csv.IsMature(200, 10))
+}
+
+func TestRawTxTimeLock(t *testing.T) {
+	client, _ := newTestRPCServer(t, map[string]string{"getblockcount": `150`})
+	params := client.params
+	wif := newTestKey(t, 4, true)
+	pubKey := hex.EncodeToString(wif.SerializePubKey())
+	cltv, err := NewTimeLock(params, DEF_multiSig_P2WSH, DEF_timeLock_CLTV, 120, pubKey)
+	require.NoError(t, err)
+	csv, err := NewTimeLock(params, DEF_multiSig_P2SH, DEF_timeLock_CSV, SequenceBlocks(10), pubKey)
+	require.NoError(t, err)
+	cltvScript, err := cltv.PkScript()
+	require.NoError(t, err)
+	csvScript, err := csv.PkScript()
+	require.NoError(t, err)
+
+	rawTx := &RawTx{}
+	require.NoError(t, rawTx.Init(client, cltv.Address.EncodeAddress(), 0))
+	require.NoError(t, rawTx.AddFromTimeLock(cltv, wif.String()))
+	require.NoError(t, rawTx.AddFromTimeLock(csv, wif.String()))
+	require.Error(t, rawTx.AddFromTimeLock(csv, newTestKey(t, 5, true).String()))
+	rawTx.SetLockTime(110)
+	rawTx.SetRBF(true)
+
+	// immature csv utxo is dropped
+	utxos := []*utxo{
+		{Txid: DEF_txid_dummy, Vout: 0, FromAmount: 0.001, ScriptPubKey: hex.EncodeToString(cltvScript), Confirmations: 1},
+		{Txid: DEF_txid_dummy, Vout: 1, FromAmount: 0.002, ScriptPubKey: hex.EncodeToString(csvScript), Confirmations: 12},
+		{Txid: DEF_txid_dummy, Vout: 2, FromAmount: 0.003, ScriptPubKey: hex.EncodeToString(csvScript), Confirmations: 9},
+	}
+	rawTx.utxoSetTimeLock(utxos)
+	utxos, err = rawTx.utxoMature(context.Background(), utxos)
+	require.NoError(t, err)
+	require.Len(t, utxos, 2)
+
+	hash, err := chainhash.NewHashFromStr(DEF_txid_dummy)
+	require.NoError(t, err)
+	msgTx := wire.NewMsgTx(1)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, 0), nil, nil))
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, 1), nil, nil))
+	msgTx.AddTxOut(wire.NewTxOut(290000, cltvScript))
+	for _, txIn := range msgTx.TxIn {
+		txIn.Sequence = DEF_sequenceRBF
+	}
+	require.NoError(t, rawTx.setLockTime(msgTx, utxos))
+	require.Equal(t, uint32(120), msgTx.LockTime)
+	require.Equal(t, int32(2), msgTx.Version)
+	require.Equal(t, uint32(DEF_sequenceRBF), msgTx.TxIn[0].Sequence)
+	require.Equal(t, SequenceBlocks(10), msgTx.TxIn[1].Sequence)
+	require.True(t, IsRBF(msgTx))
+
+	msgTxSigned, err := rawTx.sign(context.Background(), msgTx, utxos)
+	require.NoError(t, err)
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	fetcher.AddPrevOut(msgTx.TxIn[0].PreviousOutPoint, wire.NewTxOut(100000, cltvScript))
+	fetcher.AddPrevOut(msgTx.TxIn[1].PreviousOutPoint, wire.NewTxOut(200000, csvScript))
+	sigHashes := txscript.NewTxSigHashes(msgTxSigned, fetcher)
+	for i := range msgTxSigned.TxIn {
+		prevOut := fetcher.FetchPrevOutput(msgTxSigned.TxIn[i].PreviousOutPoint)
+		engine, err := txscript.NewEngine(prevOut.PkScript, msgTxSigned, i, txscript.StandardVerifyFlags, nil, sigHashes, prevOut.Value, fetcher)
+		require.NoError(t, err)
+		require.NoError(t, engine.Execute())
+	}
+
+	// sequence under csv lock
+	require.NoError(t, rawTx.SetSequence(DEF_txid_dummy, 1, SequenceBlocks(5)))
+	require.Error(t, rawTx.setLockTime(msgTx, utxos))
+
+	// nLockTime without time lock makes final inputs non final
+	rawTx = &RawTx{}
+	require.NoError(t, rawTx.Init(client, cltv.Address.EncodeAddress(), 0))
+	rawTx.SetLockTime(140)
+	msgTx = wire.NewMsgTx(wire.TxVersion)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, 3), nil, nil))
+	require.NoError(t, rawTx.setLockTime(msgTx, []*utxo{{Txid: DEF_txid_dummy, Vout: 3}}))
+	require.Equal(t, uint32(140), msgTx.LockTime)
+	require.Equal(t, uint32(wire.MaxTxInSequenceNum-1), msgTx.TxIn[0].Sequence)
+}
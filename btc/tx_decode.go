@@ -0,0 +1,182 @@
+package btc
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// DecodedTx is offline view of raw tx
+type DecodedTx struct {
+	Txid     string
+	Wtxid    string
+	Version  int32
+	LockTime uint32
+	Size     int
+	VSize    int
+	Weight   int
+	RBF      bool // BIP125 signaling
+	Inputs   []*DecodedTxIn
+	Outputs  []*DecodedTxOut
+
+	// fee is known only if prev outs of every input are supplied
+	HasFee  bool
+	Fee     btcutil.Amount
+	FeeRate FeeRate // sat/vB
+}
+
+type DecodedTxIn struct {
+	Txid      string
+	Vout      uint32
+	Sequence  uint32
+	ScriptSig string   // hex
+	Witness   []string // hex
+	Coinbase  bool
+	PrevOut   *DecodedTxOut // nil if not supplied
+}
+
+type DecodedTxOut struct {
+	Index        uint32 // index in tx, or vout of prev tx for prev out
+	Amount       btcutil.Amount
+	ScriptPubKey string // hex
+	ScriptType   string // txscript.ScriptClass ( pubkeyhash, witness_v0_keyhash, witness_v1_taproot, nulldata ... )
+	Addresses    []string
+	RequiredSigs int
+}
+
+// DecodeRawTx parses hex of raw tx ( with or without witness )
+func DecodeRawTx(rawTxHex string) (msgTx *wire.MsgTx, err error) {
+	raw, err := hex.DecodeString(rawTxHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid raw tx hex | %w", err)
+	}
+	msgTx = &wire.MsgTx{}
+	err = msgTx.Deserialize(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid raw tx | %w", err)
+	}
+	return msgTx, nil
+}
+
+// EncodeRawTx serializes tx to hex ( witness included )
+func EncodeRawTx(msgTx *wire.MsgTx) (rawTxHex string, err error) {
+	buf := bytes.NewBuffer(make([]byte, 0, msgTx.SerializeSize()))
+	err = msgTx.Serialize(buf)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf.Bytes()), nil
+}
+
+// InspectRawTx decodes hex of raw tx and inspects it
+func InspectRawTx(rawTxHex string, params *chaincfg.Params, prevOuts map[wire.OutPoint]*wire.TxOut) (decoded *DecodedTx, err error) {
+	msgTx, err := DecodeRawTx(rawTxHex)
+	if err != nil {
+		return nil, err
+	}
+	return InspectTx(msgTx, params, prevOuts)
+}
+
+// InspectTx decodes inputs, outputs and sizes of tx with addresses of params
+// prevOuts is optional ( nil ), fee is computed only if prev outs of every input are found
+func InspectTx(msgTx *wire.MsgTx, params *chaincfg.Params, prevOuts map[wire.OutPoint]*wire.TxOut) (decoded *DecodedTx, err error) {
+	size, vsize := getRawTxSize(msgTx)
+	decoded = &DecodedTx{
+		Txid:     msgTx.TxHash().String(),
+		Wtxid:    msgTx.WitnessHash().String(),
+		Version:  msgTx.Version,
+		LockTime: msgTx.LockTime,
+		Size:     size,
+		VSize:    vsize,
+		Weight:   msgTx.SerializeSizeStripped()*3 + size,
+		RBF:      IsRBF(msgTx),
+		Inputs:   make([]*DecodedTxIn, 0, len(msgTx.TxIn)),
+		Outputs:  make([]*DecodedTxOut, 0, len(msgTx.TxOut)),
+		HasFee:   len(msgTx.TxIn) > 0,
+	}
+
+	var amountIn, amountOut btcutil.Amount
+	for _, txIn := range msgTx.TxIn {
+		input := &DecodedTxIn{
+			Txid:      txIn.PreviousOutPoint.Hash.String(),
+			Vout:      txIn.PreviousOutPoint.Index,
+			Sequence:  txIn.Sequence,
+			ScriptSig: hex.EncodeToString(txIn.SignatureScript),
+			Witness:   make([]string, 0, len(txIn.Witness)),
+			Coinbase:  isCoinbaseIn(txIn),
+		}
+		for _, item := range txIn.Witness {
+			input.Witness = append(input.Witness, hex.EncodeToString(item))
+		}
+		if prevOut, ok := prevOuts[txIn.PreviousOutPoint]; ok == true && prevOut != nil {
+			input.PrevOut = decodeTxOut(prevOut, txIn.PreviousOutPoint.Index, params)
+			amountIn += input.PrevOut.Amount
+		} else {
+			decoded.HasFee = false
+		}
+		decoded.Inputs = append(decoded.Inputs, input)
+	}
+
+	for i, txOut := range msgTx.TxOut {
+		output := decodeTxOut(txOut, uint32(i), params)
+		amountOut += output.Amount
+		decoded.Outputs = append(decoded.Outputs, output)
+	}
+
+	if decoded.HasFee == true {
+		decoded.Fee = amountIn - amountOut
+		if decoded.Fee < 0 {
+			return nil, fmt.Errorf("output amount exceeds input amount | fee : %v", decoded.Fee)
+		}
+		decoded.FeeRate = FeeRate(float64(decoded.Fee) / float64(vsize))
+	}
+	return decoded, nil
+}
+
+// Inspect decodes tx with prev outs from node ( utxo set, then parent tx in mempool )
+func (t *RawTx) Inspect(ctx context.Context, msgTx *wire.MsgTx) (decoded *DecodedTx, err error) {
+	utxos, err := t.prevOutGet(ctx, msgTx)
+	if err != nil {
+		return nil, err
+	}
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut, len(utxos))
+	for i, utxo := range utxos {
+		amount, err := btcutil.NewAmount(utxo.FromAmount)
+		if err != nil {
+			return nil, err
+		}
+		pkScript, err := hex.DecodeString(utxo.ScriptPubKey)
+		if err != nil {
+			return nil, err
+		}
+		prevOuts[msgTx.TxIn[i].PreviousOutPoint] = wire.NewTxOut(int64(amount), pkScript)
+	}
+	return InspectTx(msgTx, t.client.params, prevOuts)
+}
+
+func decodeTxOut(txOut *wire.TxOut, index uint32, params *chaincfg.Params) *DecodedTxOut {
+	class, addrs, requiredSigs, _ := txscript.ExtractPkScriptAddrs(txOut.PkScript, params)
+	output := &DecodedTxOut{
+		Index:        index,
+		Amount:       btcutil.Amount(txOut.Value),
+		ScriptPubKey: hex.EncodeToString(txOut.PkScript),
+		ScriptType:   class.String(),
+		Addresses:    make([]string, 0, len(addrs)),
+		RequiredSigs: requiredSigs,
+	}
+	for _, addr := range addrs {
+		output.Addresses = append(output.Addresses, EncodeAddress(addr, params))
+	}
+	return output
+}
+
+func isCoinbaseIn(txIn *wire.TxIn) bool {
+	return txIn.PreviousOutPoint.Index == wire.MaxPrevOutIndex && txIn.PreviousOutPoint.Hash == (chainhash.Hash{})
+}
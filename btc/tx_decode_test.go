@@ -0,0 +1,78 @@
+package btc
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectTx(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	wif := newTestKey(t, 1, true)
+	addrFrom, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(wif.SerializePubKey()), params)
+	require.NoError(t, err)
+	addrTo, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(newTestKey(t, 2, true).SerializePubKey()), params)
+	require.NoError(t, err)
+
+	// P2WPKH ( 100000 ) -> P2PKH ( 60000 ) + P2WPKH ( 39000 )
+	unspent := newTestUnspent(t, addrFrom, 1, 100000)
+	tx := &OfflineTx{}
+	tx.Init(params)
+	require.NoError(t, tx.AddFrom(unspent))
+	require.NoError(t, tx.AddTo(addrTo.EncodeAddress(), 60000))
+	require.NoError(t, tx.AddTo(addrFrom.EncodeAddress(), 39000))
+	require.NoError(t, tx.AddKey(wif.String()))
+	msgTx, err := tx.Sign()
+	require.NoError(t, err)
+
+	rawTxHex, err := EncodeRawTx(msgTx)
+	require.NoError(t, err)
+	msgTxDecoded, err := DecodeRawTx(rawTxHex)
+	require.NoError(t, err)
+	require.Equal(t, msgTx.TxHash(), msgTxDecoded.TxHash())
+	_, err = DecodeRawTx(rawTxHex[:len(rawTxHex)-2])
+	require.Error(t, err)
+
+	// without prev outs
+	decoded, err := InspectRawTx(rawTxHex, params, nil)
+	require.NoError(t, err)
+	require.Equal(t, msgTx.TxHash().String(), decoded.Txid)
+	require.NotEqual(t, decoded.Txid, decoded.Wtxid)
+	require.False(t, decoded.HasFee)
+	require.False(t, decoded.RBF)
+	require.Equal(t, decoded.Weight, decoded.VSize*4-(4-decoded.Weight%4)%4)
+	require.Len(t, decoded.Inputs, 1)
+	require.Equal(t, DEF_txid_dummy, decoded.Inputs[0].Txid)
+	require.Equal(t, uint32(1), decoded.Inputs[0].Vout)
+	require.Len(t, decoded.Inputs[0].Witness, 2)
+	require.Nil(t, decoded.Inputs[0].PrevOut)
+	require.Len(t, decoded.Outputs, 2)
+	require.Equal(t, txscript.PubKeyHashTy.String(), decoded.Outputs[0].ScriptType)
+	require.Equal(t, []string{addrTo.EncodeAddress()}, decoded.Outputs[0].Addresses)
+	require.Equal(t, btcutil.Amount(60000), decoded.Outputs[0].Amount)
+	require.Equal(t, txscript.WitnessV0PubKeyHashTy.String(), decoded.Outputs[1].ScriptType)
+
+	// with prev outs
+	pkScript, err := txscript.PayToAddrScript(addrFrom)
+	require.NoError(t, err)
+	prevOuts := map[wire.OutPoint]*wire.TxOut{msgTx.TxIn[0].PreviousOutPoint: wire.NewTxOut(100000, pkScript)}
+	decoded, err = InspectTx(msgTx, params, prevOuts)
+	require.NoError(t, err)
+	require.True(t, decoded.HasFee)
+	require.Equal(t, btcutil.Amount(1000), decoded.Fee)
+	require.Equal(t, FeeRate(1000.0/float64(decoded.VSize)), decoded.FeeRate)
+	require.Equal(t, []string{addrFrom.EncodeAddress()}, decoded.Inputs[0].PrevOut.Addresses)
+
+	// rbf, outputs exceed inputs
+	msgTx.TxIn[0].Sequence = DEF_sequenceRBF
+	prevOuts[msgTx.TxIn[0].PreviousOutPoint] = wire.NewTxOut(90000, pkScript)
+	_, err = InspectTx(msgTx, params, prevOuts)
+	require.Error(t, err)
+	decoded, err = InspectTx(msgTx, params, nil)
+	require.NoError(t, err)
+	require.True(t, decoded.RBF)
+}
@@ -0,0 +1,212 @@
+package btc
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	DEF_sizeSig        = 72 // max DER signature + sighash type
+	DEF_sizeSchnorrSig = 64 // SIGHASH_DEFAULT
+	DEF_sizePubKey     = 33 // compressed
+	DEF_sizeOutPoint   = 32 + 4
+	DEF_sizeSequence   = 4
+	DEF_sizeTxOverhead = 4 + 4 // version + locktime
+)
+
+// FeeRate is fee per vbyte in satoshi
+type FeeRate float64
+
+// NewFeeRateFromBTCPerKB converts fee rate of bitcoin core ( btc/kB ) to sat/vB
+func NewFeeRateFromBTCPerKB(fee btcutil.Amount) FeeRate {
+	return FeeRate(float64(fee) / 1000)
+}
+
+// Fee returns fee of vsize ( round up )
+func (t FeeRate) Fee(vsize int64) btcutil.Amount {
+	return btcutil.Amount(math.Ceil(float64(vsize) * float64(t)))
+}
+
+//--------------------------------------------------------------------------------//
+// size estimator
+
+// TxSizeEstimator computes vsize of signed tx from script types without signing
+// signatures are counted as max size, so estimated vsize is never below the real one
+//
+// supported inputs : P2PKH ( compressed key ), P2WPKH, P2SH-P2WPKH, P2TR key path,
+// multisig in P2SH, P2SH-P2WSH and P2WSH
+type TxSizeEstimator struct {
+	inputCount  int
+	outputCount int
+	baseSize    int64 // non witness bytes of inputs and outputs
+	witnessSize int64
+	hasWitness  bool
+}
+
+// AddInput adds input spending pkScript
+// redeemScript is needed for P2SH multisig and P2SH-P2WSH ( empty P2SH is treated as P2SH-P2WPKH )
+// witnessScript is needed for P2WSH and P2SH-P2WSH
+func (t *TxSizeEstimator) AddInput(pkScript, redeemScript, witnessScript []byte) (err error) {
+	baseSize, witnessSize, err := estimateInputSize(pkScript, redeemScript, witnessScript)
+	if err != nil {
+		return err
+	}
+	t.inputCount++
+	t.baseSize += baseSize
+	if witnessSize > 0 {
+		t.witnessSize += witnessSize
+		t.hasWitness = true
+	} else {
+		t.witnessSize += 1 // empty witness stack count, only serialized if tx has witness
+	}
+	return nil
+}
+
+func (t *TxSizeEstimator) AddOutput(pkScript []byte) {
+	t.outputCount++
+	t.baseSize += outputSize(pkScript)
+}
+
+func (t *TxSizeEstimator) Weight() (weight int64) {
+	baseSize := int64(DEF_sizeTxOverhead+wire.VarIntSerializeSize(uint64(t.inputCount))+wire.VarIntSerializeSize(uint64(t.outputCount))) + t.baseSize
+	weight = baseSize * 4
+	if t.hasWitness == true {
+		weight += 2 + t.witnessSize // segwit marker, flag
+	}
+	return weight
+}
+
+func (t *TxSizeEstimator) VSize() int64 {
+	return (t.Weight() + 3) / 4
+}
+
+func (t *TxSizeEstimator) Fee(feeRate FeeRate) btcutil.Amount {
+	return feeRate.Fee(t.VSize())
+}
+
+//--------------------------------------------------------------------------------//
+// util
+
+// inputVSize returns vsize added by one input ( witness discount applied )
+func inputVSize(pkScript, redeemScript, witnessScript []byte) (vsize int64, err error) {
+	baseSize, witnessSize, err := estimateInputSize(pkScript, redeemScript, witnessScript)
+	if err != nil {
+		return 0, err
+	}
+	return (baseSize*4 + witnessSize + 3) / 4, nil
+}
+
+// inputWeight returns weight added by one input, non witness input of witness tx has empty witness ( 1 byte )
+func inputWeight(baseSize, witnessSize int64, txHasWitness bool) int64 {
+	weight := baseSize * 4
+	if txHasWitness == true {
+		weight += max(witnessSize, 1)
+	}
+	return weight
+}
+
+func outputSize(pkScript []byte) int64 {
+	return 8 + int64(wire.VarIntSerializeSize(uint64(len(pkScript)))+len(pkScript))
+}
+
+func estimateInputSize(pkScript, redeemScript, witnessScript []byte) (baseSize, witnessSize int64, err error) {
+	var sigScriptSize int64
+	switch class := txscript.GetScriptClass(pkScript); class {
+	case txscript.PubKeyHashTy:
+		sigScriptSize = 1 + DEF_sizeSig + 1 + DEF_sizePubKey
+
+	case txscript.WitnessV0PubKeyHashTy:
+		witnessSize = witnessP2WPKHSize()
+
+	case txscript.WitnessV1TaprootTy:
+		witnessSize = 1 + 1 + DEF_sizeSchnorrSig
+
+	case txscript.WitnessV0ScriptHashTy:
+		witnessSize, err = witnessScriptSize(witnessScript)
+		if err != nil {
+			return 0, 0, err
+		}
+
+	case txscript.ScriptHashTy:
+		if len(redeemScript) == 0 {
+			redeemScript = p2wpkhScript(make([]byte, 20)) // P2SH-P2WPKH
+		}
+		sigScriptSize = pushDataSize(len(redeemScript))
+		switch txscript.GetScriptClass(redeemScript) {
+		case txscript.WitnessV0PubKeyHashTy:
+			witnessSize = witnessP2WPKHSize()
+		case txscript.WitnessV0ScriptHashTy:
+			witnessSize, err = witnessScriptSize(witnessScript)
+			if err != nil {
+				return 0, 0, err
+			}
+		case txscript.MultiSigTy:
+			_, numSigs, err := txscript.CalcMultiSigStats(redeemScript)
+			if err != nil {
+				return 0, 0, err
+			}
+			sigScriptSize += 1 + int64(numSigs)*(1+DEF_sizeSig) // OP_0 for CHECKMULTISIG bug
+		case txscript.NonStandardTy:
+			if isTimeLockScript(redeemScript) == false {
+				return 0, 0, fmt.Errorf("unsupported redeem script type | %s", txscript.NonStandardTy)
+			}
+			sigScriptSize += 1 + DEF_sizeSig
+		default:
+			return 0, 0, fmt.Errorf("unsupported redeem script type | %s", txscript.GetScriptClass(redeemScript))
+		}
+
+	default:
+		return 0, 0, fmt.Errorf("unsupported script type | %s", class)
+	}
+
+	baseSize = DEF_sizeOutPoint + int64(wire.VarIntSerializeSize(uint64(sigScriptSize))) + sigScriptSize + DEF_sizeSequence
+	return baseSize, witnessSize, nil
+}
+
+func witnessP2WPKHSize() int64 {
+	return 1 + 1 + DEF_sizeSig + 1 + DEF_sizePubKey
+}
+
+// witnessScriptSize returns witness size of multisig or time lock witness script
+func witnessScriptSize(witnessScript []byte) (size int64, err error) {
+	if isTimeLockScript(witnessScript) == true {
+		// item count, signature, witness script
+		size = 1 + 1 + DEF_sizeSig
+		size += int64(wire.VarIntSerializeSize(uint64(len(witnessScript))) + len(witnessScript))
+		return size, nil
+	}
+	return witnessMultiSigSize(witnessScript)
+}
+
+// witness of multisig : item count, empty item, signatures, witness script
+func witnessMultiSigSize(witnessScript []byte) (size int64, err error) {
+	if len(witnessScript) == 0 {
+		return 0, fmt.Errorf("witness script is required")
+	}
+	_, numSigs, err := txscript.CalcMultiSigStats(witnessScript)
+	if err != nil {
+		return 0, fmt.Errorf("unsupported witness script | %w", err)
+	}
+	size = int64(wire.VarIntSerializeSize(uint64(numSigs+2))) + 1
+	size += int64(numSigs) * (1 + DEF_sizeSig)
+	size += int64(wire.VarIntSerializeSize(uint64(len(witnessScript))) + len(witnessScript))
+	return size, nil
+}
+
+// size of push opcode + data in script
+func pushDataSize(size int) int64 {
+	switch {
+	case size < txscript.OP_PUSHDATA1:
+		return int64(1 + size)
+	case size <= 0xff:
+		return int64(2 + size)
+	case size <= 0xffff:
+		return int64(3 + size)
+	default:
+		return int64(5 + size)
+	}
+}
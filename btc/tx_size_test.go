@@ -0,0 +1,110 @@
+package btc
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxSizeEstimateSigned(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	wifLegacy := newTestKey(t, 1, true)
+	wifSegwit := newTestKey(t, 2, true)
+	wifNested := newTestKey(t, 3, true)
+
+	addrP2PKH, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(wifLegacy.SerializePubKey()), params)
+	require.NoError(t, err)
+	addrP2WPKH, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(wifSegwit.SerializePubKey()), params)
+	require.NoError(t, err)
+	addrP2SH, err := btcutil.NewAddressScriptHash(p2wpkhScript(btcutil.Hash160(wifNested.SerializePubKey())), params)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name  string
+		addrs []btcutil.Address
+	}{
+		{"P2PKH", []btcutil.Address{addrP2PKH}},
+		{"P2WPKH", []btcutil.Address{addrP2WPKH}},
+		{"P2SH-P2WPKH", []btcutil.Address{addrP2SH}},
+		{"mixed", []btcutil.Address{addrP2PKH, addrP2WPKH, addrP2SH}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			tx := &OfflineTx{}
+			tx.Init(params)
+			for i, addr := range test.addrs {
+				require.NoError(t, tx.AddFrom(newTestUnspent(t, addr, uint32(i), 100000)))
+			}
+			require.NoError(t, tx.AddTo(addrP2PKH.EncodeAddress(), 50000))
+			require.NoError(t, tx.AddTo(addrP2WPKH.EncodeAddress(), 40000))
+			for _, wif := range []*btcutil.WIF{wifLegacy, wifSegwit, wifNested} {
+				require.NoError(t, tx.AddKey(wif.String()))
+			}
+			msgTx, err := tx.Sign()
+			require.NoError(t, err)
+			_, vsize := getRawTxSize(msgTx)
+
+			// estimate counts max signature size ( 1 byte per signature over at most )
+			fee, err := tx.EstimateFee(1)
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, int64(fee), int64(vsize))
+			require.LessOrEqual(t, int64(fee), int64(vsize+len(test.addrs)))
+		})
+	}
+}
+
+func TestTxSizeEstimateMultiSig(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	pubKeys := make([]*btcutil.AddressPubKey, 0, 3)
+	for i := byte(1); i <= 3; i++ {
+		pubKey, err := btcutil.NewAddressPubKey(newTestKey(t, i, true).SerializePubKey(), params)
+		require.NoError(t, err)
+		pubKeys = append(pubKeys, pubKey)
+	}
+	multiSigScript, err := txscript.MultiSigScript(pubKeys, 2)
+	require.NoError(t, err)
+
+	addrP2SH, err := btcutil.NewAddressScriptHash(multiSigScript, params)
+	require.NoError(t, err)
+	p2shScript, err := txscript.PayToAddrScript(addrP2SH)
+	require.NoError(t, err)
+
+	witnessScriptHash := sha256.Sum256(multiSigScript)
+	addrP2WSH, err := btcutil.NewAddressWitnessScriptHash(witnessScriptHash[:], params)
+	require.NoError(t, err)
+	p2wshScript, err := txscript.PayToAddrScript(addrP2WSH)
+	require.NoError(t, err)
+
+	addrP2SHP2WSH, err := btcutil.NewAddressScriptHash(p2wshScript, params)
+	require.NoError(t, err)
+	p2shP2wshScript, err := txscript.PayToAddrScript(addrP2SHP2WSH)
+	require.NoError(t, err)
+
+	// 2-of-3 multisig input vsize
+	vsize, err := inputVSize(p2shScript, multiSigScript, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(297), vsize)
+
+	vsize, err = inputVSize(p2wshScript, nil, multiSigScript)
+	require.NoError(t, err)
+	require.Equal(t, int64(105), vsize)
+
+	vsize, err = inputVSize(p2shP2wshScript, p2wshScript, multiSigScript)
+	require.NoError(t, err)
+	require.Equal(t, int64(140), vsize)
+
+	// witness script missing
+	_, err = inputVSize(p2wshScript, nil, nil)
+	require.Error(t, err)
+}
+
+func TestFeeRate(t *testing.T) {
+	// 0.00001 btc/kB = 1 sat/vB
+	feeRate := NewFeeRateFromBTCPerKB(1000)
+	require.Equal(t, FeeRate(1), feeRate)
+	require.Equal(t, btcutil.Amount(141), feeRate.Fee(141))
+	require.Equal(t, btcutil.Amount(212), FeeRate(1.5).Fee(141))
+}
@@ -0,0 +1,249 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/rabbitprincess/blockchain_rpc/btc"
+)
+
+func NewBtc(config *Config) (chain *Btc, err error) {
+	params, err := btcParams(config.Network)
+	if err != nil {
+		return nil, err
+	}
+	var opts []btc.Option
+	if config.Timeout > 0 {
+		opts = append(opts, btc.WithTimeout(config.Timeout))
+	}
+	if config.CAFile != "" {
+		opts = append(opts, btc.WithTLSCAFile(config.CAFile))
+	}
+	if config.BearerToken != "" {
+		opts = append(opts, btc.WithBearerToken(config.BearerToken))
+	}
+	if config.Proxy != "" {
+		opts = append(opts, btc.WithProxy(config.Proxy))
+	}
+
+	client := &btc.Client{}
+	err = client.Open(params, config.URL, config.User, config.Pass, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Btc{client: client}, nil
+}
+
+func btcParams(network string) (params *chaincfg.Params, err error) {
+	switch network {
+	case "", "mainnet":
+		return &chaincfg.MainNetParams, nil
+	case "testnet", "testnet3":
+		return &chaincfg.TestNet3Params, nil
+	case "regtest":
+		return &chaincfg.RegressionNetParams, nil
+	case "signet":
+		return &chaincfg.SigNetParams, nil
+	default:
+		return nil, fmt.Errorf("invalid btc network | %s", network)
+	}
+}
+
+type Btc struct {
+	client *btc.Client
+}
+
+type btcTx struct {
+	rawTx *btc.RawTx
+	msgTx *wire.MsgTx
+}
+
+func (t *Btc) Client() *btc.Client {
+	return t.client
+}
+
+func (t *Btc) Close() {
+	t.client.Close()
+}
+
+func (t *Btc) Type() Type {
+	return BTC
+}
+
+func (t *Btc) NewAddress(ctx context.Context) (privKey, address string, err error) {
+	return t.client.GetNewAddress(ctx)
+}
+
+func (t *Btc) GetBalance(ctx context.Context, address string) (balance string, err error) {
+	btcAddr, err := btc.DecodeAddress(address, t.client.Params())
+	if err != nil {
+		return "", err
+	}
+	addrInfo, err := t.client.GetAddressInfo(ctx, btcAddr.String())
+	if err != nil {
+		return "", err
+	}
+
+	// address in wallet -> listunspent, out wallet -> scantxoutset
+	var amount btcutil.Amount
+	if addrInfo.IsMine == true || addrInfo.IsWatchOnly == true {
+		unspents, err := t.client.GetListUnspent(ctx, 0, 0, btcAddr)
+		if err != nil {
+			return "", err
+		}
+		for _, unspent := range unspents {
+			unspentAmount, err := btcutil.NewAmount(unspent.Amount)
+			if err != nil {
+				return "", err
+			}
+			amount += unspentAmount
+		}
+	} else {
+		scanTxOutSet, err := t.client.ScanTxOutSet(ctx, btcAddr)
+		if err != nil {
+			return "", err
+		}
+		amount, err = btcutil.NewAmount(scanTxOutSet.TotalAmount)
+		if err != nil {
+			return "", err
+		}
+	}
+	return formatBtc(amount), nil
+}
+
+func (t *Btc) GetTxStatus(ctx context.Context, txid string) (status *TxStatus, err error) {
+	rawTxInfo, err := t.client.GetRawTxInfo(ctx, txid)
+	if err != nil {
+		return nil, err
+	}
+
+	status = &TxStatus{Txid: txid}
+	if rawTxInfo.BlockHash == "" {
+		status.Pending = true
+		return status, nil
+	}
+	blockInfo, err := t.client.GetBlockInfo(ctx, rawTxInfo.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	status.BlockHeight = uint64(blockInfo.Height)
+	status.Confirmations = rawTxInfo.Confirmations
+	return status, nil
+}
+
+func (t *Btc) GetBlockHeight(ctx context.Context) (height uint64, err error) {
+	blockCount, err := t.client.GetBlockCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(blockCount), nil
+}
+
+func (t *Btc) EstimateFee(ctx context.Context) (fee *Fee, err error) {
+	smartFee, err := t.client.GetSmartFee(ctx, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Fee{Rate: formatBtc(smartFee), Unit: "btc/kB"}, nil
+}
+
+// change is returned to from address
+func (t *Btc) BuildTransfer(ctx context.Context, transfer *Transfer) (tx *Tx, err error) {
+	amount, err := parseBtc(transfer.Amount)
+	if err != nil {
+		return nil, err
+	}
+	feeRate, err := t.client.GetSmartFeeRate(ctx, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rawTx := &btc.RawTx{}
+	err = rawTx.Init(t.client, transfer.FromAddr, 0)
+	if err != nil {
+		return nil, err
+	}
+	err = rawTx.SetFeeRate(feeRate)
+	if err != nil {
+		return nil, err
+	}
+	err = rawTx.AddFrom(transfer.FromPrivKey, transfer.FromAddr)
+	if err != nil {
+		return nil, err
+	}
+	err = rawTx.AddTo(transfer.ToAddr, amount)
+	if err != nil {
+		return nil, err
+	}
+	msgTx, err := rawTx.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Transfer: transfer, raw: &btcTx{rawTx: rawTx, msgTx: msgTx}}, nil
+}
+
+func (t *Btc) SignTransfer(ctx context.Context, tx *Tx) (err error) {
+	if err = checkTx(tx, false); err != nil {
+		return err
+	}
+	raw, ok := tx.raw.(*btcTx)
+	if ok == false {
+		return fmt.Errorf("invalid tx type | %T", tx.raw)
+	}
+	raw.msgTx, err = raw.rawTx.Sign(ctx, raw.msgTx)
+	if err != nil {
+		return err
+	}
+	tx.Signed = true
+	return nil
+}
+
+func (t *Btc) SendTransfer(ctx context.Context, tx *Tx) (txid string, err error) {
+	if err = checkTx(tx, true); err != nil {
+		return "", err
+	}
+	raw, ok := tx.raw.(*btcTx)
+	if ok == false {
+		return "", fmt.Errorf("invalid tx type | %T", tx.raw)
+	}
+	// dry run to catch policy rejection before broadcast
+	if _, err = raw.rawTx.Validate(ctx, raw.msgTx); err != nil {
+		return "", err
+	}
+	return raw.rawTx.Send(ctx, raw.msgTx)
+}
+
+// parseBtc parses decimal btc amount to satoshi exactly ( float is not used, more than 8 decimals is error )
+func parseBtc(amount string) (btcAmount btcutil.Amount, err error) {
+	integer, fraction, _ := strings.Cut(amount, ".")
+	if integer == "" && fraction == "" || len(fraction) > 8 {
+		return 0, fmt.Errorf("invalid btc amount | %s", amount)
+	}
+	if integer == "" {
+		integer = "0"
+	}
+	fraction += strings.Repeat("0", 8-len(fraction))
+
+	// ParseUint rejects sign, exponent and spaces
+	whole, err := strconv.ParseUint(integer, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid btc amount | %s | %w", amount, err)
+	}
+	satoshi, err := strconv.ParseUint(fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid btc amount | %s | %w", amount, err)
+	}
+	if whole > btcutil.MaxSatoshi/btcutil.SatoshiPerBitcoin {
+		return 0, fmt.Errorf("btc amount exceeds max supply | %s", amount)
+	}
+	return btcutil.Amount(whole*btcutil.SatoshiPerBitcoin + satoshi), nil
+}
+
+func formatBtc(amount btcutil.Amount) string {
+	return strconv.FormatFloat(amount.ToBTC(), 'f', -1, 64)
+}
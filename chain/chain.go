@@ -0,0 +1,123 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// chain agnostic wallet interface ( deposit / withdraw )
+//
+// amounts are decimal strings in the main unit of each chain ( btc, eth, xrp )
+type Chain interface {
+	Type() Type
+	Close()
+
+	// address
+	NewAddress(ctx context.Context) (privKey, address string, err error)
+	GetBalance(ctx context.Context, address string) (balance string, err error)
+
+	// tx
+	GetTxStatus(ctx context.Context, txid string) (status *TxStatus, err error)
+
+	// block
+	GetBlockHeight(ctx context.Context) (height uint64, err error)
+
+	// fee
+	EstimateFee(ctx context.Context) (fee *Fee, err error)
+
+	// transfer ( build -> sign -> send )
+	BuildTransfer(ctx context.Context, transfer *Transfer) (tx *Tx, err error)
+	SignTransfer(ctx context.Context, tx *Tx) (err error)
+	SendTransfer(ctx context.Context, tx *Tx) (txid string, err error)
+}
+
+var (
+	_ Chain = (*Btc)(nil)
+	_ Chain = (*Eth)(nil)
+	_ Chain = (*Xrp)(nil)
+)
+
+//---------------------------------------------------------------------------//
+// type
+
+type Type string
+
+const (
+	BTC Type = "btc"
+	ETH Type = "eth"
+	XRP Type = "xrp"
+)
+
+type TxStatus struct {
+	Txid          string
+	BlockHeight   uint64 // 0 if pending
+	Confirmations uint64
+	Pending       bool // not included in block yet
+	Failed        bool // included but failed ( eth reverted, xrp tec* )
+}
+
+type Fee struct {
+	Rate string // btc : btc per kB | eth : gas price in gwei | xrp : base fee in xrp
+	Unit string
+}
+
+type Transfer struct {
+	FromPrivKey string
+	FromAddr    string
+	ToAddr      string
+	Amount      string
+
+	Token   string // eth only - erc20 contract address ( empty is eth transfer )
+	DestTag uint32 // xrp only - destination tag
+}
+
+// Tx holds chain specific tx between BuildTransfer, SignTransfer and SendTransfer
+type Tx struct {
+	Transfer *Transfer
+	Signed   bool
+
+	raw interface{}
+}
+
+//---------------------------------------------------------------------------//
+// config
+
+type Config struct {
+	Type    Type
+	Network string // mainnet, testnet, regtest ...
+	URL     string
+	User    string
+	Pass    string
+
+	Timeout     time.Duration
+	CAFile      string // enables tls verified with ca bundle
+	BearerToken string
+	Proxy       string
+}
+
+func New(config *Config) (chain Chain, err error) {
+	switch config.Type {
+	case BTC:
+		return NewBtc(config)
+	case ETH:
+		return NewEth(config)
+	case XRP:
+		return NewXrp(config)
+	default:
+		return nil, fmt.Errorf("invalid chain type | %s", config.Type)
+	}
+}
+
+func checkTx(tx *Tx, signed bool) (err error) {
+	if tx == nil || tx.Transfer == nil || tx.raw == nil {
+		return fmt.Errorf("tx is not built")
+	}
+	if tx.Signed != signed {
+		if signed == true {
+			return fmt.Errorf("tx is not signed")
+		}
+		return fmt.Errorf("tx is already signed")
+	}
+	return nil
+}
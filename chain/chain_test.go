@@ -0,0 +1,67 @@
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/rabbitprincess/blockchain_rpc/xrp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInvalid(t *testing.T) {
+	_, err := New(&Config{Type: "doge"})
+	require.Error(t, err)
+
+	_, err = New(&Config{Type: BTC, Network: "unknown"})
+	require.Error(t, err)
+
+	_, err = New(&Config{Type: ETH, Network: "unknown"})
+	require.Error(t, err)
+}
+
+func TestTransferNotBuilt(t *testing.T) {
+	chain, err := New(&Config{Type: XRP, URL: "http://127.0.0.1:5005"})
+	require.NoError(t, err)
+
+	err = chain.SignTransfer(context.Background(), &Tx{})
+	require.Error(t, err)
+
+	_, err = chain.SendTransfer(context.Background(), &Tx{Transfer: &Transfer{}, raw: &xrpTx{}})
+	require.Error(t, err) // not signed
+}
+
+func TestCanceled(t *testing.T) {
+	chain, err := New(&Config{Type: XRP, URL: "http://127.0.0.1:5005"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = chain.GetBlockHeight(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestParseBtc(t *testing.T) {
+	for amount, satoshi := range map[string]btcutil.Amount{
+		"0.1":              10000000,
+		"0.29":             29000000,
+		"1.00000001":       100000001,
+		"20999999.9769":    2099999997690000,
+		".5":               50000000,
+		"3.":               300000000,
+		"0":                0,
+		"21000000.0000000": 2100000000000000,
+	} {
+		parsed, err := parseBtc(amount)
+		require.NoError(t, err, amount)
+		require.Equal(t, satoshi, parsed, amount)
+	}
+	for _, amount := range []string{"", ".", "-1", "+1", "1e-8", "0.000000001", "1.2.3", " 1", "21000001"} {
+		_, err := parseBtc(amount)
+		require.Error(t, err, amount)
+	}
+}
+
+func TestCloseNotOpened(t *testing.T) {
+	require.NotPanics(t, func() { (&Xrp{client: &xrp.Client{}}).Close() })
+}
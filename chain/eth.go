@@ -0,0 +1,197 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/rabbitprincess/blockchain_rpc/eth"
+)
+
+const (
+	DEF_gasLimit_eth   = 21000
+	DEF_gasLimit_erc20 = 100000
+)
+
+func NewEth(config *Config) (chain *Eth, err error) {
+	param, err := ethParams(config.Network)
+	if err != nil {
+		return nil, err
+	}
+	var opts []eth.Option
+	if config.Timeout > 0 {
+		opts = append(opts, eth.WithTimeout(config.Timeout))
+	}
+	if config.CAFile != "" {
+		opts = append(opts, eth.WithTLSCAFile(config.CAFile))
+	}
+	if config.User != "" {
+		opts = append(opts, eth.WithBasicAuth(config.User, config.Pass))
+	}
+	if config.BearerToken != "" {
+		opts = append(opts, eth.WithBearerToken(config.BearerToken))
+	}
+	if config.Proxy != "" {
+		opts = append(opts, eth.WithProxy(config.Proxy))
+	}
+	client, err := eth.NewClient(config.URL, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Eth{client: client, param: param}, nil
+}
+
+func ethParams(network string) (param *params.ChainConfig, err error) {
+	switch network {
+	case "", "mainnet":
+		return params.MainnetChainConfig, nil
+	case "goerli":
+		return params.GoerliChainConfig, nil
+	case "sepolia":
+		return params.SepoliaChainConfig, nil
+	case "ropsten":
+		return params.RopstenChainConfig, nil
+	case "rinkeby":
+		return params.RinkebyChainConfig, nil
+	default:
+		return nil, fmt.Errorf("invalid eth network | %s", network)
+	}
+}
+
+type Eth struct {
+	client *eth.Client
+	param  *params.ChainConfig
+}
+
+type ethTx struct {
+	rawTx *eth.RawTx
+	tx    *types.Transaction
+}
+
+func (t *Eth) Client() *eth.Client {
+	return t.client
+}
+
+func (t *Eth) Close() {
+	t.client.Close()
+}
+
+func (t *Eth) Type() Type {
+	return ETH
+}
+
+func (t *Eth) NewAddress(ctx context.Context) (privKey, address string, err error) {
+	return t.client.GetNewAddress()
+}
+
+func (t *Eth) GetBalance(ctx context.Context, address string) (balance string, err error) {
+	return t.client.GetAddressBalance(ctx, address)
+}
+
+func (t *Eth) GetTxStatus(ctx context.Context, txid string) (status *TxStatus, err error) {
+	status = &TxStatus{Txid: txid}
+	receipt, err := t.client.GetTxReceipt(ctx, txid)
+	if errors.Is(err, ethereum.NotFound) == true {
+		// no receipt yet - check tx pool
+		_, isPending, err := t.client.GetTxInfo(ctx, txid)
+		if err != nil {
+			return nil, err
+		}
+		status.Pending = isPending
+		return status, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	blockNumber, err := t.client.GetBlockMostRecent(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status.BlockHeight = receipt.BlockNumber.Uint64()
+	if blockNumber >= status.BlockHeight {
+		status.Confirmations = blockNumber - status.BlockHeight + 1
+	}
+	status.Failed = receipt.Status == types.ReceiptStatusFailed
+	return status, nil
+}
+
+func (t *Eth) GetBlockHeight(ctx context.Context) (height uint64, err error) {
+	return t.client.GetBlockMostRecent(ctx)
+}
+
+func (t *Eth) EstimateFee(ctx context.Context) (fee *Fee, err error) {
+	gasPriceWei, _, err := t.client.SuggestGasInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gasPriceGwei, err := eth.Conv_WeiToGwei(gasPriceWei.String())
+	if err != nil {
+		return nil, err
+	}
+	return &Fee{Rate: gasPriceGwei, Unit: "gwei/gas"}, nil
+}
+
+func (t *Eth) BuildTransfer(ctx context.Context, transfer *Transfer) (tx *Tx, err error) {
+	gasPriceWei, gasTipCapWei, err := t.client.SuggestGasInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := t.client.GetAddressNonce(ctx, transfer.FromAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	// fee cap = 2 * suggested gas price ( room for base fee increase, unused fee is refunded )
+	gasFeeCapWei := big.NewInt(0).Mul(gasPriceWei, big.NewInt(2))
+	if gasTipCapWei.Cmp(gasFeeCapWei) > 0 {
+		gasTipCapWei = gasFeeCapWei
+	}
+
+	var gasLimit, decimal uint64 = DEF_gasLimit_eth, 0
+	if transfer.Token != "" {
+		tokenInfo, err := t.client.GetErc20Info(ctx, transfer.Token)
+		if err != nil {
+			return nil, err
+		}
+		gasLimit, decimal = DEF_gasLimit_erc20, uint64(tokenInfo.Decimals)
+	}
+
+	rawTx := &eth.RawTx{}
+	rawTx.Init(t.client, t.param, gasTipCapWei, gasFeeCapWei, gasLimit, nonce, decimal, transfer.FromPrivKey, transfer.FromAddr, transfer.Token, transfer.ToAddr, transfer.Amount)
+	ethTypesTx, err := rawTx.Build()
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Transfer: transfer, raw: &ethTx{rawTx: rawTx, tx: ethTypesTx}}, nil
+}
+
+func (t *Eth) SignTransfer(ctx context.Context, tx *Tx) (err error) {
+	if err = checkTx(tx, false); err != nil {
+		return err
+	}
+	raw, ok := tx.raw.(*ethTx)
+	if ok == false {
+		return fmt.Errorf("invalid tx type | %T", tx.raw)
+	}
+	raw.tx, err = raw.rawTx.Sign(raw.tx)
+	if err != nil {
+		return err
+	}
+	tx.Signed = true
+	return nil
+}
+
+func (t *Eth) SendTransfer(ctx context.Context, tx *Tx) (txid string, err error) {
+	if err = checkTx(tx, true); err != nil {
+		return "", err
+	}
+	raw, ok := tx.raw.(*ethTx)
+	if ok == false {
+		return "", fmt.Errorf("invalid tx type | %T", tx.raw)
+	}
+	return raw.rawTx.Send(ctx, raw.tx)
+}
@@ -0,0 +1,177 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/rabbitprincess/blockchain_rpc/xrp"
+	"github.com/rabbitprincess/blockchain_rpc/xrp/types"
+)
+
+const (
+	DEF_xrp_tesSUCCESS = "tesSUCCESS"
+)
+
+func NewXrp(config *Config) (chain *Xrp, err error) {
+	var opts []xrp.Option
+	if config.Timeout > 0 {
+		opts = append(opts, xrp.WithTimeout(config.Timeout))
+	}
+	if config.CAFile != "" {
+		opts = append(opts, xrp.WithTLSCAFile(config.CAFile))
+	}
+	if config.User != "" {
+		opts = append(opts, xrp.WithBasicAuth(config.User, config.Pass))
+	}
+	if config.BearerToken != "" {
+		opts = append(opts, xrp.WithBearerToken(config.BearerToken))
+	}
+	if config.Proxy != "" {
+		opts = append(opts, xrp.WithProxy(config.Proxy))
+	}
+	client, err := xrp.NewClient(config.URL, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Xrp{client: client}, nil
+}
+
+type Xrp struct {
+	client *xrp.Client
+}
+
+type xrpTx struct {
+	payment *types.TransactionPayment
+	txBlob  string
+}
+
+func (t *Xrp) Client() *xrp.Client {
+	return t.client
+}
+
+func (t *Xrp) Close() {
+	t.client.Close()
+}
+
+func (t *Xrp) Type() Type {
+	return XRP
+}
+
+// privKey is master seed of account
+func (t *Xrp) NewAddress(ctx context.Context) (privKey, address string, err error) {
+	wallet, err := t.client.WalletPropose(ctx, "")
+	if err != nil {
+		return "", "", err
+	}
+	return wallet.MasterSeed, wallet.AccountID, nil
+}
+
+func (t *Xrp) GetBalance(ctx context.Context, address string) (balance string, err error) {
+	accountInfo, err := t.client.GetAccountInfo(ctx, address)
+	if err != nil {
+		return "", err
+	}
+	return xrp.Conv_DropToXrp(accountInfo.AccountData.Balance)
+}
+
+func (t *Xrp) GetTxStatus(ctx context.Context, txid string) (status *TxStatus, err error) {
+	txInfo, err := t.client.GetTxInfo(ctx, txid)
+	if err != nil {
+		return nil, err
+	}
+
+	status = &TxStatus{Txid: txid}
+	if txInfo.Validated == false {
+		status.Pending = true
+		return status, nil
+	}
+	height, err := t.GetBlockHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status.BlockHeight = uint64(txInfo.LedgerIndex)
+	if height >= status.BlockHeight {
+		status.Confirmations = height - status.BlockHeight + 1
+	}
+	status.Failed = txInfo.Metadata.TransactionResult != DEF_xrp_tesSUCCESS
+	return status, nil
+}
+
+// block height of xrp is last validated ledger index
+func (t *Xrp) GetBlockHeight(ctx context.Context) (height uint64, err error) {
+	ledger, err := t.client.GetLedgerLast(ctx, false)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(ledger.LedgerIndex, 10, 64)
+}
+
+func (t *Xrp) EstimateFee(ctx context.Context) (fee *Fee, err error) {
+	baseFeeDrop, err := t.client.GetBaseFee(ctx)
+	if err != nil {
+		return nil, err
+	}
+	baseFee, err := xrp.Conv_DropToXrp(baseFeeDrop)
+	if err != nil {
+		return nil, err
+	}
+	return &Fee{Rate: baseFee, Unit: "xrp/tx"}, nil
+}
+
+func (t *Xrp) BuildTransfer(ctx context.Context, transfer *Transfer) (tx *Tx, err error) {
+	amountDrop, err := xrp.Conv_XrpToDrop(transfer.Amount)
+	if err != nil {
+		return nil, err
+	}
+	feeDrop, err := t.client.GetBaseFee(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accountInfo, err := t.client.GetAccountInfo(ctx, transfer.FromAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	payment := &types.TransactionPayment{
+		TransactionType: types.Tx_Payment,
+		Account:         transfer.FromAddr,
+		Fee:             feeDrop,
+		Sequence:        uint32(accountInfo.AccountData.Sequence),
+		Destination:     transfer.ToAddr,
+		Amount:          amountDrop,
+		DestinationTag:  transfer.DestTag,
+	}
+	return &Tx{Transfer: transfer, raw: &xrpTx{payment: payment}}, nil
+}
+
+func (t *Xrp) SignTransfer(ctx context.Context, tx *Tx) (err error) {
+	if err = checkTx(tx, false); err != nil {
+		return err
+	}
+	raw, ok := tx.raw.(*xrpTx)
+	if ok == false {
+		return fmt.Errorf("invalid tx type | %T", tx.raw)
+	}
+	raw.txBlob, err = t.client.SignTransaction(ctx, raw.payment, tx.Transfer.FromPrivKey)
+	if err != nil {
+		return err
+	}
+	tx.Signed = true
+	return nil
+}
+
+func (t *Xrp) SendTransfer(ctx context.Context, tx *Tx) (txid string, err error) {
+	if err = checkTx(tx, true); err != nil {
+		return "", err
+	}
+	raw, ok := tx.raw.(*xrpTx)
+	if ok == false {
+		return "", fmt.Errorf("invalid tx type | %T", tx.raw)
+	}
+	res, err := t.client.SendTransaction(ctx, raw.txBlob)
+	if err != nil {
+		return "", err
+	}
+	return res.TxJSON.Hash, nil
+}
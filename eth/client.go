@@ -1,34 +1,61 @@
-package eth
-
-import (
-	"github.com/ethereum/go-ethereum/ethclient"
-)
-
-func NewClient(url string) (*Client, error) {
-	client := &Client{}
-	err := client.Open(url)
-	if err != nil {
-		return nil, err
-	}
-	return client, nil
-}
-
-type Client struct {
-	rpc *ethclient.Client
-}
-
-func (t *Client) Open(url string) (err error) {
-	t.rpc, err = ethclient.Dial(url)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func (t *Client) Close() {
-	if t.rpc == nil {
-		return
-	}
-	t.rpc.Close()
-	t.rpc = nil
-}
+package eth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/rabbitprincess/blockchain_rpc/internal/httpopt"
+)
+
+func NewClient(url string, opts ...Option) (*Client, error) {
+	client := &Client{}
+	err := client.Open(url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+type Client struct {
+	rpc *ethclient.Client
+}
+
+// options are only applied to http / https endpoint ( ws, ipc use default dialer )
+func (t *Client) Open(url string, opts ...Option) (err error) {
+	if len(opts) == 0 {
+		t.rpc, err = ethclient.Dial(url)
+		if err != nil {
+			return err
+		}
+		return nil
+	}
+
+	if strings.HasPrefix(url, "http://") == false && strings.HasPrefix(url, "https://") == false {
+		return fmt.Errorf("options are supported for http endpoint only | %s", url)
+	}
+	config := &httpopt.Config{}
+	for _, opt := range opts {
+		if err = opt(config); err != nil {
+			return err
+		}
+	}
+	rpcClient, err := rpc.DialHTTPWithClient(url, config.HTTPClient())
+	if err != nil {
+		return err
+	}
+	for key, value := range config.Headers {
+		rpcClient.SetHeader(key, value)
+	}
+	t.rpc = ethclient.NewClient(rpcClient)
+	return nil
+}
+
+func (t *Client) Close() {
+	if t.rpc == nil {
+		return
+	}
+	t.rpc.Close()
+	t.rpc = nil
+}
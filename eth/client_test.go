@@ -1,6 +1,7 @@
 package eth
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -51,16 +52,16 @@ func TestWithdraw(t *testing.T) {
 
 	// 출금 과정
 	{
-		gasPrice, gasTip, err := client.SuggestGasInfo()
+		gasPrice, gasTip, err := client.SuggestGasInfo(context.Background())
 		require.NoError(t, err)
-		nonce, err := client.GetAddressNonce(from)
+		nonce, err := client.GetAddressNonce(context.Background(), from)
 		require.NoError(t, err)
-		tokenInfo, err := client.GetErc20Info(contract)
+		tokenInfo, err := client.GetErc20Info(context.Background(), contract)
 		require.NoError(t, err)
 
 		rawTx := &RawTx{}
 		rawTx.Init(client, params.TestChainConfig, gasPrice, gasTip, 100000, nonce+1, uint64(tokenInfo.Decimals), privKey, from, contract, to, amount)
-		txid, err := rawTx.SendTx()
+		txid, err := rawTx.SendTx(context.Background())
 		require.NoError(t, err)
 		fmt.Println("tx send success, txid : ", txid)
 	}
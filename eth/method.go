@@ -16,18 +16,16 @@ import (
 //-------------------------------------------------------------------------------------------//
 // server
 
-func (t *Client) GetServerInfo() (status *ethereum.SyncProgress, err error) {
-	return t.rpc.SyncProgress(context.Background())
+func (t *Client) GetServerInfo(ctx context.Context) (status *ethereum.SyncProgress, err error) {
+	return t.rpc.SyncProgress(ctx)
 }
 
-func (t *Client) SuggestGasInfo() (gasPriceWei, gasTipCapWei *big.Int, err error) {
-	context := context.Background()
-
-	gasPriceWei, err = t.rpc.SuggestGasPrice(context)
+func (t *Client) SuggestGasInfo(ctx context.Context) (gasPriceWei, gasTipCapWei *big.Int, err error) {
+	gasPriceWei, err = t.rpc.SuggestGasPrice(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
-	gasTipCapWei, err = t.rpc.SuggestGasTipCap(context)
+	gasTipCapWei, err = t.rpc.SuggestGasTipCap(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -61,32 +59,32 @@ func (t *Client) GetNewAddress() (privKey, address string, err error) {
 	return privKey, address, nil
 }
 
-func (t *Client) GetAddressBalance(address string) (balance string, err error) {
-	wei, err := t.rpc.BalanceAt(context.Background(), common.HexToAddress(address), nil)
+func (t *Client) GetAddressBalance(ctx context.Context, address string) (balance string, err error) {
+	wei, err := t.rpc.BalanceAt(ctx, common.HexToAddress(address), nil)
 	if err != nil {
 		return "", err
 	}
 	return Conv_WeiToEth(wei.String())
 }
 
-func (t *Client) GetAddressNonce(address string) (nonce uint64, err error) {
-	return t.rpc.PendingNonceAt(context.Background(), common.HexToAddress(address))
+func (t *Client) GetAddressNonce(ctx context.Context, address string) (nonce uint64, err error) {
+	return t.rpc.PendingNonceAt(ctx, common.HexToAddress(address))
 }
 
-func (t *Client) GetAddressCode(address string, blockNumber uint64) (byteCode []byte, err error) {
+func (t *Client) GetAddressCode(ctx context.Context, address string, blockNumber uint64) (byteCode []byte, err error) {
 	var blockNumberBig *big.Int
 	if blockNumber > 0 {
 		blockNumberBig = big.NewInt(int64(blockNumber))
 	}
-	return t.rpc.CodeAt(context.Background(), common.HexToAddress(address), blockNumberBig)
+	return t.rpc.CodeAt(ctx, common.HexToAddress(address), blockNumberBig)
 }
 
-func (t *Client) ValidAddress(address string) (isContract bool, err error) {
+func (t *Client) ValidAddress(ctx context.Context, address string) (isContract bool, err error) {
 	valid := AddressValid(address)
 	if valid == false {
 		return valid, fmt.Errorf("invalid address | %s", address)
 	}
-	code, err := t.GetAddressCode(address, 0)
+	code, err := t.GetAddressCode(ctx, address, 0)
 	if err != nil {
 		return false, err
 	}
@@ -97,31 +95,31 @@ func (t *Client) ValidAddress(address string) (isContract bool, err error) {
 //-------------------------------------------------------------------------------------------//
 // tx
 
-func (t *Client) GetTxInfo(txid string) (txInfo *types.Transaction, isPending bool, err error) {
+func (t *Client) GetTxInfo(ctx context.Context, txid string) (txInfo *types.Transaction, isPending bool, err error) {
 	ethTxHash := common.HexToHash(txid)
-	return t.rpc.TransactionByHash(context.Background(), ethTxHash)
+	return t.rpc.TransactionByHash(ctx, ethTxHash)
 }
 
-func (t *Client) GetTxReceipt(txid string) (txReceipt *types.Receipt, err error) {
+func (t *Client) GetTxReceipt(ctx context.Context, txid string) (txReceipt *types.Receipt, err error) {
 	ethTxHash := common.HexToHash(txid)
-	return t.rpc.TransactionReceipt(context.Background(), ethTxHash)
+	return t.rpc.TransactionReceipt(ctx, ethTxHash)
 }
 
-func (t *Client) SendTx(tx *types.Transaction) (err error) {
-	return t.rpc.SendTransaction(context.Background(), tx)
+func (t *Client) SendTx(ctx context.Context, tx *types.Transaction) (err error) {
+	return t.rpc.SendTransaction(ctx, tx)
 }
 
 //-------------------------------------------------------------------------------------------//
 // block
 
-func (t *Client) GetBlockMostRecent() (blockNumber uint64, err error) {
-	return t.rpc.BlockNumber(context.Background())
+func (t *Client) GetBlockMostRecent(ctx context.Context) (blockNumber uint64, err error) {
+	return t.rpc.BlockNumber(ctx)
 }
 
-func (t *Client) GetBlockInfo(blockNumber uint64) (blockInfo *types.Block, err error) {
-	return t.rpc.BlockByNumber(context.Background(), big.NewInt(int64(blockNumber)))
+func (t *Client) GetBlockInfo(ctx context.Context, blockNumber uint64) (blockInfo *types.Block, err error) {
+	return t.rpc.BlockByNumber(ctx, big.NewInt(int64(blockNumber)))
 }
 
-func (t *Client) GetBlockInfoByHash(blockHash string) (blockInfo *types.Block, err error) {
-	return t.rpc.BlockByHash(context.Background(), common.HexToHash(blockHash))
+func (t *Client) GetBlockInfoByHash(ctx context.Context, blockHash string) (blockInfo *types.Block, err error) {
+	return t.rpc.BlockByHash(ctx, common.HexToHash(blockHash))
 }
@@ -21,7 +21,7 @@ type ApprovalLog struct {
 	Tokens     *big.Int
 }
 
-func (t *Client) FilterLogs(contractAddresses []string, blockHash string) (logs []*types.Log, err error) {
+func (t *Client) FilterLogs(ctx context.Context, contractAddresses []string, blockHash string) (logs []*types.Log, err error) {
 	ethBlockHash := common.HexToHash(blockHash)
 
 	var ethContractAddresses []common.Address
@@ -37,7 +37,7 @@ func (t *Client) FilterLogs(contractAddresses []string, blockHash string) (logs
 		Addresses: ethContractAddresses,
 		Topics:    nil,
 	}
-	typesLogs, err := t.rpc.FilterLogs(context.Background(), query)
+	typesLogs, err := t.rpc.FilterLogs(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -72,7 +72,7 @@ type Erc20Info struct {
 	TotalSupply string
 }
 
-func (t *Client) GetErc20Info(contractAddr string) (info *Erc20Info, err error) {
+func (t *Client) GetErc20Info(ctx context.Context, contractAddr string) (info *Erc20Info, err error) {
 	ethContractAddr := common.HexToAddress(contractAddr)
 	token, err := token.NewToken(ethContractAddr, t.rpc)
 	if err != nil {
@@ -84,7 +84,7 @@ func (t *Client) GetErc20Info(contractAddr string) (info *Erc20Info, err error)
 		return info, nil
 	}
 
-	opts := &bind.CallOpts{}
+	opts := &bind.CallOpts{Context: ctx}
 	info.Name, err = token.Name(opts)
 	if err != nil {
 		return nil, err
@@ -107,9 +107,9 @@ func (t *Client) GetErc20Info(contractAddr string) (info *Erc20Info, err error)
 	return info, nil
 }
 
-func (t *Client) GetErc20BalanceOf(addr string, contractAddr string) (balance string, err error) {
+func (t *Client) GetErc20BalanceOf(ctx context.Context, addr string, contractAddr string) (balance string, err error) {
 	// decimal 추출
-	info, err := t.GetErc20Info(contractAddr)
+	info, err := t.GetErc20Info(ctx, contractAddr)
 	if err != nil {
 		return "", err
 	}
@@ -118,7 +118,7 @@ func (t *Client) GetErc20BalanceOf(addr string, contractAddr string) (balance st
 	if err != nil {
 		return "", err
 	}
-	bigBalance, err := token.BalanceOf(&bind.CallOpts{}, common.HexToAddress(addr))
+	bigBalance, err := token.BalanceOf(&bind.CallOpts{Context: ctx}, common.HexToAddress(addr))
 	if err != nil {
 		return "", err
 	}
@@ -2,6 +2,7 @@ package eth
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"testing"
 
@@ -16,7 +17,7 @@ func TestServerInfo(t *testing.T) {
 	}
 	defer client.Close()
 
-	info, err := client.GetServerInfo()
+	info, err := client.GetServerInfo(context.Background())
 	if err != nil {
 		t.Fatal("get server info fail |", err)
 	}
@@ -36,7 +37,7 @@ func TestGetAddressBalance(t *testing.T) {
 	require.NoError(t, err)
 	defer client.Close()
 
-	balance, err := client.GetAddressBalance(DEF_Address)
+	balance, err := client.GetAddressBalance(context.Background(), DEF_Address)
 	require.NoError(t, err, "balance at failed")
 	fmt.Printf("balance : %s\n", balance)
 }
@@ -49,7 +50,7 @@ func TestFeeSuggestGas(t *testing.T) {
 	require.NoError(t, err)
 	defer client.Close()
 
-	price, tipcap, err := client.SuggestGasInfo()
+	price, tipcap, err := client.SuggestGasInfo(context.Background())
 	require.NoError(t, err)
 
 	gasPriceGwei, err := Conv_WeiToGwei(price.String())
@@ -70,15 +71,15 @@ func TestTxGetRawData(t *testing.T) {
 	require.NoError(t, err)
 	defer client.Close()
 
-	blockNumber, err := client.GetBlockMostRecent()
+	blockNumber, err := client.GetBlockMostRecent(context.Background())
 	require.NoError(t, err)
 
-	blockInfo, err := client.GetBlockInfo(blockNumber)
+	blockInfo, err := client.GetBlockInfo(context.Background(), blockNumber)
 	require.NoError(t, err)
 
 	txInfos := blockInfo.Transactions()
 	txLast := txInfos[blockInfo.Transactions().Len()-1]
-	txRaw, _, err := client.GetTxInfo(txLast.Hash().Hex())
+	txRaw, _, err := client.GetTxInfo(context.Background(), txLast.Hash().Hex())
 	require.NoError(t, err, "get tx failed")
 
 	hexTxid, err := EncodeTxRLP(txRaw)
@@ -103,12 +104,12 @@ func TestGetErc20Info(t *testing.T) {
 	defer client.Close()
 
 	// BNB
-	info, err := client.GetErc20Info(DEF_TokenAERGO)
+	info, err := client.GetErc20Info(context.Background(), DEF_TokenAERGO)
 	require.NoError(t, err)
 	fmt.Println(info.IsFunded, info.Name, info.Symbol, info.TotalSupply)
 
 	// KCH
-	info, err = client.GetErc20Info(DEF_TokenKCH)
+	info, err = client.GetErc20Info(context.Background(), DEF_TokenKCH)
 	require.NoError(t, err)
 	fmt.Println(info.IsFunded, info.Name, info.Symbol, info.TotalSupply)
 }
@@ -118,7 +119,7 @@ func TestGetErc20TokenBalance(t *testing.T) {
 	require.NoError(t, err)
 	defer client.Close()
 
-	balance, err := client.GetErc20BalanceOf(DEF_Address, DEF_TokenKCH)
+	balance, err := client.GetErc20BalanceOf(context.Background(), DEF_Address, DEF_TokenKCH)
 	require.NoError(t, err)
 
 	fmt.Println(balance)
@@ -130,12 +131,12 @@ func TestGetErc20QueryAndReceipt(t *testing.T) {
 	defer client.Close()
 
 	// get block info
-	blockInfo, err := client.GetBlockInfo(15934533)
+	blockInfo, err := client.GetBlockInfo(context.Background(), 15934533)
 	require.NoError(t, err)
 	blockHash := blockInfo.Hash().String()
 
 	// get logs in block ( aergo token only )
-	logs, err := client.FilterLogs([]string{DEF_TokenAERGO}, blockHash)
+	logs, err := client.FilterLogs(context.Background(), []string{DEF_TokenAERGO}, blockHash)
 	require.NoError(t, err)
 
 	// decode logs ( transfer only )
@@ -155,10 +156,10 @@ func TestGetAddressFrom(t *testing.T) {
 	require.NoError(t, err)
 	defer client.Close()
 
-	blockNumber, err := client.GetBlockMostRecent()
+	blockNumber, err := client.GetBlockMostRecent(context.Background())
 	require.NoError(t, err)
 
-	blockInfo, err := client.GetBlockInfo(blockNumber)
+	blockInfo, err := client.GetBlockInfo(context.Background(), blockNumber)
 	require.NoError(t, err)
 
 	for _, tx := range blockInfo.Transactions() {
@@ -176,11 +177,11 @@ func TestBlockGetInfo(t *testing.T) {
 	require.NoError(t, err)
 	defer client.Close()
 
-	blockNumber, err := client.GetBlockMostRecent()
+	blockNumber, err := client.GetBlockMostRecent(context.Background())
 	require.NoError(t, err)
 
 	fmt.Printf("%v\n", blockNumber)
-	blockInfo, err := client.GetBlockInfo(blockNumber)
+	blockInfo, err := client.GetBlockInfo(context.Background(), blockNumber)
 	require.NoError(t, err)
 
 	if err != nil {
@@ -205,10 +206,10 @@ func TestBlockEncode(t *testing.T) {
 	require.NoError(t, err)
 	defer client.Close()
 
-	blockNumber, err := client.GetBlockMostRecent()
+	blockNumber, err := client.GetBlockMostRecent(context.Background())
 	require.NoError(t, err)
 
-	blockInfo, err := client.GetBlockInfo(blockNumber)
+	blockInfo, err := client.GetBlockInfo(context.Background(), blockNumber)
 	require.NoError(t, err)
 
 	blockRLP, err := EncodeBlockRLP(blockInfo)
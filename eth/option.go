@@ -0,0 +1,55 @@
+package eth
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/rabbitprincess/blockchain_rpc/internal/httpopt"
+)
+
+// Option configures http connection of Client ( http / https endpoint only )
+type Option = httpopt.Option
+
+// WithTimeout sets timeout of every http request
+func WithTimeout(timeout time.Duration) Option {
+	return httpopt.WithTimeout(timeout)
+}
+
+// WithTransport replaces default http transport ( tls and proxy options are applied on top of it, tls settings of transport are kept )
+func WithTransport(transport *http.Transport) Option {
+	return httpopt.WithTransport(transport)
+}
+
+// WithTLS sets ca bundle to verify node certificate
+func WithTLS(caPEM []byte) Option {
+	return httpopt.WithTLS(caPEM)
+}
+
+func WithTLSCAFile(caFile string) Option {
+	return httpopt.WithTLSCAFile(caFile)
+}
+
+// WithClientCert sets client certificate for mutual tls
+func WithClientCert(certPEM, keyPEM []byte) Option {
+	return httpopt.WithClientCert(certPEM, keyPEM)
+}
+
+func WithInsecureSkipVerify() Option {
+	return httpopt.WithInsecureSkipVerify()
+}
+
+func WithBasicAuth(user, pass string) Option {
+	return httpopt.WithBasicAuth(user, pass)
+}
+
+func WithBearerToken(token string) Option {
+	return httpopt.WithBearerToken(token)
+}
+
+func WithHeader(key, value string) Option {
+	return httpopt.WithHeader(key, value)
+}
+
+func WithProxy(proxyURL string) Option {
+	return httpopt.WithProxy(proxyURL)
+}
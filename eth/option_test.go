@@ -0,0 +1,96 @@
+package eth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionTLSAndAuth(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+	}))
+	defer server.Close()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	// without ca - unknown authority
+	client, err := NewClient(server.URL, WithTimeout(time.Second))
+	require.NoError(t, err)
+	_, err = client.GetBlockMostRecent(context.Background())
+	require.Error(t, err)
+	client.Close()
+
+	// without token - unauthorized
+	client, err = NewClient(server.URL, WithTLS(caPEM))
+	require.NoError(t, err)
+	_, err = client.GetBlockMostRecent(context.Background())
+	require.Error(t, err)
+	client.Close()
+
+	client, err = NewClient(server.URL, WithTLS(caPEM), WithBearerToken("token"), WithTimeout(time.Second))
+	require.NoError(t, err)
+	blockNumber, err := client.GetBlockMostRecent(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(16), blockNumber)
+	client.Close()
+}
+
+func TestOptionTransportTLS(t *testing.T) {
+	var serverName string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+	}))
+	server.TLS = &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverName = hello.ServerName
+			return nil, nil
+		},
+	}
+	server.StartTLS()
+	defer server.Close()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	// server name and min version of custom transport are kept with ca of WithTLS
+	transport := &http.Transport{TLSClientConfig: &tls.Config{ServerName: "example.com", MinVersion: tls.VersionTLS12}}
+	client, err := NewClient(server.URL, WithTransport(transport), WithTLS(caPEM), WithTimeout(time.Second))
+	require.NoError(t, err)
+	blockNumber, err := client.GetBlockMostRecent(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(16), blockNumber)
+	require.Equal(t, "example.com", serverName)
+	client.Close()
+
+	// custom transport is not modified
+	require.Nil(t, transport.TLSClientConfig.RootCAs)
+
+	// pinned roots of custom transport are kept without WithTLS
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(caPEM)
+	transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}
+	client, err = NewClient(server.URL, WithTransport(transport), WithBearerToken("token"), WithTimeout(time.Second))
+	require.NoError(t, err)
+	_, err = client.GetBlockMostRecent(context.Background())
+	require.NoError(t, err)
+	client.Close()
+}
+
+func TestOptionInvalid(t *testing.T) {
+	_, err := NewClient("ws://127.0.0.1:8546", WithTimeout(time.Second))
+	require.Error(t, err)
+
+	_, err = NewClient("https://127.0.0.1:8545", WithTLS([]byte("invalid")))
+	require.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package eth
 
 import (
+	"context"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -47,22 +48,37 @@ func (t *RawTx) Init(client *Client, param *params.ChainConfig, gasTip, gasFee *
 
 }
 
-func (t *RawTx) SendTx() (txid string, err error) {
-	tx, err := t.make()
+func (t *RawTx) SendTx(ctx context.Context) (txid string, err error) {
+	tx, err := t.Build()
 	if err != nil {
 		return "", err
 	}
-	txSigned, err := t.sign(tx, t.fromPrivKey)
+	txSigned, err := t.Sign(tx)
 	if err != nil {
 		return "", err
 	}
-	txid, err = t.send(txSigned)
+	txid, err = t.Send(ctx, txSigned)
 	if err != nil {
 		return "", err
 	}
 	return txid, nil
 }
 
+// Build makes unsigned dynamic fee tx ( eth or erc20 transfer )
+func (t *RawTx) Build() (tx *types.Transaction, err error) {
+	return t.make()
+}
+
+// Sign signs tx with from private key
+func (t *RawTx) Sign(tx *types.Transaction) (txSigned *types.Transaction, err error) {
+	return t.sign(tx, t.fromPrivKey)
+}
+
+// Send broadcasts signed tx
+func (t *RawTx) Send(ctx context.Context, txSigned *types.Transaction) (txid string, err error) {
+	return t.send(ctx, txSigned)
+}
+
 //--------------------------------------------------------------------------------//
 // method
 
@@ -115,8 +131,8 @@ func (t *RawTx) sign(tx *types.Transaction, privKey string) (txSigned *types.Tra
 	return types.SignTx(tx, types.LatestSigner(t.param), ecdsaPrivKey)
 }
 
-func (t *RawTx) send(txSigned *types.Transaction) (txid string, err error) {
-	err = t.client.SendTx(txSigned)
+func (t *RawTx) send(ctx context.Context, txSigned *types.Transaction) (txid string, err error) {
+	err = t.client.SendTx(ctx, txSigned)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,144 @@
+// Package httpopt is http connection options shared by eth and xrp clients
+package httpopt
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"time"
+)
+
+type Option func(config *Config) error
+
+type Config struct {
+	Timeout          time.Duration
+	Transport        *http.Transport // custom transport ( WithTransport )
+	DefaultTransport *http.Transport // used if Transport is nil, http.DefaultTransport if both are nil
+	TLS              *tls.Config     // merged into tls config of transport
+	Proxy            *neturl.URL
+	Headers          map[string]string
+}
+
+func WithTimeout(timeout time.Duration) Option {
+	return func(config *Config) error {
+		config.Timeout = timeout
+		return nil
+	}
+}
+
+func WithTransport(transport *http.Transport) Option {
+	return func(config *Config) error {
+		config.Transport = transport
+		return nil
+	}
+}
+
+func WithTLS(caPEM []byte) Option {
+	return func(config *Config) error {
+		pool := x509.NewCertPool()
+		if pool.AppendCertsFromPEM(caPEM) == false {
+			return fmt.Errorf("invalid ca certificate")
+		}
+		config.tls().RootCAs = pool
+		return nil
+	}
+}
+
+func WithTLSCAFile(caFile string) Option {
+	return func(config *Config) error {
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			return err
+		}
+		return WithTLS(caPEM)(config)
+	}
+}
+
+func WithClientCert(certPEM, keyPEM []byte) Option {
+	return func(config *Config) error {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return err
+		}
+		config.tls().Certificates = append(config.tls().Certificates, cert)
+		return nil
+	}
+}
+
+func WithInsecureSkipVerify() Option {
+	return func(config *Config) error {
+		config.tls().InsecureSkipVerify = true
+		return nil
+	}
+}
+
+func WithBasicAuth(user, pass string) Option {
+	auth := base64.StdEncoding.EncodeToString([]byte(user + ":" + pass))
+	return WithHeader("Authorization", "Basic "+auth)
+}
+
+func WithBearerToken(token string) Option {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+func WithHeader(key, value string) Option {
+	return func(config *Config) error {
+		if config.Headers == nil {
+			config.Headers = make(map[string]string)
+		}
+		config.Headers[key] = value
+		return nil
+	}
+}
+
+func WithProxy(proxyURL string) Option {
+	return func(config *Config) (err error) {
+		config.Proxy, err = neturl.Parse(proxyURL)
+		return err
+	}
+}
+
+func (t *Config) tls() *tls.Config {
+	if t.TLS == nil {
+		t.TLS = &tls.Config{}
+	}
+	return t.TLS
+}
+
+// HTTPClient returns http client of options, transport given by WithTransport is not modified
+func (t *Config) HTTPClient() *http.Client {
+	var transport *http.Transport
+	switch {
+	case t.Transport != nil:
+		transport = t.Transport.Clone()
+	case t.DefaultTransport != nil:
+		transport = t.DefaultTransport.Clone()
+	default:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	if t.TLS != nil {
+		tlsConfig := &tls.Config{}
+		if transport.TLSClientConfig != nil {
+			tlsConfig = transport.TLSClientConfig.Clone() // keep settings of custom transport
+		}
+		if t.TLS.RootCAs != nil {
+			tlsConfig.RootCAs = t.TLS.RootCAs
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, t.TLS.Certificates...)
+		if t.TLS.InsecureSkipVerify == true {
+			tlsConfig.InsecureSkipVerify = true
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	if t.Proxy != nil {
+		transport.Proxy = http.ProxyURL(t.Proxy)
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   t.Timeout,
+	}
+}
@@ -0,0 +1,44 @@
+package httpopt
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionTransportTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{MinVersion: tls.VersionTLS13, ServerName: "example.com"},
+		MaxIdleConns:    3,
+	}
+	config := &Config{}
+	for _, opt := range []Option{WithTransport(transport), WithTLS(caPEM), WithTimeout(time.Second)} {
+		require.NoError(t, opt(config))
+	}
+	httpClient := config.HTTPClient()
+	httpTransport := httpClient.Transport.(*http.Transport)
+
+	// settings of custom transport are kept, ca is added
+	require.Equal(t, uint16(tls.VersionTLS13), httpTransport.TLSClientConfig.MinVersion)
+	require.Equal(t, "example.com", httpTransport.TLSClientConfig.ServerName)
+	require.NotNil(t, httpTransport.TLSClientConfig.RootCAs)
+	require.Equal(t, 3, httpTransport.MaxIdleConns)
+	require.Equal(t, time.Second, httpClient.Timeout)
+
+	// custom transport is not modified
+	require.Nil(t, transport.TLSClientConfig.RootCAs)
+
+	// ca verifies node ( server name of httptest certificate )
+	res, err := httpClient.Get(server.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+}
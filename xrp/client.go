@@ -1,28 +1,54 @@
 package xrp
 
 import (
-	"crypto/tls"
 	"net/http"
 	"time"
+
+	"github.com/rabbitprincess/blockchain_rpc/internal/httpopt"
 )
 
+func NewClient(url string, opts ...Option) (*Client, error) {
+	client := &Client{}
+	err := client.Init(url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
 type Client struct {
-	url string
-	rpc *http.Client
+	url     string
+	rpc     *http.Client
+	headers map[string]string
 }
 
-func (t *Client) Init(url string) (err error) {
-	// init client http
-	t.url = url
-	t.rpc = &http.Client{
-		Transport: &http.Transport{
+// node certificate is verified by default, use WithInsecureSkipVerify for self signed node
+func (t *Client) Init(url string, opts ...Option) (err error) {
+	config := &httpopt.Config{
+		DefaultTransport: &http.Transport{
+			Proxy:              http.ProxyFromEnvironment,
 			MaxIdleConns:       10,
 			IdleConnTimeout:    30 * time.Second,
 			DisableCompression: true,
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
 		},
 	}
+	for _, opt := range opts {
+		if err = opt(config); err != nil {
+			return err
+		}
+	}
+
+	// init client http
+	t.url = url
+	t.rpc = config.HTTPClient()
+	t.headers = config.Headers
 	return nil
 }
+
+// Close releases idle connections of http client ( rippled http api has no session )
+func (t *Client) Close() {
+	if t.rpc == nil {
+		return
+	}
+	t.rpc.CloseIdleConnections()
+}
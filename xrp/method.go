@@ -2,6 +2,7 @@ package xrp
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
@@ -10,7 +11,7 @@ import (
 	"github.com/rabbitprincess/blockchain_rpc/xrp/types"
 )
 
-func (t *Client) sendCmd(req types.CmdReq, res types.CmdRes) (err error) {
+func (t *Client) sendCmd(ctx context.Context, req types.CmdReq, res types.CmdRes) (err error) {
 	// marshal
 	btReq, err := req.Marshal()
 	if err != nil {
@@ -19,10 +20,14 @@ func (t *Client) sendCmd(req types.CmdReq, res types.CmdRes) (err error) {
 	buf := bytes.NewBuffer(btReq)
 
 	// httpReq
-	httpReq, err := http.NewRequest("POST", t.url, buf)
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", t.url, buf)
 	if err != nil {
 		return err
 	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for key, value := range t.headers {
+		httpReq.Header.Set(key, value)
+	}
 	httpRes, err := t.rpc.Do(httpReq)
 	if err != nil {
 		return err
@@ -50,10 +55,10 @@ func (t *Client) sendCmd(req types.CmdReq, res types.CmdRes) (err error) {
 //-------------------------------------------------------------------------------------------//
 // server
 
-func (t *Client) GetServerInfo() (res *types.Res_serverInfo_result, err error) {
+func (t *Client) GetServerInfo(ctx context.Context) (res *types.Res_serverInfo_result, err error) {
 	cmdReq := types.Req_serverInfo{Method: types.Cmd_ServerInfo}
 	cmdRes := types.Res_serverInfo{}
-	err = t.sendCmd(&cmdReq, &cmdRes)
+	err = t.sendCmd(ctx, &cmdReq, &cmdRes)
 	if err != nil {
 		return nil, err
 	}
@@ -67,10 +72,10 @@ func (t *Client) GetServerInfo() (res *types.Res_serverInfo_result, err error) {
 	return res, nil
 }
 
-func (t *Client) GetBaseFee() (baseFee string, err error) {
+func (t *Client) GetBaseFee(ctx context.Context) (baseFee string, err error) {
 	req := types.Req_fee{Method: types.Cmd_Fee}
 	res := types.Res_fee{}
-	err = t.sendCmd(&req, &res)
+	err = t.sendCmd(ctx, &req, &res)
 	if err != nil {
 		return "", err
 	}
@@ -87,13 +92,13 @@ func (t *Client) GetBaseFee() (baseFee string, err error) {
 //-------------------------------------------------------------------------------------------//
 // account
 
-func (t *Client) GetAccountInfo(account string) (res *types.Res_accountInfo_result, err error) {
+func (t *Client) GetAccountInfo(ctx context.Context, account string) (res *types.Res_accountInfo_result, err error) {
 	cmdReq := types.Req_accountInfo{
 		Method: types.Cmd_AccountInfo,
 		Params: []types.Req_accountInfo_params{{Account: account, Strict: true, Queue: true}},
 	}
 	cmdRes := types.Res_accountInfo{}
-	err = t.sendCmd(&cmdReq, &cmdRes)
+	err = t.sendCmd(ctx, &cmdReq, &cmdRes)
 	if err != nil {
 		return nil, err
 	}
@@ -108,7 +113,7 @@ func (t *Client) GetAccountInfo(account string) (res *types.Res_accountInfo_resu
 	return res, nil
 }
 
-func (t *Client) GetAccountTx(account string, ledgerMin, ledgerMax int) (res *types.Res_accountTx_result, err error) {
+func (t *Client) GetAccountTx(ctx context.Context, account string, ledgerMin, ledgerMax int) (res *types.Res_accountTx_result, err error) {
 	cmdReq := types.Req_accountTx{
 		Method: types.Cmd_AccountTx,
 		Params: []types.Req_accountTx_params{
@@ -121,7 +126,7 @@ func (t *Client) GetAccountTx(account string, ledgerMin, ledgerMax int) (res *ty
 		},
 	}
 	cmdRes := types.Res_accountTx{}
-	err = t.sendCmd(&cmdReq, &cmdRes)
+	err = t.sendCmd(ctx, &cmdReq, &cmdRes)
 	if err != nil {
 		return nil, err
 	}
@@ -135,13 +140,13 @@ func (t *Client) GetAccountTx(account string, ledgerMin, ledgerMax int) (res *ty
 	return res, nil
 }
 
-func (t *Client) WalletPropose(privKey string) (res *types.Res_walletPropose_Result, err error) {
+func (t *Client) WalletPropose(ctx context.Context, privKey string) (res *types.Res_walletPropose_Result, err error) {
 	cmdReq := types.Req_walletPropose{Method: types.Cmd_WalletPropose}
 	if privKey != "" {
 		cmdReq.Params = []types.Req_walletPropose_params{{Seed: privKey, KeyType: types.Secp256k1}}
 	}
 	cmdRes := types.Res_walletPropose{}
-	err = t.sendCmd(&cmdReq, &cmdRes)
+	err = t.sendCmd(ctx, &cmdReq, &cmdRes)
 	if err != nil {
 		return nil, err
 	}
@@ -158,10 +163,10 @@ func (t *Client) WalletPropose(privKey string) (res *types.Res_walletPropose_Res
 //-------------------------------------------------------------------------------------------//
 // tx
 
-func (t *Client) MakeTransaction(txHash string) (res *types.TransactionRes, err error) {
+func (t *Client) MakeTransaction(ctx context.Context, txHash string) (res *types.TransactionRes, err error) {
 	cmdReq := types.Req_tx{Method: types.Cmd_Tx}
 	cmdRes := types.Res_tx{}
-	err = t.sendCmd(&cmdReq, &cmdRes)
+	err = t.sendCmd(ctx, &cmdReq, &cmdRes)
 	if err != nil {
 		return nil, err
 	}
@@ -175,7 +180,28 @@ func (t *Client) MakeTransaction(txHash string) (res *types.TransactionRes, err
 	return res, nil
 }
 
-func (t *Client) SignTransaction(tx *types.TransactionRes, privKey string) (txid string, err error) {
+func (t *Client) GetTxInfo(ctx context.Context, txHash string) (res *types.TransactionRes, err error) {
+	cmdReq := types.Req_tx{
+		Method: types.Cmd_Tx,
+		Params: []types.Req_tx_params{{Transaction: txHash}},
+	}
+	cmdRes := types.Res_tx{}
+	err = t.sendCmd(ctx, &cmdReq, &cmdRes)
+	if err != nil {
+		return nil, err
+	}
+
+	// 에러 처리
+	rpcError := cmdRes.Error()
+	if rpcError.ErrCode != types.Ok {
+		return nil, rpcError
+	}
+	res = &cmdRes.Result
+	return res, nil
+}
+
+// tx - *types.TransactionRes or *types.TransactionPayment
+func (t *Client) SignTransaction(ctx context.Context, tx interface{}, privKey string) (txid string, err error) {
 	txJSON, err := json.Marshal(tx)
 	if err != nil {
 		return "", err
@@ -191,7 +217,7 @@ func (t *Client) SignTransaction(tx *types.TransactionRes, privKey string) (txid
 		},
 	}
 	cmdRes := types.Res_sign{}
-	err = t.sendCmd(&cmdReq, &cmdRes)
+	err = t.sendCmd(ctx, &cmdReq, &cmdRes)
 	if err != nil {
 		return "", err
 	}
@@ -205,10 +231,13 @@ func (t *Client) SignTransaction(tx *types.TransactionRes, privKey string) (txid
 	return txid, nil
 }
 
-func (t *Client) SendTransaction(txid string) (res *types.Res_submit_result, err error) {
-	cmdReq := types.Req_submit{Method: types.Cmd_Submit}
+func (t *Client) SendTransaction(ctx context.Context, txid string) (res *types.Res_submit_result, err error) {
+	cmdReq := types.Req_submit{
+		Method: types.Cmd_Submit,
+		Params: []types.Req_submit_params{{TxBlob: txid}},
+	}
 	cmdRes := types.Res_submit{}
-	err = t.sendCmd(&cmdReq, &cmdRes)
+	err = t.sendCmd(ctx, &cmdReq, &cmdRes)
 	if err != nil {
 		return nil, err
 	}
@@ -225,7 +254,7 @@ func (t *Client) SendTransaction(txid string) (res *types.Res_submit_result, err
 //-------------------------------------------------------------------------------------------//
 // ledger
 
-func (t *Client) GetLedgerByNumber(ledgerNumber int, includeTx bool) (res *types.LedgerRes, err error) {
+func (t *Client) GetLedgerByNumber(ctx context.Context, ledgerNumber int, includeTx bool) (res *types.LedgerRes, err error) {
 	cmdReq := types.Req_ledger{
 		Method: types.Cmd_Ledger,
 		Params: []types.Req_ledger_params{
@@ -240,7 +269,7 @@ func (t *Client) GetLedgerByNumber(ledgerNumber int, includeTx bool) (res *types
 		},
 	}
 	cmdRes := types.Res_ledger{}
-	err = t.sendCmd(&cmdReq, &cmdRes)
+	err = t.sendCmd(ctx, &cmdReq, &cmdRes)
 	if err != nil {
 		return nil, err
 	}
@@ -254,7 +283,7 @@ func (t *Client) GetLedgerByNumber(ledgerNumber int, includeTx bool) (res *types
 	return res, nil
 }
 
-func (t *Client) GetLedgerByHash(ledgerHash string, includeTx bool) (res *types.LedgerRes, err error) {
+func (t *Client) GetLedgerByHash(ctx context.Context, ledgerHash string, includeTx bool) (res *types.LedgerRes, err error) {
 	cmdReq := types.Req_ledger{
 		Method: types.Cmd_Ledger,
 		Params: []types.Req_ledger_params{
@@ -269,7 +298,7 @@ func (t *Client) GetLedgerByHash(ledgerHash string, includeTx bool) (res *types.
 		},
 	}
 	cmdRes := types.Res_ledger{}
-	err = t.sendCmd(&cmdReq, &cmdRes)
+	err = t.sendCmd(ctx, &cmdReq, &cmdRes)
 	if err != nil {
 		return nil, err
 	}
@@ -283,7 +312,7 @@ func (t *Client) GetLedgerByHash(ledgerHash string, includeTx bool) (res *types.
 	return res, nil
 }
 
-func (t *Client) GetLedgerLast(includeTx bool) (res *types.LedgerRes, err error) {
+func (t *Client) GetLedgerLast(ctx context.Context, includeTx bool) (res *types.LedgerRes, err error) {
 	cmdReq := types.Req_ledger{
 		Method: types.Cmd_Ledger,
 		Params: []types.Req_ledger_params{
@@ -298,7 +327,7 @@ func (t *Client) GetLedgerLast(includeTx bool) (res *types.LedgerRes, err error)
 		},
 	}
 	cmdRes := types.Res_ledger{}
-	err = t.sendCmd(&cmdReq, &cmdRes)
+	err = t.sendCmd(ctx, &cmdReq, &cmdRes)
 	if err != nil {
 		return nil, err
 	}
@@ -435,8 +435,8 @@ type Req_sign struct {
 }
 
 type Req_sign_params struct {
-	Secret string `json:"secret"`
-	TxJson []byte `json:"tx_json"`
+	Secret string          `json:"secret"`
+	TxJson json.RawMessage `json:"tx_json"`
 }
 
 type Res_sign struct {
@@ -51,14 +51,14 @@ type TransactionRes struct {
 }
 
 type TransactionPayment struct {
-	TransactionType TxType `json:"transaction_type"`
-	Account         string `json:"account"`
-	Fee             string `json:"fee"`
-	Sequence        uint32 `json:"sequence"`
-	Destination     string `json:"destination"`
-	Amount          string `json:"amount"`
-	DestinationTag  uint32 `json:"destination_tag,omitempty"`
-	InvoiceID       []byte `json:"invoice_id,omitempty"`
+	TransactionType TxType `json:"TransactionType"`
+	Account         string `json:"Account"`
+	Fee             string `json:"Fee"`
+	Sequence        uint32 `json:"Sequence"`
+	Destination     string `json:"Destination"`
+	Amount          string `json:"Amount"`
+	DestinationTag  uint32 `json:"DestinationTag,omitempty"`
+	InvoiceID       []byte `json:"InvoiceID,omitempty"`
 }
 
 type TransactionAccountSet struct {